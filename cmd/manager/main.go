@@ -34,6 +34,8 @@ import (
 func main() {
 	var metricsAddr string
 	flag.StringVar(&metricsAddr, "metrics-addr", ":8080", "The address the metric endpoint binds to.")
+	flag.BoolVar(&iter8v1alpha2.SegmentTelemetry, "segment-telemetry", false,
+		"Whether telemetry carries label iter8_segment, which experiments with segments require.")
 	flag.Parse()
 	logf.SetLogger(logf.ZapLogger(false))
 	log := logf.Log.WithName("entrypoint")
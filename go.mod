@@ -6,6 +6,7 @@ require (
 	github.com/fatih/camelcase v1.0.0
 	github.com/go-logr/logr v0.2.1
 	github.com/go-logr/zapr v0.2.0 // indirect
+	github.com/gogo/protobuf v1.3.1
 	github.com/google/go-cmp v0.4.1
 	github.com/onsi/gomega v1.10.1
 	github.com/pkg/errors v0.9.1
@@ -33,6 +33,15 @@ type Request struct {
 	// Criteria to be assessed for each version in this experiment
 	Criteria []Criterion `json:"criteria"`
 
+	// Policy used to combine multiple reward criteria
+	RewardPolicy string `json:"reward_policy,omitempty"`
+
+	// Method used to assess versions
+	AssessmentMethod string `json:"assessment_method,omitempty"`
+
+	// Parameters of frequentist tests; defined only for frequentist assessment method
+	FrequentistParameters *FrequentistParameters `json:"frequentist_parameters,omitempty"`
+
 	// Baseline verison details
 	Baseline Version `json:"baseline"`
 
@@ -112,6 +121,21 @@ type Criterion struct {
 	MetricID  string     `json:"metric_id"`
 	IsReward  *bool      `json:"is_reward,omitempty"`
 	Threshold *Threshold `json:"threshold,omitempty"`
+	Weight    *float32   `json:"weight,omitempty"`
+	Priority  *int32     `json:"priority,omitempty"`
+	Test      string     `json:"test,omitempty"`
+}
+
+// FrequentistParameters details
+type FrequentistParameters struct {
+	// Significance level of the tests
+	Alpha float32 `json:"alpha"`
+
+	// Power of the tests
+	Power float32 `json:"power"`
+
+	// Smallest change relative to baseline value to be detected
+	MinimumDetectableEffect float32 `json:"minimum_detectable_effect"`
 }
 
 // TrafficControl details
@@ -173,12 +197,21 @@ type CriterionAssessment struct {
 	// Assessment of how well this metric is doing with respect to threshold.
 	// Defined only for metrics with a threshold
 	ThresholdAssessment *ThresholdAssessment `json:"threshold_assessment,omitempty"`
+
+	// P-value of the test comparing this version with baseline
+	// Defined only for candidates in frequentist assessment
+	PValue *float32 `json:"p_value,omitempty"`
+
+	// Confidence interval of the difference between this version and baseline
+	// Defined only for candidates in frequentist assessment
+	ConfidenceInterval *Interval `json:"confidence_interval,omitempty"`
 }
 
 // Statistics for a metric
 type Statistics struct {
-	Value           *float32         `json:"value,omitempty"`
-	RatioStatistics *RatioStatistics `json:"ratio_statistics,omitempty"`
+	Value             *float32         `json:"value,omitempty"`
+	StandardDeviation *float32         `json:"standard_deviation,omitempty"`
+	RatioStatistics   *RatioStatistics `json:"ratio_statistics,omitempty"`
 }
 
 // RatioStatistics is statistics for a ratio metric
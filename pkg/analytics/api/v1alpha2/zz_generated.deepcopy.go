@@ -40,6 +40,16 @@ func (in *CriterionAssessment) DeepCopyInto(out *CriterionAssessment) {
 	*out = *in
 	out.Statistics = in.Statistics
 	out.ThresholdAssessment = in.ThresholdAssessment
+	if in.PValue != nil {
+		in, out := &in.PValue, &out.PValue
+		*out = new(float32)
+		**out = **in
+	}
+	if in.ConfidenceInterval != nil {
+		in, out := &in.ConfidenceInterval, &out.ConfidenceInterval
+		*out = new(Interval)
+		**out = **in
+	}
 	return
 }
 
@@ -0,0 +1,72 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package analytics
+
+import (
+	"fmt"
+	"math"
+
+	iter8v1alpha2 "github.com/iter8-tools/iter8/pkg/apis/iter8/v1alpha2"
+)
+
+// asymptotic relative efficiency of Mann-Whitney U test to t-test under normality
+const mannWhitneyEfficiency = 3 / math.Pi
+
+// RequiredSampleSize returns the number of samples each version needs for a two-sided test
+// to detect a relative change of mde from the baseline value with the given significance level and power.
+// stdDev is the standard deviation of the metric in baseline, and is ignored by z-test.
+func RequiredSampleSize(test iter8v1alpha2.StatisticalTestType, alpha, power, mde, baseline, stdDev float64) (int32, error) {
+	if alpha <= 0 || alpha >= 1 || power <= 0 || power >= 1 {
+		return 0, fmt.Errorf("Invalid alpha %f or power %f", alpha, power)
+	}
+	delta := mde * math.Abs(baseline)
+	if delta <= 0 {
+		return 0, fmt.Errorf("Minimum detectable effect is zero for baseline value %f", baseline)
+	}
+
+	zAlpha := normalQuantile(1 - alpha/2)
+	zBeta := normalQuantile(power)
+
+	var n float64
+	switch test {
+	case iter8v1alpha2.TestZ:
+		p1 := baseline
+		p2 := baseline + delta
+		if p1 <= 0 || p2 >= 1 {
+			return 0, fmt.Errorf("Proportions out of range: %f, %f", p1, p2)
+		}
+		p := (p1 + p2) / 2
+		n = math.Pow(zAlpha*math.Sqrt(2*p*(1-p))+zBeta*math.Sqrt(p1*(1-p1)+p2*(1-p2)), 2) / (delta * delta)
+	case iter8v1alpha2.TestWelchT, iter8v1alpha2.TestMannWhitneyU:
+		if stdDev <= 0 {
+			return 0, fmt.Errorf("Standard deviation not available")
+		}
+		n = 2 * math.Pow((zAlpha+zBeta)*stdDev/delta, 2)
+		if test == iter8v1alpha2.TestMannWhitneyU {
+			n /= mannWhitneyEfficiency
+		}
+	default:
+		return 0, fmt.Errorf("Unsupported test: %s", test)
+	}
+
+	if n > math.MaxInt32 {
+		return 0, fmt.Errorf("Required sample size too large: %f", n)
+	}
+	return int32(math.Ceil(n)), nil
+}
+
+// normalQuantile returns the quantile function of the standard normal distribution at p
+func normalQuantile(p float64) float64 {
+	return math.Sqrt2 * math.Erfinv(2*p-1)
+}
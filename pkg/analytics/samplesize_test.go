@@ -0,0 +1,47 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package analytics
+
+import (
+	"testing"
+
+	iter8v1alpha2 "github.com/iter8-tools/iter8/pkg/apis/iter8/v1alpha2"
+)
+
+func TestRequiredSampleSize(t *testing.T) {
+	cases := []struct {
+		test     iter8v1alpha2.StatisticalTestType
+		baseline float64
+		stdDev   float64
+		want     int32
+	}{
+		// 2 * (1.96 + 0.8416)^2 * (10 / 5)^2
+		{iter8v1alpha2.TestWelchT, 100, 10, 63},
+		{iter8v1alpha2.TestMannWhitneyU, 100, 10, 66},
+		{iter8v1alpha2.TestZ, 0.1, 0, 57763},
+	}
+	for _, c := range cases {
+		got, err := RequiredSampleSize(c.test, 0.05, 0.8, 0.05, c.baseline, c.stdDev)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", c.test, err)
+		}
+		if got != c.want {
+			t.Errorf("%s: got %d, want %d", c.test, got, c.want)
+		}
+	}
+
+	if _, err := RequiredSampleSize(iter8v1alpha2.TestWelchT, 0.05, 0.8, 0.05, 100, 0); err == nil {
+		t.Error("expected error without standard deviation")
+	}
+}
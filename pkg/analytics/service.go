@@ -35,11 +35,19 @@ const (
 	destinationServiceNameKey      = "destination_service_name"
 	destinationServiceNamespaceKey = "destination_service_namespace"
 
+	// external hosts are identified by source-side telemetry
+	destinationServiceKey = "destination_service"
+	reporterKey           = "reporter"
+	reporterSource        = "source"
+
+	// requests are identified by segment with the label added to telemetry
+	segmentKey = "iter8_segment"
+
 	baselineID        = "baseline"
 	candidateIDPrefix = "candidate-"
 )
 
-// GetCandidateID returns id of candidate used by analytics, with index of candidate as input
+// GetCandidateID returns id of candidate used by analytics, with stable index of candidate as input
 func GetCandidateID(idx int) string {
 	return fmt.Sprintf("%s%d", candidateIDPrefix, idx)
 }
@@ -49,27 +57,41 @@ func GetBaselineID() string {
 	return baselineID
 }
 
-// MakeRequest generates request payload to analytics
-func MakeRequest(instance *iter8v1alpha2.Experiment) (*v1alpha2.Request, error) {
-	destinationKey := destinationWorkloadKey
-	destinationNamespaceKey := destinationWorkloadNamespaceKey
-
-	if instance.Spec.Service.Kind == "Service" {
-		destinationKey = destinationServiceNameKey
-		destinationNamespaceKey = destinationServiceNamespaceKey
+// versionLabels returns telemetry labels identifying a version with given name and namespace
+func versionLabels(instance *iter8v1alpha2.Experiment, name, namespace string) map[string]string {
+	switch instance.Spec.Service.Kind {
+	case "ServiceEntry":
+		return map[string]string{
+			destinationServiceKey: name,
+			reporterKey:           reporterSource,
+		}
+	case "Service":
+		return map[string]string{
+			destinationServiceNamespaceKey: namespace,
+			destinationServiceNameKey:      name,
+		}
+	default:
+		return map[string]string{
+			destinationWorkloadNamespaceKey: namespace,
+			destinationWorkloadKey:          name,
+		}
 	}
+}
 
+// MakeRequest generates request payload to analytics for metrics collected since startTime
+func MakeRequest(instance *iter8v1alpha2.Experiment, startTime time.Time) (*v1alpha2.Request, error) {
 	serviceNamespace := instance.ServiceNamespace()
 	// identify and define list of candidates
-	candidates := make([]v1alpha2.Version, len(instance.Spec.Service.Candidates))
-	for i, candidate := range instance.Spec.Candidates {
-		candidates[i].ID = GetCandidateID(i)
-		candidates[i].VersionLabels = map[string]string{
-			destinationNamespaceKey: serviceNamespace,
-			destinationKey:          candidate,
-		}
+	assessment := instance.Status.Assessment
+	candidates := make([]v1alpha2.Version, len(assessment.Candidates))
+	for i, candidate := range assessment.Candidates {
+		name, namespace := iter8v1alpha2.SplitVersion(candidate.Name, serviceNamespace)
+		candidates[i].ID = GetCandidateID(assessment.CandidateIndex(candidate.Name))
+		candidates[i].VersionLabels = versionLabels(instance, name, namespace)
 	}
 
+	frequentist := instance.Spec.GetAssessmentMethod() == iter8v1alpha2.AssessmentMethodFrequentist
+
 	// identify and define list of criteria
 	criteria := make([]v1alpha2.Criterion, len(instance.Spec.Criteria))
 	for i, criterion := range instance.Spec.Criteria {
@@ -88,6 +110,14 @@ func MakeRequest(instance *iter8v1alpha2.Experiment) (*v1alpha2.Request, error)
 				Value: criterion.Threshold.Value,
 			}
 		}
+		if isReward {
+			weight := criterion.GetWeight()
+			criteria[i].Weight = &weight
+			criteria[i].Priority = criterion.Priority
+		}
+		if frequentist {
+			criteria[i].Test = string(instance.Spec.GetStatisticalTest(criterion.Metric))
+		}
 	}
 
 	// identify and define metrics
@@ -110,31 +140,56 @@ func MakeRequest(instance *iter8v1alpha2.Experiment) (*v1alpha2.Request, error)
 		}
 	}
 
+	baselineName, baselineNamespace := iter8v1alpha2.SplitVersion(instance.GetBaseline(), serviceNamespace)
 	request := &v1alpha2.Request{
 		Name:        instance.Name,
-		StartTime:   instance.Status.StartTimestamp.Format(time.RFC3339),
+		StartTime:   startTime.Format(time.RFC3339),
 		ServiceName: instance.Spec.Service.Name,
 		Baseline: v1alpha2.Version{
-			ID: GetBaselineID(),
-			VersionLabels: map[string]string{
-				destinationNamespaceKey: serviceNamespace,
-				destinationKey:          instance.Spec.Service.Baseline,
-			},
+			ID:            GetBaselineID(),
+			VersionLabels: versionLabels(instance, baselineName, baselineNamespace),
 		},
 		MetricSpecs: v1alpha2.Metrics{
 			CounterMetrics: counterMetrics,
 			RatioMetrics:   ratioMetrics,
 		},
-		Candidate: candidates,
-		Criteria:  criteria,
+		Candidate:    candidates,
+		Criteria:     criteria,
+		RewardPolicy: string(instance.Spec.GetRewardPolicy()),
 		TrafficControl: &v1alpha2.TrafficControl{
 			MaxIncrement: float32(instance.Spec.GetMaxIncrements()),
-			Strategy:     instance.Spec.GetStrategy(),
+			Strategy:     instance.Spec.GetAnalyticsStrategy(),
 		},
-		IterationNumber: instance.Status.CurrentIteration,
-		LastState:       instance.Status.AnalysisState,
+		IterationNumber:  instance.Status.CurrentIteration,
+		LastState:        instance.Status.AnalysisState,
+		AssessmentMethod: string(instance.Spec.GetAssessmentMethod()),
 	}
 
+	if frequentist {
+		request.FrequentistParameters = &v1alpha2.FrequentistParameters{
+			Alpha:                   instance.Spec.GetAlpha(),
+			Power:                   instance.Spec.GetPower(),
+			MinimumDetectableEffect: instance.Spec.GetMinimumDetectableEffect(),
+		}
+	}
+
+	return request, nil
+}
+
+// MakeSegmentRequest generates request payload to analytics for requests in a segment of the experiment
+// collected since startTime
+func MakeSegmentRequest(instance *iter8v1alpha2.Experiment, segment *iter8v1alpha2.SegmentStatus,
+	startTime time.Time) (*v1alpha2.Request, error) {
+	request, err := MakeRequest(instance, startTime)
+	if err != nil {
+		return nil, err
+	}
+
+	request.Baseline.VersionLabels[segmentKey] = segment.Name
+	for i := range request.Candidate {
+		request.Candidate[i].VersionLabels[segmentKey] = segment.Name
+	}
+	request.LastState = segment.AnalysisState
 	return request, nil
 }
 
@@ -38,6 +38,151 @@ const (
 
 	// StrategyUniform is the uniform strategy
 	StrategyUniform StrategyType = "uniform"
+
+	// StrategyFixedSteps is the strategy shifting traffic by a fixed list of steps
+	StrategyFixedSteps StrategyType = "fixed_steps"
+
+	// StrategyBlueGreen is the strategy switching all traffic to candidate at once after validation
+	StrategyBlueGreen StrategyType = "blue_green"
+)
+
+// RewardPolicyType provides options for combining multiple reward criteria
+type RewardPolicyType string
+
+const (
+	// RewardPolicyWeighted compares versions by the weighted sum of reward improvements
+	RewardPolicyWeighted RewardPolicyType = "weighted"
+
+	// RewardPolicyLexicographic compares versions by rewards in order of priority
+	RewardPolicyLexicographic RewardPolicyType = "lexicographic"
+)
+
+// BlueGreenPhaseType provides options for phases of blue_green strategy
+type BlueGreenPhaseType string
+
+const (
+	// BlueGreenPhaseValidating indicates candidate receives test or mirrored traffic only
+	BlueGreenPhaseValidating BlueGreenPhaseType = "Validating"
+
+	// BlueGreenPhaseRollbackWindow indicates traffic has been switched to candidate, and is switched back instantly on failure
+	BlueGreenPhaseRollbackWindow BlueGreenPhaseType = "RollbackWindow"
+
+	// BlueGreenPhaseSwitched indicates traffic has been switched to candidate and the rollback window is over
+	BlueGreenPhaseSwitched BlueGreenPhaseType = "Switched"
+)
+
+// CandidateActionType provides options for the action applied to the winning candidate after promotion
+type CandidateActionType string
+
+const (
+	// CandidateActionDelete deletes the winning candidate after promotion
+	CandidateActionDelete CandidateActionType = "delete"
+
+	// CandidateActionScaleDown scales the winning candidate to zero replicas after promotion
+	CandidateActionScaleDown CandidateActionType = "scale_down"
+)
+
+// PromotionPhaseType provides options for phases of promotion of winner to baseline
+type PromotionPhaseType string
+
+const (
+	// PromotionPhaseUpdating indicates baseline is being updated with the pod template of the winner
+	PromotionPhaseUpdating PromotionPhaseType = "Updating"
+
+	// PromotionPhaseRollingOut indicates baseline is being rolled out with the pod template of the winner
+	PromotionPhaseRollingOut PromotionPhaseType = "RollingOut"
+
+	// PromotionPhaseCompleted indicates traffic goes to the promoted baseline
+	PromotionPhaseCompleted PromotionPhaseType = "Completed"
+
+	// PromotionPhaseFailed indicates baseline is not updated or rolled out before progress deadline,
+	// and traffic stays with the winner
+	PromotionPhaseFailed PromotionPhaseType = "Failed"
+)
+
+// TemplateChangePolicyType provides options for the policy applied when pod template of targets changes
+type TemplateChangePolicyType string
+
+const (
+	// TemplateChangePolicyRestart restarts the assessment from the first iteration
+	TemplateChangePolicyRestart TemplateChangePolicyType = "restart"
+
+	// TemplateChangePolicyPause pauses the experiment
+	TemplateChangePolicyPause TemplateChangePolicyType = "pause"
+
+	// TemplateChangePolicyIgnore continues the experiment with existing assessment
+	TemplateChangePolicyIgnore TemplateChangePolicyType = "ignore"
+)
+
+// PortProtocolType provides options for protocol of a port exposed by internal services
+type PortProtocolType string
+
+const (
+	// PortProtocolHTTP routes traffic of the port by HTTP routes
+	PortProtocolHTTP PortProtocolType = "HTTP"
+
+	// PortProtocolTCP routes traffic of the port by TCP routes
+	PortProtocolTCP PortProtocolType = "TCP"
+
+	// PortProtocolTLS routes traffic of the port by TLS routes
+	PortProtocolTLS PortProtocolType = "TLS"
+)
+
+// AssessmentMethodType provides options for the method used to assess versions
+type AssessmentMethodType string
+
+const (
+	// AssessmentMethodBayesian decides winner by win probability from analytics
+	AssessmentMethodBayesian AssessmentMethodType = "bayesian"
+
+	// AssessmentMethodFrequentist decides winner by fixed-horizon two-sample tests
+	AssessmentMethodFrequentist AssessmentMethodType = "frequentist"
+)
+
+// StatisticalTestType provides options for two-sample tests in frequentist assessment
+type StatisticalTestType string
+
+const (
+	// TestWelchT is Welch's t-test for means
+	TestWelchT StatisticalTestType = "welch_t_test"
+
+	// TestZ is z-test for proportions
+	TestZ StatisticalTestType = "z_test"
+
+	// TestMannWhitneyU is Mann-Whitney U test
+	TestMannWhitneyU StatisticalTestType = "mann_whitney_u"
+)
+
+// EarlyStopPolicyType provides options for early-stop policies
+type EarlyStopPolicyType string
+
+const (
+	// EarlyStopPolicyWinProbability stops when the same winner holds a win probability threshold for consecutive iterations
+	EarlyStopPolicyWinProbability EarlyStopPolicyType = "win_probability"
+
+	// EarlyStopPolicySPRT stops when sequential probability ratio tests reach a decision
+	EarlyStopPolicySPRT EarlyStopPolicyType = "sprt"
+)
+
+// OutcomeType provides options for the outcome of a completed experiment
+type OutcomeType string
+
+const (
+	// OutcomeWinnerFound indicates a winner was found when the experiment completed
+	OutcomeWinnerFound OutcomeType = "WinnerFound"
+
+	// OutcomeNoWinnerFound indicates no winner was found when the experiment completed
+	OutcomeNoWinnerFound OutcomeType = "NoWinnerFound"
+
+	// OutcomeAborted indicates the experiment was terminated before completion
+	OutcomeAborted OutcomeType = "Aborted"
+
+	// OutcomeFailed indicates the experiment was terminated since candidates did not become available,
+	// or the winner could not be promoted to baseline
+	OutcomeFailed OutcomeType = "Failed"
+
+	// OutcomeRolledBackAfterPromotion indicates traffic was routed back to baseline during bake period after promotion
+	OutcomeRolledBackAfterPromotion OutcomeType = "RolledBackAfterPromotion"
 )
 
 // ActionType provides options for override actions
@@ -74,6 +219,9 @@ const (
 	ExperimentConditionRoutingRulesReady ExperimentConditionType = "RoutingRulesReady"
 )
 
+// SegmentHeader is the header carrying name of the segment of a request
+const SegmentHeader = "x-iter8-segment"
+
 // PhaseType has options for phases that an experiment can be at
 type PhaseType string
 
@@ -86,22 +234,31 @@ const (
 
 	// PhaseCompleted indicates experiment has competed (successfully or not)
 	PhaseCompleted PhaseType = "Completed"
+
+	// PhaseQueued indicates experiment is waiting for other experiments on its targets to complete
+	PhaseQueued PhaseType = "Queued"
 )
 
 // A set of reason setting the experiment condition status
 const (
-	ReasonTargetsFound            = "TargetsFound"
-	ReasonTargetsError            = "TargetsError"
-	ReasonAnalyticsServiceError   = "AnalyticsServiceError"
-	ReasonAnalyticsServiceRunning = "AnalyticsServiceRunning"
-	ReasonIterationUpdate         = "IterationUpdate"
-	ReasonAssessmentUpdate        = "AssessmentUpdate"
-	ReasonTrafficUpdate           = "TrafficUpdate"
-	ReasonExperimentCompleted     = "ExperimentCompleted"
-	ReasonSyncMetricsError        = "SyncMetricsError"
-	ReasonSyncMetricsSucceeded    = "SyncMetricsSucceeded"
-	ReasonRoutingRulesError       = "RoutingRulesError"
-	ReasonRoutingRulesReady       = "RoutingRulesReady"
-	ReasonActionPause             = "ActionPause"
-	ReasonActionResume            = "ActionResume"
+	ReasonTargetsFound             = "TargetsFound"
+	ReasonTargetsError             = "TargetsError"
+	ReasonProgressDeadlineExceeded = "ProgressDeadlineExceeded"
+	ReasonAnalyticsServiceError    = "AnalyticsServiceError"
+	ReasonAnalyticsServiceRunning  = "AnalyticsServiceRunning"
+	ReasonIterationUpdate          = "IterationUpdate"
+	ReasonAssessmentUpdate         = "AssessmentUpdate"
+	ReasonTrafficUpdate            = "TrafficUpdate"
+	ReasonTrafficHeld              = "TrafficHeld"
+	ReasonSampleSizeUnknown        = "SampleSizeUnknown"
+	ReasonCandidateUnhealthy       = "CandidateUnhealthy"
+	ReasonExperimentCompleted      = "ExperimentCompleted"
+	ReasonSyncMetricsError         = "SyncMetricsError"
+	ReasonSyncMetricsSucceeded     = "SyncMetricsSucceeded"
+	ReasonRoutingRulesError        = "RoutingRulesError"
+	ReasonRoutingRulesReady        = "RoutingRulesReady"
+	ReasonActionPause              = "ActionPause"
+	ReasonActionResume             = "ActionResume"
+	ReasonExperimentQueued         = "ExperimentQueued"
+	ReasonExperimentDequeued       = "ExperimentDequeued"
 )
@@ -14,7 +14,13 @@ package v1alpha2
 
 import (
 	"fmt"
+	"sort"
+	"strings"
 	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/validation"
 )
 
 const (
@@ -47,8 +53,82 @@ const (
 
 	// DefaultAnalyticsEndpoint is the default endpoint of analytics
 	DefaultAnalyticsEndpoint string = "http://iter8-analytics:8080"
+
+	// DefaultRewardPolicy is the default policy for combining reward criteria, which is weighted
+	DefaultRewardPolicy RewardPolicyType = RewardPolicyWeighted
+
+	// DefaultRewardWeight is the default weight of a reward metric, which is 1
+	DefaultRewardWeight float32 = 1
+
+	// DefaultRollbackWindow is the default rollback window of blue_green strategy, which is 5m
+	DefaultRollbackWindow time.Duration = time.Minute * 5
+
+	// DefaultRampSteps is the default number of traffic updates in ramp to winner, which is 5
+	DefaultRampSteps int32 = 5
+
+	// DefaultRampDuration is the default duration of ramp to winner, which is 5m
+	DefaultRampDuration time.Duration = time.Minute * 5
+
+	// DefaultAssessmentMethod is the default method to assess versions, which is bayesian
+	DefaultAssessmentMethod AssessmentMethodType = AssessmentMethodBayesian
+
+	// DefaultAlpha is the default significance level of frequentist tests, which is 0.05
+	DefaultAlpha float32 = 0.05
+
+	// DefaultPower is the default power of frequentist tests, which is 0.8
+	DefaultPower float32 = 0.8
+
+	// DefaultMinimumDetectableEffect is the default relative effect to be detected by frequentist tests, which is 0.05
+	DefaultMinimumDetectableEffect float32 = 0.05
+
+	// DefaultEarlyStopPolicy is the default early-stop policy, which is win_probability
+	DefaultEarlyStopPolicy EarlyStopPolicyType = EarlyStopPolicyWinProbability
+
+	// DefaultEarlyStopThreshold is the default win probability in percentage to be held for early stop, which is 95
+	DefaultEarlyStopThreshold float32 = 95
+
+	// DefaultEarlyStopConsecutiveIterations is the default number of consecutive iterations for early stop, which is 3
+	DefaultEarlyStopConsecutiveIterations int32 = 3
+
+	// DefaultSPRTAlpha is the default type I error of sequential probability ratio test, which is 0.05
+	DefaultSPRTAlpha float32 = 0.05
+
+	// DefaultSPRTBeta is the default type II error of sequential probability ratio test, which is 0.2
+	DefaultSPRTBeta float32 = 0.2
+
+	// DefaultCandidateAction is the default action applied to the winning candidate after promotion, which is delete
+	DefaultCandidateAction CandidateActionType = CandidateActionDelete
+
+	// DefaultProgressDeadline is the default duration candidates may stay unavailable, which is 10m
+	DefaultProgressDeadline time.Duration = time.Minute * 10
+
+	// DefaultPortProtocol is the default protocol of a port exposed by internal services, which is HTTP
+	DefaultPortProtocol PortProtocolType = PortProtocolHTTP
+
+	// DefaultTemplateChangePolicy is the default policy applied when pod template of targets changes, which is ignore
+	DefaultTemplateChangePolicy TemplateChangePolicyType = TemplateChangePolicyIgnore
+
+	// DefaultRestartThreshold is the default number of container restarts above which a candidate is unhealthy, which is 3
+	DefaultRestartThreshold int32 = 3
+
+	// DefaultReadinessTimeout is the default duration a running pod may stay not ready, which is 2m
+	DefaultReadinessTimeout time.Duration = time.Minute * 2
+
+	// DefaultMinReplicas is the default minimum number of replicas of each version when scaled by traffic, which is 1
+	DefaultMinReplicas int32 = 1
+
+	// DefaultMaxCandidates is the default maximum number of candidates selected by candidateSelector, which is 1
+	DefaultMaxCandidates int32 = 1
+
+	// DefaultPodTemplateLabelsPath is the default path to pod template labels in targets of generic kind
+	DefaultPodTemplateLabelsPath string = "spec.template.metadata.labels"
 )
 
+// SegmentTelemetry tells whether telemetry carries label iter8_segment taken from header x-iter8-segment
+// It is set by flag segment-telemetry of the controller; segments are rejected unless it is,
+// since versions could not be assessed per segment otherwise
+var SegmentTelemetry = false
+
 // ServiceNamespace gets the namespace for targets
 func (e *Experiment) ServiceNamespace() string {
 	serviceNamespace := e.Spec.Service.Namespace
@@ -58,6 +138,37 @@ func (e *Experiment) ServiceNamespace() string {
 	return serviceNamespace
 }
 
+// VersionSeparator separates namespace and name of a version in another namespace, e.g. preview/reviews-v2
+const VersionSeparator = "/"
+
+// SplitVersion returns name and namespace of a version specified as either name or namespace/name
+// Versions specified by name only are in the default namespace
+func SplitVersion(version, defaultNamespace string) (name, namespace string) {
+	if i := strings.Index(version, VersionSeparator); i >= 0 {
+		return version[i+1:], version[:i]
+	}
+	return version, defaultNamespace
+}
+
+// VersionNamespacedName returns namespaced name of the target object of a version
+func (e *Experiment) VersionNamespacedName(version string) types.NamespacedName {
+	name, namespace := SplitVersion(version, e.ServiceNamespace())
+	return types.NamespacedName{Name: name, Namespace: namespace}
+}
+
+// VersionOf returns the version referring to the target object with given name and namespace
+func (e *Experiment) VersionOf(name, namespace string) string {
+	if namespace == "" || namespace == e.ServiceNamespace() {
+		return name
+	}
+	return namespace + VersionSeparator + name
+}
+
+// CrossNamespace tells whether the target object of a version is in a namespace other than that of targets
+func (e *Experiment) CrossNamespace(version string) bool {
+	return e.VersionNamespacedName(version).Namespace != e.ServiceNamespace()
+}
+
 // Pause indicates whether an Experiment Pause request is issued or not
 func (s *ExperimentSpec) Pause() bool {
 	if s.ManualOverride != nil && s.ManualOverride.Action == ActionPause {
@@ -98,6 +209,22 @@ func (s *ExperimentSpec) GetInterval() (time.Duration, error) {
 	return time.ParseDuration(*s.Duration.Interval)
 }
 
+// GetWarmup returns specified(or default) warm-up duration for the experiment
+func (s *ExperimentSpec) GetWarmup() (time.Duration, error) {
+	if s.Duration == nil || s.Duration.Warmup == nil {
+		return 0, nil
+	}
+	return time.ParseDuration(*s.Duration.Warmup)
+}
+
+// GetProgressDeadline returns specified(or default) duration candidates may stay unavailable
+func (s *ExperimentSpec) GetProgressDeadline() (time.Duration, error) {
+	if s.Duration == nil || s.Duration.ProgressDeadline == nil {
+		return DefaultProgressDeadline, nil
+	}
+	return time.ParseDuration(*s.Duration.ProgressDeadline)
+}
+
 // GetMaxIterations returns specified(or default) max of iterations
 func (s *ExperimentSpec) GetMaxIterations() int32 {
 	if s.Duration == nil || s.Duration.MaxIterations == nil {
@@ -114,6 +241,153 @@ func (c *Criterion) HasRewardMetric() bool {
 	return *c.IsReward
 }
 
+// GetWeight returns specified(or default) weight of the reward metric
+func (c *Criterion) GetWeight() float32 {
+	if c.Weight == nil {
+		return DefaultRewardWeight
+	}
+	return *c.Weight
+}
+
+// GetRewardPolicy returns specified(or default) policy for combining reward criteria
+func (s *ExperimentSpec) GetRewardPolicy() RewardPolicyType {
+	if s.RewardPolicy == nil {
+		return DefaultRewardPolicy
+	}
+	return *s.RewardPolicy
+}
+
+// GetRewardCriteria returns the reward criteria in the order they should be compared
+// Criteria with lower priority value come first; unprioritized criteria keep their order after prioritized ones
+func (s *ExperimentSpec) GetRewardCriteria() []Criterion {
+	out := make([]Criterion, 0)
+	for _, criterion := range s.Criteria {
+		if criterion.HasRewardMetric() {
+			out = append(out, criterion)
+		}
+	}
+
+	if s.GetRewardPolicy() == RewardPolicyLexicographic {
+		sort.SliceStable(out, func(i, j int) bool {
+			if out[j].Priority == nil {
+				return out[i].Priority != nil
+			}
+			return out[i].Priority != nil && *out[i].Priority < *out[j].Priority
+		})
+	}
+	return out
+}
+
+// GetAssessmentMethod returns specified(or default) method used to assess versions
+func (s *ExperimentSpec) GetAssessmentMethod() AssessmentMethodType {
+	if s.Assessment == nil || s.Assessment.Method == nil {
+		return DefaultAssessmentMethod
+	}
+	return *s.Assessment.Method
+}
+
+// GetAlpha returns specified(or default) significance level of frequentist tests
+func (s *ExperimentSpec) GetAlpha() float32 {
+	if s.Assessment == nil || s.Assessment.Frequentist == nil || s.Assessment.Frequentist.Alpha == nil {
+		return DefaultAlpha
+	}
+	return *s.Assessment.Frequentist.Alpha
+}
+
+// GetPower returns specified(or default) power of frequentist tests
+func (s *ExperimentSpec) GetPower() float32 {
+	if s.Assessment == nil || s.Assessment.Frequentist == nil || s.Assessment.Frequentist.Power == nil {
+		return DefaultPower
+	}
+	return *s.Assessment.Frequentist.Power
+}
+
+// GetMinimumDetectableEffect returns specified(or default) relative effect to be detected by frequentist tests
+func (s *ExperimentSpec) GetMinimumDetectableEffect() float32 {
+	if s.Assessment == nil || s.Assessment.Frequentist == nil || s.Assessment.Frequentist.MinimumDetectableEffect == nil {
+		return DefaultMinimumDetectableEffect
+	}
+	return *s.Assessment.Frequentist.MinimumDetectableEffect
+}
+
+// GetStatisticalTest returns specified(or default) test used for the metric in frequentist assessment
+func (s *ExperimentSpec) GetStatisticalTest(metric string) StatisticalTestType {
+	if s.Assessment != nil && s.Assessment.Frequentist != nil && s.Assessment.Frequentist.Test != nil {
+		return *s.Assessment.Frequentist.Test
+	}
+	if s.Metrics != nil {
+		for _, m := range s.Metrics.RatioMetrics {
+			if m.Name == metric && m.IsZeroToOne() {
+				return TestZ
+			}
+		}
+	}
+	return TestWelchT
+}
+
+// GetPrimaryCriterion returns the criterion used to decide the winner in frequentist assessment,
+// which is the first reward criterion, or the first criterion if no reward is specified
+func (s *ExperimentSpec) GetPrimaryCriterion() *Criterion {
+	if rewards := s.GetRewardCriteria(); len(rewards) > 0 {
+		return &rewards[0]
+	}
+	if len(s.Criteria) > 0 {
+		return &s.Criteria[0]
+	}
+	return nil
+}
+
+// GetEarlyStopPolicy returns specified(or default) early-stop policy
+func (e *EarlyStop) GetEarlyStopPolicy() EarlyStopPolicyType {
+	if e.Policy == nil {
+		return DefaultEarlyStopPolicy
+	}
+	return *e.Policy
+}
+
+// GetThreshold returns specified(or default) win probability to be held for early stop
+func (e *EarlyStop) GetThreshold() float32 {
+	if e.Threshold == nil {
+		return DefaultEarlyStopThreshold
+	}
+	return *e.Threshold
+}
+
+// GetConsecutiveIterations returns specified(or default) number of consecutive iterations for early stop
+func (e *EarlyStop) GetConsecutiveIterations() int32 {
+	if e.ConsecutiveIterations == nil {
+		return DefaultEarlyStopConsecutiveIterations
+	}
+	return *e.ConsecutiveIterations
+}
+
+// GetAlpha returns specified(or default) type I error of sequential probability ratio test
+func (e *EarlyStop) GetAlpha() float32 {
+	if e.Alpha == nil {
+		return DefaultSPRTAlpha
+	}
+	return *e.Alpha
+}
+
+// GetBeta returns specified(or default) type II error of sequential probability ratio test
+func (e *EarlyStop) GetBeta() float32 {
+	if e.Beta == nil {
+		return DefaultSPRTBeta
+	}
+	return *e.Beta
+}
+
+// GetEarlyStopMetric returns specified(or default) metric used by sequential probability ratio test
+func (s *ExperimentSpec) GetEarlyStopMetric() string {
+	if s.EarlyStop != nil && s.EarlyStop.Metric != nil {
+		return *s.EarlyStop.Metric
+	}
+	if criterion := s.GetPrimaryCriterion(); criterion != nil {
+		return criterion.Metric
+	}
+	return ""
+}
+
 // CutOffOnViolation indicates whether traffic should be cutoff to a target if threshold is violated
 func (t *Threshold) CutOffOnViolation() bool {
 	if t.CutoffTrafficOnViolation == nil {
@@ -130,6 +404,197 @@ func (s *ExperimentSpec) GetStrategy() string {
 	return string(*s.TrafficControl.Strategy)
 }
 
+// GetAnalyticsStrategy returns the strategy sent to analytics
+// fixed_steps and blue_green strategies do not use recommendation from analytics, so progressive is sent instead
+func (s *ExperimentSpec) GetAnalyticsStrategy() string {
+	switch s.GetStrategy() {
+	case string(StrategyFixedSteps), string(StrategyBlueGreen):
+		return string(StrategyProgressive)
+	}
+	return s.GetStrategy()
+}
+
+// GetRollbackWindow returns specified(or default) rollback window of blue_green strategy
+func (s *ExperimentSpec) GetRollbackWindow() (time.Duration, error) {
+	if s.TrafficControl == nil || s.TrafficControl.BlueGreen == nil || s.TrafficControl.BlueGreen.RollbackWindow == nil {
+		return DefaultRollbackWindow, nil
+	}
+	return time.ParseDuration(*s.TrafficControl.BlueGreen.RollbackWindow)
+}
+
+// GetMirror returns specified(or default) mirror flag of blue_green strategy
+func (s *ExperimentSpec) GetMirror() bool {
+	if s.TrafficControl == nil || s.TrafficControl.BlueGreen == nil || s.TrafficControl.BlueGreen.Mirror == nil {
+		return false
+	}
+	return *s.TrafficControl.BlueGreen.Mirror
+}
+
+// GetDisjoint returns whether the experiment runs in a traffic segment disjoint from other experiments
+func (s *ExperimentSpec) GetDisjoint() bool {
+	if s.TrafficControl == nil || s.TrafficControl.Disjoint == nil {
+		return false
+	}
+	return *s.TrafficControl.Disjoint
+}
+
+// GetSegments returns segments of requests in the experiment
+func (s *ExperimentSpec) GetSegments() []Segment {
+	if s.TrafficControl == nil {
+		return nil
+	}
+	return s.TrafficControl.Segments
+}
+
+// GetSticky returns the sticky assignment specification; nil if users are not assigned stickily
+func (s *ExperimentSpec) GetSticky() *Sticky {
+	if s.TrafficControl == nil {
+		return nil
+	}
+	return s.TrafficControl.Sticky
+}
+
+// GetHold returns specified(or default) hold duration of the step
+func (t *TrafficStep) GetHold(s *ExperimentSpec) (time.Duration, error) {
+	if t.Hold == nil {
+		return s.GetInterval()
+	}
+	return time.ParseDuration(*t.Hold)
+}
+
+// GetRampSteps returns specified(or default) number of traffic updates in ramp to winner
+func (s *ExperimentSpec) GetRampSteps() int32 {
+	if s.TrafficControl == nil || s.TrafficControl.Ramp == nil || s.TrafficControl.Ramp.Steps == nil {
+		return DefaultRampSteps
+	}
+	return *s.TrafficControl.Ramp.Steps
+}
+
+// GetRampInterval returns the duration between traffic updates in ramp to winner
+func (s *ExperimentSpec) GetRampInterval() (time.Duration, error) {
+	duration := DefaultRampDuration
+	if s.TrafficControl != nil && s.TrafficControl.Ramp != nil && s.TrafficControl.Ramp.Duration != nil {
+		d, err := time.ParseDuration(*s.TrafficControl.Ramp.Duration)
+		if err != nil {
+			return 0, err
+		}
+		duration = d
+	}
+	return duration / time.Duration(s.GetRampSteps()), nil
+}
+
+// GetBakeDuration returns specified bake duration, 0 if bake is not specified
+func (s *ExperimentSpec) GetBakeDuration() (time.Duration, error) {
+	if s.Bake == nil {
+		return 0, nil
+	}
+	return time.ParseDuration(s.Bake.Duration)
+}
+
+// GetCandidateAction returns specified(or default) action applied to the winning candidate after promotion
+func (s *ExperimentSpec) GetCandidateAction() CandidateActionType {
+	if s.Promotion == nil || s.Promotion.CandidateAction == nil {
+		return DefaultCandidateAction
+	}
+	return *s.Promotion.CandidateAction
+}
+
+// GetOnTemplateChange returns specified(or default) policy applied when pod template of targets changes
+func (s *ExperimentSpec) GetOnTemplateChange() TemplateChangePolicyType {
+	if s.OnTemplateChange == nil {
+		return DefaultTemplateChangePolicy
+	}
+	return *s.OnTemplateChange
+}
+
+// GetRestartThreshold returns specified(or default) number of container restarts above which a candidate is unhealthy
+func (s *ExperimentSpec) GetRestartThreshold() int32 {
+	if s.PodHealth == nil || s.PodHealth.RestartThreshold == nil {
+		return DefaultRestartThreshold
+	}
+	return *s.PodHealth.RestartThreshold
+}
+
+// GetReadinessTimeout returns specified(or default) duration a running pod may stay not ready
+func (s *ExperimentSpec) GetReadinessTimeout() (time.Duration, error) {
+	if s.PodHealth == nil || s.PodHealth.ReadinessTimeout == nil {
+		return DefaultReadinessTimeout, nil
+	}
+	return time.ParseDuration(*s.PodHealth.ReadinessTimeout)
+}
+
+// GetMinReplicas returns specified(or default) minimum number of replicas of each version when scaled by traffic
+func (s *ExperimentSpec) GetMinReplicas() int32 {
+	if s.Scaling == nil || s.Scaling.MinReplicas == nil {
+		return DefaultMinReplicas
+	}
+	return *s.Scaling.MinReplicas
+}
+
+// GetCandidates returns names of all candidates, including candidates generated from templates
+func (s *Service) GetCandidates() []string {
+	if len(s.CandidateTemplates) == 0 {
+		return s.Candidates
+	}
+	out := append([]string{}, s.Candidates...)
+	for _, t := range s.CandidateTemplates {
+		out = append(out, t.Name)
+	}
+	return out
+}
+
+// GetPorts returns ports exposed by internal services, from either Ports or Port
+func (s *Service) GetPorts() []ServicePort {
+	if len(s.Ports) > 0 {
+		return s.Ports
+	}
+	if s.Port != nil {
+		return []ServicePort{{Number: *s.Port}}
+	}
+	return []ServicePort{}
+}
+
+// GetProtocol returns specified(or default) protocol of the port
+func (p *ServicePort) GetProtocol() PortProtocolType {
+	if p.Protocol == nil {
+		return DefaultPortProtocol
+	}
+	return *p.Protocol
+}
+
+// GetPodTemplateLabelsPath returns specified(or default) path to pod template labels in targets of generic kind
+func (s *Service) GetPodTemplateLabelsPath() string {
+	if s.PodTemplateLabelsPath == nil {
+		return DefaultPodTemplateLabelsPath
+	}
+	return *s.PodTemplateLabelsPath
+}
+
+// GetMaxCandidates returns specified(or default) maximum number of candidates selected by candidateSelector
+func (s *Service) GetMaxCandidates() int32 {
+	if s.MaxCandidates == nil {
+		return DefaultMaxCandidates
+	}
+	return *s.MaxCandidates
+}
+
+// GetBaseline returns name of baseline, either specified or selected by baselineSelector
+func (e *Experiment) GetBaseline() string {
+	if e.Spec.Baseline == "" && e.Status.SelectedBaseline != nil {
+		return *e.Status.SelectedBaseline
+	}
+	return e.Spec.Baseline
+}
+
+// GetCandidates returns names of all candidates, including candidates generated from templates
+// and candidates selected by candidateSelector
+func (e *Experiment) GetCandidates() []string {
+	if len(e.Status.SelectedCandidates) == 0 {
+		return e.Spec.GetCandidates()
+	}
+	return append(append([]string{}, e.Spec.GetCandidates()...), e.Status.SelectedCandidates...)
+}
+
 // GetOnTermination returns specified(or default) onTermination strategy for traffic controller
 func (s *ExperimentSpec) GetOnTermination() OnTerminationType {
 	if s.TrafficControl == nil || s.TrafficControl.OnTermination == nil {
@@ -154,6 +619,22 @@ func (s *ExperimentSpec) GetMaxIncrements() int32 {
 	return *s.TrafficControl.MaxIncrement
 }
 
+// GetMinRequestCount returns specified(or default) number of requests each version must serve before traffic change
+func (s *ExperimentSpec) GetMinRequestCount() int32 {
+	if s.TrafficControl == nil || s.TrafficControl.MinRequestCount == nil {
+		return 0
+	}
+	return *s.TrafficControl.MinRequestCount
+}
+
+// GetMinObservationTime returns specified(or default) duration each version must receive traffic before traffic change
+func (s *ExperimentSpec) GetMinObservationTime() (time.Duration, error) {
+	if s.TrafficControl == nil || s.TrafficControl.MinObservationTime == nil {
+		return 0, nil
+	}
+	return time.ParseDuration(*s.TrafficControl.MinObservationTime)
+}
+
 // GetAnalyticsEndpoint returns specified(or default) analytics endpoint
 func (s *ExperimentSpec) GetAnalyticsEndpoint() string {
 	if s.AnalyticsEndpoint == nil {
@@ -199,12 +680,314 @@ func (s *ExperimentSpec) Validate() error {
 		if !(s.APIVersion == "" || s.APIVersion == "apps/v1" || s.APIVersion == "v1") {
 			return fmt.Errorf("Invalid kind/apiVerison pair: %s, %s", s.Kind, s.APIVersion)
 		}
+	case "StatefulSet":
+		if !(s.APIVersion == "" || s.APIVersion == "apps/v1") {
+			return fmt.Errorf("Invalid kind/apiVerison pair: %s, %s", s.Kind, s.APIVersion)
+		}
 	case "Service":
 		if !(s.APIVersion == "" || s.APIVersion == "v1") {
 			return fmt.Errorf("Invalid kind/apiVerison pair: %s, %s", s.Kind, s.APIVersion)
 		}
+	case "ServiceEntry":
+		if !(s.APIVersion == "" || s.APIVersion == "networking.istio.io/v1alpha3") {
+			return fmt.Errorf("Invalid kind/apiVerison pair: %s, %s", s.Kind, s.APIVersion)
+		}
+		if s.Name == "" {
+			return fmt.Errorf("Name of logical host should be specified for ServiceEntry targets")
+		}
 	default:
-		return fmt.Errorf("Invalid kind/apiVerison pair: %s, %s", s.Kind, s.APIVersion)
+		// generic workload
+		if s.APIVersion == "" {
+			return fmt.Errorf("Invalid kind/apiVerison pair: %s, %s", s.Kind, s.APIVersion)
+		}
+		if s.GetPodTemplateLabelsPath() == "" {
+			return fmt.Errorf("Invalid podTemplateLabelsPath")
+		}
+	}
+
+	// check selectors specification
+	if s.Baseline == "" && s.BaselineSelector == nil {
+		return fmt.Errorf("Either Baseline or BaselineSelector should be specified")
+	}
+
+	// check ports specification
+	if s.Port != nil && len(s.Ports) > 0 {
+		return fmt.Errorf("Port and Ports should not be specified together")
+	}
+	numbers := make(map[int32]bool)
+	for _, port := range s.Ports {
+		if port.Number <= 0 || numbers[port.Number] {
+			return fmt.Errorf("Invalid port: %d", port.Number)
+		}
+		numbers[port.Number] = true
+		switch port.GetProtocol() {
+		case PortProtocolHTTP, PortProtocolTCP:
+			if len(port.SNIHosts) > 0 {
+				return fmt.Errorf("SNIHosts are only applicable to TLS ports")
+			}
+		case PortProtocolTLS:
+		default:
+			return fmt.Errorf("Invalid protocol of port %d: %s", port.Number, port.GetProtocol())
+		}
+	}
+
+	// check versions in other namespaces
+	versions := s.Candidates
+	if s.Baseline != "" {
+		versions = append([]string{s.Baseline}, versions...)
+	}
+	for _, version := range versions {
+		name, namespace := SplitVersion(version, "")
+		if name == "" || strings.Contains(name, VersionSeparator) ||
+			strings.Contains(version, VersionSeparator) && namespace == "" {
+			return fmt.Errorf("Invalid version: %s", version)
+		}
+	}
+	for _, selector := range []*metav1.LabelSelector{s.BaselineSelector, s.CandidateSelector} {
+		if selector == nil {
+			continue
+		}
+		if s.Kind != "" && s.Kind != "Deployment" {
+			return fmt.Errorf("Selectors are only supported for Deployment targets")
+		}
+		if _, err := metav1.LabelSelectorAsSelector(selector); err != nil {
+			return fmt.Errorf("Invalid selector: %v", err)
+		}
+	}
+	if s.GetMaxCandidates() < 1 {
+		return fmt.Errorf("Invalid maxCandidates: %d", s.GetMaxCandidates())
+	}
+
+	// check candidate templates specification
+	if len(s.CandidateTemplates) > 0 {
+		if s.Kind != "" && s.Kind != "Deployment" {
+			return fmt.Errorf("CandidateTemplates are only supported for Deployment targets")
+		}
+		if strings.Contains(s.Baseline, VersionSeparator) {
+			return fmt.Errorf("CandidateTemplates are only supported for baseline in namespace of targets")
+		}
+		names := map[string]bool{s.Baseline: true}
+		for _, candidate := range s.Candidates {
+			names[candidate] = true
+		}
+		for _, t := range s.CandidateTemplates {
+			if t.Name == "" || names[t.Name] {
+				return fmt.Errorf("Invalid name of candidate template: %s", t.Name)
+			}
+			names[t.Name] = true
+		}
+	}
+
+	// check disjoint traffic segment specification
+	if s.GetDisjoint() {
+		if s.TrafficControl.Match == nil || len(s.TrafficControl.Match.HTTP) == 0 {
+			return fmt.Errorf("HTTP match should be specified for disjoint experiments")
+		}
+		for _, port := range s.GetPorts() {
+			if port.GetProtocol() != PortProtocolHTTP {
+				return fmt.Errorf("Disjoint experiments only support HTTP ports")
+			}
+		}
+	}
+
+	// check segments specification
+	if segments := s.GetSegments(); len(segments) > 0 {
+		if !SegmentTelemetry {
+			return fmt.Errorf("Segments require telemetry carrying label iter8_segment, enabled by flag segment-telemetry of the controller")
+		}
+		if s.TrafficControl.Match != nil || s.GetDisjoint() {
+			return fmt.Errorf("Segments should not be specified together with match")
+		}
+		switch StrategyType(s.GetStrategy()) {
+		case StrategyProgressive, StrategyTop2, StrategyUniform:
+		default:
+			return fmt.Errorf("Segments are not supported by %s strategy", s.GetStrategy())
+		}
+		if s.TrafficControl.Ramp != nil || s.Promotion != nil || s.Scaling != nil {
+			return fmt.Errorf("Segments are not supported with ramp, promotion or scaling")
+		}
+		for _, port := range s.GetPorts() {
+			if port.GetProtocol() != PortProtocolHTTP {
+				return fmt.Errorf("Segments only support HTTP ports")
+			}
+		}
+		names := make(map[string]bool)
+		for _, segment := range segments {
+			if errs := validation.IsDNS1123Label(segment.Name); len(errs) > 0 || names[segment.Name] {
+				return fmt.Errorf("Invalid name of segment: %s", segment.Name)
+			}
+			names[segment.Name] = true
+			if len(segment.Match.HTTP) == 0 {
+				return fmt.Errorf("HTTP match should be specified for segment %s", segment.Name)
+			}
+		}
+	}
+
+	// check sticky specification
+	if sticky := s.GetSticky(); sticky != nil {
+		if (sticky.Header == nil) == (sticky.Cookie == nil) {
+			return fmt.Errorf("Either header or cookie should be specified for sticky assignment")
+		}
+		if len(s.GetSegments()) > 0 {
+			return fmt.Errorf("Sticky assignment should not be specified together with segments")
+		}
+		// filters bucketing users match virtual hosts of the service by port
+		http := false
+		for _, port := range s.GetPorts() {
+			if port.GetProtocol() == PortProtocolHTTP {
+				http = true
+			}
+		}
+		if !http {
+			return fmt.Errorf("Sticky assignment requires an HTTP port of the service to be specified")
+		}
+	}
+
+	// check duration specification
+	if warmup, err := s.GetWarmup(); err != nil || warmup < 0 {
+		return fmt.Errorf("Invalid warmup: %s", *s.Duration.Warmup)
+	}
+
+	if d, err := s.GetProgressDeadline(); err != nil || d <= 0 {
+		return fmt.Errorf("Invalid progressDeadline: %s", *s.Duration.ProgressDeadline)
+	}
+
+	// check traffic control specification
+	if d, err := s.GetMinObservationTime(); err != nil || d < 0 {
+		return fmt.Errorf("Invalid minObservationTime: %s", *s.TrafficControl.MinObservationTime)
+	}
+	if s.GetMinRequestCount() < 0 {
+		return fmt.Errorf("Invalid minRequestCount: %d", s.GetMinRequestCount())
+	}
+
+	// check fixed steps specification
+	if s.GetStrategy() == string(StrategyFixedSteps) {
+		if len(s.TrafficControl.Steps) == 0 {
+			return fmt.Errorf("Steps should be specified for fixed_steps strategy")
+		}
+		last := int32(0)
+		for i, step := range s.TrafficControl.Steps {
+			if step.Weight < last || step.Weight > 100 {
+				return fmt.Errorf("Invalid weight of step %d: %d", i, step.Weight)
+			}
+			last = step.Weight
+			if _, err := step.GetHold(s); err != nil {
+				return fmt.Errorf("Invalid hold of step %d: %v", i, err)
+			}
+		}
+	}
+
+	// check blue/green specification
+	if s.GetStrategy() == string(StrategyBlueGreen) {
+		numCandidates := len(s.GetCandidates())
+		if s.CandidateSelector != nil {
+			numCandidates += int(s.GetMaxCandidates())
+		}
+		if numCandidates != 1 {
+			return fmt.Errorf("Exactly one candidate should be specified for blue_green strategy")
+		}
+		if len(s.Criteria) == 0 {
+			return fmt.Errorf("Criteria should be specified for blue_green strategy")
+		}
+		if s.GetMinRequestCount() < 1 {
+			return fmt.Errorf("minRequestCount should be specified for blue_green strategy")
+		}
+		if _, err := s.GetRollbackWindow(); err != nil {
+			return fmt.Errorf("Invalid rollbackWindow: %v", err)
+		}
+	}
+
+	// check ramp specification
+	if s.TrafficControl != nil && s.TrafficControl.Ramp != nil {
+		if steps := s.GetRampSteps(); steps < 1 {
+			return fmt.Errorf("Invalid ramp steps: %d", steps)
+		}
+		if _, err := s.GetRampInterval(); err != nil {
+			return fmt.Errorf("Invalid ramp duration: %v", err)
+		}
+	}
+
+	// check bake specification
+	if d, err := s.GetBakeDuration(); err != nil || d < 0 {
+		return fmt.Errorf("Invalid bake duration: %s", s.Bake.Duration)
+	}
+
+	// check pod health specification
+	if s.GetRestartThreshold() < 0 {
+		return fmt.Errorf("Invalid restartThreshold: %d", s.GetRestartThreshold())
+	}
+	if d, err := s.GetReadinessTimeout(); err != nil || d <= 0 {
+		return fmt.Errorf("Invalid readinessTimeout: %s", *s.PodHealth.ReadinessTimeout)
+	}
+
+	// check scaling specification
+	if s.Scaling != nil {
+		if s.Kind != "" && s.Kind != "Deployment" {
+			return fmt.Errorf("Scaling is only supported for Deployment targets")
+		}
+		if s.GetMinReplicas() < 1 {
+			return fmt.Errorf("Invalid minReplicas: %d", s.GetMinReplicas())
+		}
+	}
+
+	// check template change policy
+	switch s.GetOnTemplateChange() {
+	case TemplateChangePolicyRestart, TemplateChangePolicyPause, TemplateChangePolicyIgnore:
+	default:
+		return fmt.Errorf("Invalid onTemplateChange: %s", s.GetOnTemplateChange())
+	}
+
+	// check promotion specification
+	if s.Promotion != nil {
+		if s.Kind != "" && s.Kind != "Deployment" {
+			return fmt.Errorf("Promotion is only supported for Deployment targets")
+		}
+		switch s.GetCandidateAction() {
+		case CandidateActionDelete, CandidateActionScaleDown:
+		default:
+			return fmt.Errorf("Invalid candidateAction: %s", s.GetCandidateAction())
+		}
+	}
+
+	// check frequentist specification
+	if s.GetAssessmentMethod() == AssessmentMethodFrequentist {
+		if alpha := s.GetAlpha(); alpha <= 0 || alpha >= 1 {
+			return fmt.Errorf("Invalid alpha: %f", alpha)
+		}
+		if power := s.GetPower(); power <= 0 || power >= 1 {
+			return fmt.Errorf("Invalid power: %f", power)
+		}
+		if mde := s.GetMinimumDetectableEffect(); mde <= 0 {
+			return fmt.Errorf("Invalid minimum detectable effect: %f", mde)
+		}
+	}
+
+	// check early-stop specification
+	if s.EarlyStop != nil {
+		switch s.EarlyStop.GetEarlyStopPolicy() {
+		case EarlyStopPolicyWinProbability:
+			if threshold := s.EarlyStop.GetThreshold(); threshold <= 0 || threshold > 100 {
+				return fmt.Errorf("Invalid early-stop threshold: %f", threshold)
+			}
+			if n := s.EarlyStop.GetConsecutiveIterations(); n < 1 {
+				return fmt.Errorf("Invalid early-stop consecutive iterations: %d", n)
+			}
+		case EarlyStopPolicySPRT:
+			alpha, beta := s.EarlyStop.GetAlpha(), s.EarlyStop.GetBeta()
+			if alpha <= 0 || beta <= 0 || alpha+beta >= 1 {
+				return fmt.Errorf("Invalid early-stop alpha %f or beta %f", alpha, beta)
+			}
+		}
+	}
+
+	// check reward criteria specification
+	for _, criterion := range s.Criteria {
+		if criterion.Weight != nil && !criterion.HasRewardMetric() {
+			return fmt.Errorf("Weight should only be specified for reward criteria: %s", criterion.Metric)
+		}
+		if criterion.Weight != nil && *criterion.Weight < 0 {
+			return fmt.Errorf("Invalid weight for reward %s: %f", criterion.Metric, *criterion.Weight)
+		}
 	}
 
 	return nil
@@ -0,0 +1,76 @@
+/*
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha2
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+func validSpec() *ExperimentSpec {
+	return &ExperimentSpec{
+		Service: Service{
+			ObjectReference: &corev1.ObjectReference{Name: "reviews"},
+			Baseline:        "reviews-v1",
+			Candidates:      []string{"reviews-v2"},
+		},
+		TrafficControl: &TrafficControl{},
+	}
+}
+
+func uriPrefix(prefix string) *HTTPMatchRequest {
+	return &HTTPMatchRequest{URI: &StringMatch{Prefix: &prefix}}
+}
+
+func TestValidateSegments(t *testing.T) {
+	defer func(telemetry bool) { SegmentTelemetry = telemetry }(SegmentTelemetry)
+
+	tests := []struct {
+		name      string
+		telemetry bool
+		match     *Match
+		segments  []Segment
+		wantErr   bool
+	}{
+		{name: "no segments", telemetry: false},
+		{name: "segments without telemetry", telemetry: false, wantErr: true,
+			segments: []Segment{{Name: "beta", Match: Match{HTTP: []*HTTPMatchRequest{uriPrefix("/beta")}}}}},
+		{name: "segments with telemetry", telemetry: true,
+			segments: []Segment{{Name: "beta", Match: Match{HTTP: []*HTTPMatchRequest{uriPrefix("/beta")}}}}},
+		{name: "segments with match", telemetry: true, wantErr: true,
+			match:    &Match{HTTP: []*HTTPMatchRequest{uriPrefix("/")}},
+			segments: []Segment{{Name: "beta", Match: Match{HTTP: []*HTTPMatchRequest{uriPrefix("/beta")}}}}},
+		{name: "segment without match", telemetry: true, wantErr: true,
+			segments: []Segment{{Name: "beta"}}},
+		{name: "duplicate segments", telemetry: true, wantErr: true,
+			segments: []Segment{
+				{Name: "beta", Match: Match{HTTP: []*HTTPMatchRequest{uriPrefix("/beta")}}},
+				{Name: "beta", Match: Match{HTTP: []*HTTPMatchRequest{uriPrefix("/gamma")}}},
+			}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			SegmentTelemetry = tt.telemetry
+			s := validSpec()
+			s.TrafficControl.Match = tt.match
+			s.TrafficControl.Segments = tt.segments
+			if err := s.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
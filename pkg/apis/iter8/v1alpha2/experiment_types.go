@@ -61,11 +61,22 @@ type ExperimentSpec struct {
 	Service `json:"service"`
 
 	// Criteria contains a list of Criterion for assessing the target service
-	// Noted that at most one reward metric is allowed
-	// If more than one reward criterion is included, the first would be used while others would be omitted
+	// More than one reward criterion can be included; they are combined as specified in RewardPolicy
 	// +optional
 	Criteria []Criterion `json:"criteria,omitempty"`
 
+	// RewardPolicy determines how multiple reward criteria are combined in winner selection
+	// weighted: versions are compared by the weighted sum of relative improvements over baseline
+	// lexicographic: versions are compared by reward criteria in order of priority
+	// default is weighted
+	// +kubebuilder:validation:Enum={weighted,lexicographic}
+	// +optional
+	RewardPolicy *RewardPolicyType `json:"rewardPolicy,omitempty"`
+
+	// Assessment configures the method used to assess versions in the experiment
+	// +optional
+	Assessment *AssessmentConfig `json:"assessment,omitempty"`
+
 	// TrafficControl provides instructions on traffic management for an experiment
 	// +optional
 	TrafficControl *TrafficControl `json:"trafficControl,omitempty"`
@@ -79,6 +90,36 @@ type ExperimentSpec struct {
 	// +optional
 	Duration *Duration `json:"duration,omitempty"`
 
+	// EarlyStop specifies the policy used to complete the experiment before maxIterations when the result is clear
+	// The experiment runs to maxIterations if it is not specified
+	// +optional
+	EarlyStop *EarlyStop `json:"earlyStop,omitempty"`
+
+	// Bake keeps evaluating criteria for a period after traffic goes to the winner
+	// If criteria are breached, traffic is routed back to baseline
+	// +optional
+	Bake *Bake `json:"bake,omitempty"`
+
+	// Promotion updates baseline in place with the winner after traffic goes to the winner
+	// Only applicable to Deployment targets
+	// +optional
+	Promotion *Promotion `json:"promotion,omitempty"`
+
+	// OnTemplateChange is the policy applied when pod template of baseline or candidates changes during the experiment
+	// options: restart, pause, ignore; default is ignore
+	// +optional
+	OnTemplateChange *TemplateChangePolicyType `json:"onTemplateChange,omitempty"`
+
+	// PodHealth specifies health checks on pods of candidates
+	// A candidate failing the checks is rolled back without waiting for the next iteration
+	// +optional
+	PodHealth *PodHealth `json:"podHealth,omitempty"`
+
+	// Scaling scales replicas of baseline and candidates in proportion to their traffic
+	// Only applicable to Deployment targets
+	// +optional
+	Scaling *Scaling `json:"scaling,omitempty"`
+
 	// Cleanup indicates whether routing rules and deployment receiving no traffic should be deleted at the end of experiment
 	// +optional
 	Cleanup *bool `json:"cleanup,omitempty"`
@@ -99,16 +140,103 @@ type ExperimentSpec struct {
 // Service is a reference to the service that this experiment is targeting at
 type Service struct {
 	// defines the object reference to the service
+	// Kind of targets is one of Deployment, StatefulSet, Service and ServiceEntry;
+	// any other kind is handled as a generic workload, and apiVersion should be specified;
+	// the controller should be granted access to generic kinds, e.g. by genericTargets of its helm chart
+	// For ServiceEntry targets, Name is the logical external host called by clients,
+	// and baseline and candidates are external hosts defined by ServiceEntries
 	*corev1.ObjectReference `json:",inline"`
 
+	// PodTemplateLabelsPath is the dot-separated path to pod template labels in targets of generic kind
+	// Labels are used to differentiate pods of versions in routing rules
+	// default is spec.template.metadata.labels
+	// +optional
+	PodTemplateLabelsPath *string `json:"podTemplateLabelsPath,omitempty"`
+
 	// Name of the baseline deployment
-	Baseline string `json:"baseline"`
+	// A version in a namespace other than that of targets is specified as namespace/name
+	// Either Baseline or BaselineSelector should be specified
+	// +optional
+	Baseline string `json:"baseline,omitempty"`
+
+	// BaselineSelector selects the baseline deployment by labels when Baseline is not specified
+	// The oldest matching deployment is selected
+	// +optional
+	BaselineSelector *metav1.LabelSelector `json:"baselineSelector,omitempty"`
 
 	// List of names of candidate deployments
-	Candidates []string `json:"candidates"`
+	// A version in a namespace other than that of targets is specified as namespace/name
+	// +optional
+	Candidates []string `json:"candidates,omitempty"`
+
+	// CandidateTemplates generates candidate deployments from copies of baseline deployment
+	// Generated candidates follow candidates listed in Candidates, and are deleted with other targets by cleanup
+	// Only applicable to Deployment targets
+	// +optional
+	CandidateTemplates []CandidateTemplate `json:"candidateTemplates,omitempty"`
+
+	// CandidateSelector selects candidate deployments by labels, in addition to Candidates
+	// Matching deployments are added as candidates in order of creation, including those created during the experiment
+	// +optional
+	CandidateSelector *metav1.LabelSelector `json:"candidateSelector,omitempty"`
+
+	// MaxCandidates is the maximum number of candidates selected by CandidateSelector
+	// default is 1
+	// +optional
+	MaxCandidates *int32 `json:"maxCandidates,omitempty"`
 
 	// Port number exposed by internal services
+	// Port should not be specified if Ports is specified
 	Port *int32 `json:"port,omitempty"`
+
+	// Ports exposed by internal services, all of which share the same traffic split
+	// +optional
+	Ports []ServicePort `json:"ports,omitempty"`
+}
+
+// ServicePort specifies a port exposed by internal services
+type ServicePort struct {
+	// Number of the port
+	Number int32 `json:"number"`
+
+	// Protocol of the port, one of HTTP, TCP and TLS
+	// default is HTTP
+	// +optional
+	Protocol *PortProtocolType `json:"protocol,omitempty"`
+
+	// SNIHosts are SNI names of TLS traffic to be routed
+	// Only applicable to TLS ports; default is hosts of the experiment
+	// +optional
+	SNIHosts []string `json:"sniHosts,omitempty"`
+}
+
+// CandidateTemplate specifies patches applied to a copy of baseline deployment to generate a candidate
+type CandidateTemplate struct {
+	// Name of the generated candidate deployment
+	Name string `json:"name"`
+
+	// Labels override pod labels of the copy, and should differentiate pods of candidate from pods of baseline
+	// At least one of them should change the value of a pod label of baseline
+	// default sets label version, which baseline pods should have, to name of the candidate
+	// +optional
+	Labels map[string]string `json:"labels,omitempty"`
+
+	// Container to be patched
+	// default is the first container
+	// +optional
+	Container *string `json:"container,omitempty"`
+
+	// Image of the container
+	// +optional
+	Image *string `json:"image,omitempty"`
+
+	// Env adds or replaces environment variables of the container
+	// +optional
+	Env []corev1.EnvVar `json:"env,omitempty"`
+
+	// Args replaces arguments of the container
+	// +optional
+	Args []string `json:"args,omitempty"`
 }
 
 // Host holds the name of host and gateway associated with it
@@ -133,6 +261,18 @@ type Criterion struct {
 	// IsReward indicates whether the metric is a reward metric or not
 	// +optional
 	IsReward *bool `json:"isReward,omitempty"`
+
+	// Weight of the reward metric in the combined objective
+	// Applied to reward criterion with weighted reward policy only
+	// default is 1
+	// +optional
+	Weight *float32 `json:"weight,omitempty"`
+
+	// Priority of the reward metric, smaller value is compared first
+	// Applied to reward criterion with lexicographic reward policy only
+	// default is the order of the criterion in criteria
+	// +optional
+	Priority *int32 `json:"priority,omitempty"`
 }
 
 // Threshold defines the value and type of a criterion threshold
@@ -151,36 +291,213 @@ type Threshold struct {
 	CutoffTrafficOnViolation *bool `json:"cutoffTrafficOnViolation,omitempty"`
 }
 
+// AssessmentConfig specifies the method used to assess versions and its parameters
+type AssessmentConfig struct {
+	// Method used to assess versions
+	// bayesian: winner is decided by win probability from analytics
+	// frequentist: winner is decided by fixed-horizon two-sample tests against baseline
+	// default is bayesian
+	// +kubebuilder:validation:Enum={bayesian,frequentist}
+	// +optional
+	Method *AssessmentMethodType `json:"method,omitempty"`
+
+	// Parameters of the frequentist tests
+	// Applied to frequentist method only
+	// +optional
+	Frequentist *FrequentistConfig `json:"frequentist,omitempty"`
+}
+
+// FrequentistConfig specifies the parameters of fixed-horizon two-sample tests
+type FrequentistConfig struct {
+	// Test used to compare each candidate with baseline
+	// welch_t_test: Welch's t-test for means
+	// z_test: z-test for proportions
+	// mann_whitney_u: Mann-Whitney U test
+	// default is z_test for ratio metrics in range 0 to 1, and welch_t_test for others
+	// +kubebuilder:validation:Enum={welch_t_test,z_test,mann_whitney_u}
+	// +optional
+	Test *StatisticalTestType `json:"test,omitempty"`
+
+	// Alpha is the significance level of the test
+	// default is 0.05
+	// +optional
+	Alpha *float32 `json:"alpha,omitempty"`
+
+	// Power is the probability of detecting an effect of the minimum detectable size
+	// default is 0.8
+	// +optional
+	Power *float32 `json:"power,omitempty"`
+
+	// MinimumDetectableEffect is the smallest change relative to baseline value the test should detect
+	// default is 0.05
+	// +optional
+	MinimumDetectableEffect *float32 `json:"minimumDetectableEffect,omitempty"`
+}
+
 // Duration specifies how often/many times the expriment should re-evaluate the assessment
 type Duration struct {
 	// Interval specifies duration between iterations
 	// default is 30s
 	// +optional
 	Interval *string `json:"interval,omitempty"`
+
 	// MaxIterations indicates the amount of iteration
 	// default is 100
 	// +optional
 	MaxIterations *int32 `json:"maxIterations,omitempty"`
+
+	// Warmup specifies duration from the start of experiment during which metrics are not assessed
+	// Traffic is held at the initial split during warm-up,
+	// and the warm-up period is excluded from the time range of analytics
+	// Iterations during warm-up count towards maxIterations
+	// default is no warm-up
+	// +optional
+	Warmup *string `json:"warmup,omitempty"`
+
+	// ProgressDeadline specifies maximum duration candidates may stay unavailable
+	// No traffic is shifted to unavailable candidates, and the experiment fails after the deadline
+	// default is 10m
+	// +optional
+	ProgressDeadline *string `json:"progressDeadline,omitempty"`
+}
+
+// Bake specifies the period after promotion during which criteria are still evaluated
+type Bake struct {
+	// Duration of the bake period, starting when traffic goes to the winner
+	Duration string `json:"duration"`
+}
+
+// Scaling specifies how replicas of versions are scaled in proportion to their traffic
+type Scaling struct {
+	// MinReplicas is the minimum number of replicas of each version
+	// default is 1
+	// +optional
+	MinReplicas *int32 `json:"minReplicas,omitempty"`
+}
+
+// PodHealth specifies health checks on pods of candidates
+type PodHealth struct {
+	// RestartThreshold is the number of container restarts above which a candidate is unhealthy
+	// default is 3
+	// +optional
+	RestartThreshold *int32 `json:"restartThreshold,omitempty"`
+
+	// ReadinessTimeout is the duration a running pod may stay not ready before its candidate is unhealthy
+	// default is 2m
+	// +optional
+	ReadinessTimeout *string `json:"readinessTimeout,omitempty"`
+}
+
+// Promotion specifies how the winner is promoted to baseline
+type Promotion struct {
+	// CandidateAction is the action applied to the winning candidate after baseline is promoted
+	// options: delete, scale_down; default is delete
+	// +optional
+	CandidateAction *CandidateActionType `json:"candidateAction,omitempty"`
+}
+
+// EarlyStop specifies the policy used to stop the experiment early
+type EarlyStop struct {
+	// Policy used to decide whether to stop the experiment
+	// win_probability: the same winner holds a win probability above threshold for a number of consecutive iterations
+	// sprt: sequential probability ratio test on a ratio metric in range 0 to 1 accepts or rejects all candidates
+	// default is win_probability
+	// +kubebuilder:validation:Enum={win_probability,sprt}
+	// +optional
+	Policy *EarlyStopPolicyType `json:"policy,omitempty"`
+
+	// Threshold of win probability in percentage to be held by the winner
+	// Applied to win_probability policy only
+	// default is 95
+	// +optional
+	Threshold *float32 `json:"threshold,omitempty"`
+
+	// ConsecutiveIterations is the number of consecutive iterations the winner must hold the threshold
+	// Applied to win_probability policy only
+	// default is 3
+	// +optional
+	ConsecutiveIterations *int32 `json:"consecutiveIterations,omitempty"`
+
+	// Alpha is the probability of accepting a candidate that is not better than baseline
+	// Applied to sprt policy only
+	// default is 0.05
+	// +optional
+	Alpha *float32 `json:"alpha,omitempty"`
+
+	// Beta is the probability of rejecting a candidate that is better than baseline by the minimum detectable effect
+	// Applied to sprt policy only
+	// default is 0.2
+	// +optional
+	Beta *float32 `json:"beta,omitempty"`
+
+	// Metric used by the test; must be a ratio metric in range 0 to 1
+	// Applied to sprt policy only
+	// default is the metric of the first reward criterion, or the first criterion if no reward is specified
+	// +optional
+	Metric *string `json:"metric,omitempty"`
 }
 
 // TrafficControl specifies constrains on traffic and stratgy used to update the traffic
 type TrafficControl struct {
 	// Strategy used to shift traffic
 	// default is progressive
-	// +kubebuilder:validation:Enum={progressive, top_2, uniform}
+	// +kubebuilder:validation:Enum={progressive, top_2, uniform, fixed_steps, blue_green}
 	// +optional
 	Strategy *StrategyType `json:"strategy,omitempty"`
 
+	// Steps of traffic to candidates in fixed_steps strategy
+	// Traffic moves to the next step once the hold duration of current step elapses and all criteria pass;
+	// candidates failing the criteria are rolled back
+	// Without criteria, traffic moves on hold durations alone, and only candidates with unhealthy pods are rolled back
+	// Traffic stays at the initial split during warm-up
+	// Applied to fixed_steps strategy only
+	// +optional
+	Steps []TrafficStep `json:"steps,omitempty"`
+
 	// OnTermination determines traffic split status at the end of experiment
 	// +kubebuilder:validation:Enum={to_winner,to_baseline,keep_last}
 	// +optional
 	OnTermination *OnTerminationType `json:"onTermination,omitempty"`
 
+	// Ramp shifts traffic to winner gradually after all iterations, instead of in a single update
+	// Criteria with thresholds keep being checked during the ramp; if one is breached,
+	// traffic goes back to the last safe split and the experiment is aborted
+	// Applied to to_winner onTermination only
+	// +optional
+	Ramp *Ramp `json:"ramp,omitempty"`
+
 	// Only requests fulfill the match section would be used in experiment
 	// Istio matching rules are used
 	// +optional
 	Match *Match `json:"match,omitempty"`
 
+	// Disjoint declares that requests fulfilling the match section never fulfill
+	// those of other experiments on the same service, e.g. different platforms of users
+	// Experiments with disjoint match sections can run concurrently on the service,
+	// each with its own route and subsets in the shared routing rules
+	// default is false
+	// +optional
+	Disjoint *bool `json:"disjoint,omitempty"`
+
+	// Segments split requests into named segments by HTTP match rules
+	// Each segment gets its own route, traffic split and assessment, and can end with its own winner;
+	// requests out of segments follow the traffic split of the whole experiment
+	// Requests in a segment carry the segment name in header x-iter8-segment, which should be
+	// added to telemetry as label iter8_segment, so that versions are assessed per segment;
+	// segments are rejected unless the controller runs with flag segment-telemetry, telling it carries this label
+	// +optional
+	Segments []Segment `json:"segments,omitempty"`
+
+	// Sticky assigns each user to one version, instead of assigning each request by weights
+	// Users are bucketed by hashing their keys into 100 buckets, which are assigned to versions by weights;
+	// when weights change, only buckets in excess of the new weights move to other versions
+	// Requests without the key are assigned by weights
+	// Users are bucketed by clients in the namespace of the service and by gateways of the experiment;
+	// requests from clients in other namespaces are assigned by weights
+	// An HTTP port of the service should be specified
+	// +optional
+	Sticky *Sticky `json:"sticky,omitempty"`
+
 	// Percentage specifies the amount of traffic to service that would be used in experiment
 	// default is 100
 	// +optional
@@ -191,12 +508,81 @@ type TrafficControl struct {
 	// +optional
 	MaxIncrement *int32 `json:"maxIncrement,omitempty"`
 
+	// BlueGreen specifies how candidate is validated and switched in blue_green strategy
+	// Applied to blue_green strategy only
+	// +optional
+	BlueGreen *BlueGreen `json:"blueGreen,omitempty"`
+
+	// MinRequestCount is the number of requests each version must serve before its traffic can be changed
+	// Traffic is held steady until it is reached by every version receiving traffic,
+	// and no winner is declared until it is reached by every version
+	// In blue_green strategy, it is the number of requests the candidate must serve before traffic is switched to it,
+	// and should be specified
+	// default is 0
+	// +optional
+	MinRequestCount *int32 `json:"minRequestCount,omitempty"`
+
+	// MinObservationTime is the duration each version must receive traffic before its traffic can be changed
+	// Traffic is held steady until it is reached by every version receiving traffic,
+	// and no winner is declared until it is reached by every version
+	// default is 0s
+	// +optional
+	MinObservationTime *string `json:"minObservationTime,omitempty"`
+
 	// RouterID refers to the id of router used to handle traffic for the experiment
 	// If it's not specified, the first entry of effictive host will be used as the id
 	// +optional
 	RouterID *string `json:"routerID,omitempty"`
 }
 
+// Ramp specifies how traffic is shifted to winner at the end of experiment
+type Ramp struct {
+	// Steps is the number of traffic updates in the ramp
+	// default is 5
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	Steps *int32 `json:"steps,omitempty"`
+
+	// Duration is the total duration of the ramp
+	// default is 5m
+	// +optional
+	Duration *string `json:"duration,omitempty"`
+}
+
+// TrafficStep is a step of traffic in fixed_steps strategy
+type TrafficStep struct {
+	// Weight is the percentage of traffic to candidates in this step
+	// It is split evenly among candidates not rolled back
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=100
+	Weight int32 `json:"weight"`
+
+	// Hold is the minimum duration of this step before moving to the next one
+	// default is the interval of experiment
+	// +optional
+	Hold *string `json:"hold,omitempty"`
+}
+
+// BlueGreen specifies validation and switch of candidate in blue_green strategy
+// The candidate receives no live traffic until it has served minRequestCount requests and all criteria pass,
+// then all traffic is switched to it at once
+type BlueGreen struct {
+	// TestMatch routes matching requests to candidate during validation
+	// Istio matching rules are used
+	// +optional
+	TestMatch *Match `json:"testMatch,omitempty"`
+
+	// Mirror indicates whether live traffic is mirrored to candidate during validation
+	// default is false
+	// +optional
+	Mirror *bool `json:"mirror,omitempty"`
+
+	// RollbackWindow is the duration after switch during which traffic is switched back to baseline instantly if criteria fail
+	// default is 5m
+	// +optional
+	RollbackWindow *string `json:"rollbackWindow,omitempty"`
+}
+
 // Match contains matching criteria for requests
 type Match struct {
 	// Matching criteria for HTTP requests
@@ -204,6 +590,27 @@ type Match struct {
 	HTTP []*HTTPMatchRequest `json:"http,omitempty"`
 }
 
+// Segment is a named part of requests in the experiment
+type Segment struct {
+	// Name of the segment
+	Name string `json:"name"`
+
+	// Requests fulfilling the match section are in the segment
+	// Istio matching rules are used
+	Match Match `json:"match"`
+}
+
+// Sticky specifies the key identifying the user of a request
+type Sticky struct {
+	// Header whose value identifies the user, e.g. x-user-id
+	// +optional
+	Header *string `json:"header,omitempty"`
+
+	// Cookie whose value identifies the user
+	// +optional
+	Cookie *string `json:"cookie,omitempty"`
+}
+
 // ManualOverride defines actions that the user can perform to an experiment
 type ManualOverride struct {
 	// Action to perform
@@ -303,6 +710,18 @@ type ExperimentStatus struct {
 	// +optional
 	CurrentIteration *int32 `json:"currentIteration,omitempty"`
 
+	// SelectedBaseline is the name of baseline selected by baselineSelector
+	// +optional
+	SelectedBaseline *string `json:"selectedBaseline,omitempty"`
+
+	// SelectedCandidates are names of candidates selected by candidateSelector
+	// +optional
+	SelectedCandidates []string `json:"selectedCandidates,omitempty"`
+
+	// UnavailableSince is the timestamp since when some candidate is not available to receive traffic
+	// +optional
+	UnavailableSince *metav1.Time `json:"unavailableSince,omitempty"`
+
 	// Assessment returned by the last analyis
 	// +optional
 	Assessment *Assessment `json:"assessment,omitempty"`
@@ -311,6 +730,11 @@ type ExperimentStatus struct {
 	// +optional
 	Phase PhaseType `json:"phase,omitempty"`
 
+	// QueuePosition is the position of the experiment among experiments waiting for their targets, starting from 1
+	// Set in Queued phase only
+	// +optional
+	QueuePosition *int32 `json:"queuePosition,omitempty"`
+
 	// Message specifies message to show in the kubectl printer
 	// +optional
 	Message *string `json:"message,omitempty"`
@@ -325,6 +749,149 @@ type ExperimentStatus struct {
 	// EffectiveHosts is computed host for experiment.
 	// List of spec.Service.Name and spec.Service.Hosts[0].name
 	EffectiveHosts []string `json:"effectiveHosts,omitempty"`
+
+	// EarlyStop records progress of the early-stop policy
+	// +optional
+	EarlyStop *EarlyStopStatus `json:"earlyStop,omitempty"`
+
+	// CurrentStep is the index of current step in fixed_steps strategy
+	// +optional
+	CurrentStep *int32 `json:"currentStep,omitempty"`
+
+	// StepTimestamp is the timestamp when current step in fixed_steps strategy starts
+	// +optional
+	StepTimestamp *metav1.Time `json:"stepTimestamp,omitempty"`
+
+	// BlueGreen records progress of blue_green strategy
+	// +optional
+	BlueGreen *BlueGreenStatus `json:"blueGreen,omitempty"`
+
+	// Ramp records progress of traffic ramp to winner
+	// +optional
+	Ramp *RampStatus `json:"ramp,omitempty"`
+
+	// Bake records progress of bake period after promotion
+	// +optional
+	Bake *BakeStatus `json:"bake,omitempty"`
+
+	// Promotion records progress of promotion of winner to baseline
+	// +optional
+	Promotion *PromotionStatus `json:"promotion,omitempty"`
+
+	// Scaling records replicas of versions before they are scaled in proportion to traffic
+	// +optional
+	Scaling *ScalingStatus `json:"scaling,omitempty"`
+
+	// Outcome of the experiment, set when the experiment is completed
+	// +optional
+	Outcome *OutcomeType `json:"outcome,omitempty"`
+
+	// Segments records traffic split and assessment of each segment
+	// +optional
+	Segments []SegmentStatus `json:"segments,omitempty"`
+
+	// Buckets records the version assigned to each bucket of users in sticky assignment, indexed by bucket
+	// +optional
+	Buckets []string `json:"buckets,omitempty"`
+
+	// RestartCounts records restart counts of containers of candidate pods created before the experiment starts,
+	// keyed by pod/container, as first seen by pod health checks; only restarts after them are counted
+	// +optional
+	RestartCounts map[string]int32 `json:"restartCounts,omitempty"`
+}
+
+// SegmentStatus records progress of a segment
+type SegmentStatus struct {
+	// Name of the segment
+	Name string `json:"name"`
+
+	// Assessment of versions by requests in the segment, including their traffic split
+	Assessment Assessment `json:"assessment"`
+
+	// AnalysisState is the last recorded analysis state of the segment
+	// +optional
+	AnalysisState *runtime.RawExtension `json:"analysisState,omitempty"`
+}
+
+// BlueGreenStatus records progress of blue_green strategy
+type BlueGreenStatus struct {
+	// Phase of blue_green strategy
+	// +kubebuilder:validation:Enum={Validating,RollbackWindow,Switched}
+	Phase BlueGreenPhaseType `json:"phase"`
+
+	// SwitchTimestamp is the timestamp when traffic is switched to candidate
+	// +optional
+	SwitchTimestamp *metav1.Time `json:"switchTimestamp,omitempty"`
+}
+
+// ScalingStatus records replicas of versions before they are scaled in proportion to traffic
+type ScalingStatus struct {
+	// TotalReplicas is the number of replicas serving all traffic, taken from baseline when scaling starts
+	TotalReplicas int32 `json:"totalReplicas"`
+
+	// OriginalMinReplicas records minReplicas of HPAs of versions before they are changed
+	// +optional
+	OriginalMinReplicas map[string]int32 `json:"originalMinReplicas,omitempty"`
+}
+
+// PromotionStatus records progress of promotion of winner to baseline
+type PromotionStatus struct {
+	// Phase of the promotion
+	Phase PromotionPhaseType `json:"phase"`
+
+	// Winner is the name of the candidate promoted to baseline
+	Winner string `json:"winner"`
+
+	// StartTimestamp is the timestamp when promotion starts
+	// Promotion fails if baseline is not updated and rolled out within progressDeadline from then
+	StartTimestamp *metav1.Time `json:"startTimestamp"`
+}
+
+// BakeStatus records progress of bake period after promotion
+type BakeStatus struct {
+	// StartTimestamp is the timestamp when traffic goes to the winner
+	StartTimestamp *metav1.Time `json:"startTimestamp"`
+
+	// RolledBack indicates traffic has been routed back to baseline due to breached criteria
+	// +optional
+	RolledBack bool `json:"rolledBack,omitempty"`
+}
+
+// RampStatus records progress of traffic ramp to winner
+type RampStatus struct {
+	// Step is the number of traffic updates completed in the ramp
+	Step int32 `json:"step"`
+
+	// StartSplit is the traffic split when the ramp starts
+	// +optional
+	StartSplit map[string]int32 `json:"startSplit,omitempty"`
+
+	// LastSafeSplit is the latest traffic split in which no criteria is breached
+	// +optional
+	LastSafeSplit map[string]int32 `json:"lastSafeSplit,omitempty"`
+
+	// StepTimestamp is the time when traffic of the current step is applied
+	// Criteria are assessed by metrics collected since then
+	// +optional
+	StepTimestamp *metav1.Time `json:"stepTimestamp,omitempty"`
+}
+
+// EarlyStopStatus records progress of the early-stop policy
+type EarlyStopStatus struct {
+	// Name of the version holding the win probability threshold in the latest iterations
+	// +optional
+	Winner *string `json:"winner,omitempty"`
+
+	// Number of consecutive iterations the winner has held the threshold
+	ConsecutiveIterations int32 `json:"consecutiveIterations"`
+
+	// Met indicates the early-stop policy has been met and remaining iterations are skipped
+	// +optional
+	Met bool `json:"met,omitempty"`
+
+	// RequiredSampleSize is the number of requests each version needs before the sprt policy can make a decision
+	// +optional
+	RequiredSampleSize *int32 `json:"requiredSampleSize,omitempty"`
 }
 
 // Conditions is a list of ExperimentConditions
@@ -361,6 +928,15 @@ type Assessment struct {
 
 	// Assessment for winner target if exists
 	Winner *WinnerAssessment `json:"winner,omitempty"`
+
+	// RequiredSampleSize is the number of requests each version needs before a winner can be declared
+	// Only available with frequentist assessment method
+	// +optional
+	RequiredSampleSize *int32 `json:"requiredSampleSize,omitempty"`
+
+	// NextCandidateIndex is the lowest index not used by any candidate, including retired ones
+	// +optional
+	NextCandidateIndex int32 `json:"nextCandidateIndex,omitempty"`
 }
 
 // WinnerAssessment shows assessment details for winner of an experiment
@@ -371,6 +947,11 @@ type WinnerAssessment struct {
 
 	// Assessment details from analytics
 	*analyticsv1alpha2.WinnerAssessment `json:",inline,omitempty"`
+
+	// PValue of the test of the winner against baseline on the primary criterion
+	// Only available with frequentist assessment method when a candidate wins
+	// +optional
+	PValue *float32 `json:"pValue,omitempty"`
 }
 
 // VersionAssessment contains assessment details for each version
@@ -381,10 +962,59 @@ type VersionAssessment struct {
 	// Weight of traffic
 	Weight int32 `json:"weight"`
 
+	// Index is the stable index of a candidate, used to identify it in analytics and routing rules
+	// Indexes of candidates removed during the experiment are not reused
+	// +optional
+	Index *int32 `json:"index,omitempty"`
+
 	// Assessment details from analytics
 	analyticsv1alpha2.VersionAssessment `json:",inline"`
 
 	// A flag indicates whether traffic to this target should be cutoff
 	// +optional
 	Rollback bool `json:"rollback,omitempty"`
+
+	// Unhealthy indicates pods of this version fail health checks, and traffic to it is cut off
+	// +optional
+	Unhealthy bool `json:"unhealthy,omitempty"`
+
+	// Retired indicates the candidate is removed from the experiment; its traffic is drained before its routing subset is removed
+	// +optional
+	Retired bool `json:"retired,omitempty"`
+
+	// TrafficStartTimestamp is the time when this version starts to receive traffic
+	// +optional
+	TrafficStartTimestamp *metav1.Time `json:"trafficStartTimestamp,omitempty"`
+
+	// Objective is the combined value of reward metrics used in winner selection
+	// Only available when more than one reward criterion is specified with weighted reward policy
+	// +optional
+	Objective *float32 `json:"objective,omitempty"`
+
+	// Breakdown of each reward metric in the combined objective
+	// Only available when more than one reward criterion is specified
+	// +optional
+	RewardAssessments []RewardAssessment `json:"rewardAssessments,omitempty"`
+}
+
+// RewardAssessment shows the contribution of a reward metric to the combined objective of a version
+type RewardAssessment struct {
+	// Name of the reward metric
+	Metric string `json:"metric"`
+
+	// Value of the reward metric from analytics
+	// +optional
+	Value *float32 `json:"value,omitempty"`
+
+	// Improvement of the value over baseline, relative to the baseline value
+	// Positive number indicates the value is better than baseline regardless of preferred direction
+	// +optional
+	Improvement *float32 `json:"improvement,omitempty"`
+
+	// Weight of the reward metric in the combined objective
+	Weight float32 `json:"weight"`
+
+	// Priority of the reward metric in lexicographic comparison
+	// +optional
+	Priority *int32 `json:"priority,omitempty"`
 }
@@ -65,18 +65,21 @@ func (c *ExperimentCondition) IsFalse() bool {
 func (e *Experiment) InitStatus() {
 	e.Status.Assessment = &Assessment{
 		Baseline: VersionAssessment{
-			Name:   e.Spec.Baseline,
+			Name:   e.GetBaseline(),
 			Weight: int32(0),
 			VersionAssessment: v1alpha2.VersionAssessment{
 				CriterionAssessments: make([]v1alpha2.CriterionAssessment, 0),
 			},
 		},
-		Candidates: make([]VersionAssessment, len(e.Spec.Candidates)),
+		Candidates:         make([]VersionAssessment, len(e.GetCandidates())),
+		NextCandidateIndex: int32(len(e.GetCandidates())),
 	}
-	for i, name := range e.Spec.Candidates {
+	for i, name := range e.GetCandidates() {
+		index := int32(i)
 		e.Status.Assessment.Candidates[i] = VersionAssessment{
 			Name:   name,
 			Weight: int32(0),
+			Index:  &index,
 			VersionAssessment: v1alpha2.VersionAssessment{
 				CriterionAssessments: make([]v1alpha2.CriterionAssessment, 0),
 			},
@@ -116,7 +119,7 @@ const (
 )
 
 func (spec *ExperimentSpec) experimentType() string {
-	numCandidates := len(spec.Service.Candidates)
+	numCandidates := len(spec.Service.GetCandidates())
 	if 0 == numCandidates {
 		return ExperimentTypePerformance
 	} else if 1 == numCandidates && spec.hasReward() {
@@ -218,6 +221,22 @@ func (s *ExperimentStatus) MarkTargetsError(messageFormat string, messageA ...in
 		markCondition(corev1.ConditionFalse, reason, messageFormat, messageA...), reason
 }
 
+// MarkProgressDeadlineExceeded sets the condition that candidates do not become available in time
+// Return true if it's converted from true or unknown
+func (s *ExperimentStatus) MarkProgressDeadlineExceeded(messageFormat string, messageA ...interface{}) (bool, string) {
+	reason := ReasonProgressDeadlineExceeded
+	message := composeMessage(reason, messageFormat, messageA...)
+	s.Message = &message
+	return s.GetCondition(ExperimentConditionTargetsProvided).
+		markCondition(corev1.ConditionFalse, reason, messageFormat, messageA...), reason
+}
+
+// ProgressDeadlineExceeded returns whether candidates failed to become available in time
+func (s *ExperimentStatus) ProgressDeadlineExceeded() bool {
+	c := s.GetCondition(ExperimentConditionTargetsProvided)
+	return c.Status == corev1.ConditionFalse && c.Reason != nil && *c.Reason == ReasonProgressDeadlineExceeded
+}
+
 // MarkRoutingRulesReady sets the condition that the routing rules are ready
 // Return true if it's converted from false or unknown
 func (s *ExperimentStatus) MarkRoutingRulesReady(messageFormat string, messageA ...interface{}) (bool, string) {
@@ -258,6 +277,164 @@ func (s *ExperimentStatus) MarkAnalyticsServiceError(messageFormat string, messa
 		markCondition(corev1.ConditionFalse, reason, messageFormat, messageA...), reason
 }
 
+// EarlyStopped returns whether the early-stop policy of experiment has been met
+func (s *ExperimentStatus) EarlyStopped() bool {
+	return s.EarlyStop != nil && s.EarlyStop.Met
+}
+
+// GetBlueGreenPhase returns the phase of blue_green strategy, empty if not started
+func (s *ExperimentStatus) GetBlueGreenPhase() BlueGreenPhaseType {
+	if s.BlueGreen == nil {
+		return ""
+	}
+	return s.BlueGreen.Phase
+}
+
+// InBlueGreenValidation tells whether candidate is being validated in blue_green strategy
+func (e *Experiment) InBlueGreenValidation() bool {
+	if e.Spec.GetStrategy() != string(StrategyBlueGreen) {
+		return false
+	}
+	phase := e.Status.GetBlueGreenPhase()
+	return phase == "" || phase == BlueGreenPhaseValidating
+}
+
+// RolledBackAfterPromotion returns whether traffic has been routed back to baseline during bake period
+func (s *ExperimentStatus) RolledBackAfterPromotion() bool {
+	return s.Bake != nil && s.Bake.RolledBack
+}
+
+// PromotionFailed returns whether the winner could not be promoted to baseline before progress deadline
+func (s *ExperimentStatus) PromotionFailed() bool {
+	return s.Promotion != nil && s.Promotion.Phase == PromotionPhaseFailed
+}
+
+// Promoted returns whether the winner has been promoted to baseline
+func (s *ExperimentStatus) Promoted() bool {
+	return s.Promotion != nil && s.Promotion.Phase == PromotionPhaseCompleted
+}
+
+// RestartAssessment clears assessment of all versions and restarts the experiment from the first iteration
+// Current traffic split is kept
+func (s *ExperimentStatus) RestartAssessment() {
+	now := metav1.Now()
+	s.StartTimestamp = &now
+	currentIteration := int32(0)
+	s.CurrentIteration = &currentIteration
+	s.AnalysisState = &runtime.RawExtension{
+		Raw: []byte("{}"),
+	}
+	s.EarlyStop = nil
+
+	if s.Assessment == nil {
+		return
+	}
+	s.Assessment.Winner = nil
+	s.Assessment.RequiredSampleSize = nil
+	versions := []*VersionAssessment{&s.Assessment.Baseline}
+	for i := range s.Assessment.Candidates {
+		versions = append(versions, &s.Assessment.Candidates[i])
+	}
+	for _, va := range versions {
+		va.VersionAssessment = v1alpha2.VersionAssessment{
+			CriterionAssessments: make([]v1alpha2.CriterionAssessment, 0),
+		}
+		va.Rollback = va.Unhealthy || va.Retired
+		va.TrafficStartTimestamp = nil
+	}
+}
+
+// SelectBaseline records the baseline selected by baselineSelector
+func (s *ExperimentStatus) SelectBaseline(name string) {
+	s.SelectedBaseline = &name
+	if s.Assessment != nil {
+		s.Assessment.Baseline.Name = name
+	}
+}
+
+// SelectCandidate records a candidate selected by candidateSelector
+func (s *ExperimentStatus) SelectCandidate(name string) {
+	s.SelectedCandidates = append(s.SelectedCandidates, name)
+}
+
+// CandidateIndex returns the stable index of candidate with given name, -1 if not found
+func (a *Assessment) CandidateIndex(name string) int {
+	for i, candidate := range a.Candidates {
+		if candidate.Name != name {
+			continue
+		}
+		if candidate.Index == nil {
+			// assessments created before indexes were introduced are ordered by index
+			return i
+		}
+		return int(*candidate.Index)
+	}
+	return -1
+}
+
+// AddCandidate appends assessment of a new candidate with weight 0 and an unused index
+func (a *Assessment) AddCandidate(name string) {
+	a.pinIndexes()
+	next := a.NextCandidateIndex
+	a.NextCandidateIndex++
+	a.Candidates = append(a.Candidates, VersionAssessment{
+		Name:   name,
+		Weight: int32(0),
+		Index:  &next,
+		VersionAssessment: v1alpha2.VersionAssessment{
+			CriterionAssessments: make([]v1alpha2.CriterionAssessment, 0),
+		},
+	})
+}
+
+// DrainCandidate marks candidate with given name as retired, and returns its traffic to baseline
+// Its assessment is kept until RetireCandidate, so that its routing subset outlives its traffic
+func (a *Assessment) DrainCandidate(name string) {
+	for i := range a.Candidates {
+		candidate := &a.Candidates[i]
+		if candidate.Name != name {
+			continue
+		}
+		a.Baseline.Weight += candidate.Weight
+		candidate.Weight = 0
+		candidate.Retired = true
+		candidate.Rollback = true
+		if a.Winner != nil && a.Winner.Name != nil && *a.Winner.Name == name {
+			a.Winner = nil
+		}
+		return
+	}
+}
+
+// RetireCandidate removes assessment of candidate with given name, and returns its traffic to baseline
+func (a *Assessment) RetireCandidate(name string) {
+	a.pinIndexes()
+	for i, candidate := range a.Candidates {
+		if candidate.Name != name {
+			continue
+		}
+		a.Baseline.Weight += candidate.Weight
+		a.Candidates = append(a.Candidates[:i], a.Candidates[i+1:]...)
+		if a.Winner != nil && a.Winner.Name != nil && *a.Winner.Name == name {
+			a.Winner = nil
+		}
+		return
+	}
+}
+
+// pinIndexes sets indexes of candidates which are not set yet, and keeps NextCandidateIndex above them
+func (a *Assessment) pinIndexes() {
+	for i := range a.Candidates {
+		if a.Candidates[i].Index == nil {
+			index := int32(i)
+			a.Candidates[i].Index = &index
+		}
+		if *a.Candidates[i].Index >= a.NextCandidateIndex {
+			a.NextCandidateIndex = *a.Candidates[i].Index + 1
+		}
+	}
+}
+
 // ExperimentCompleted returns whether experiment is completed or not
 func (s *ExperimentStatus) ExperimentCompleted() bool {
 	return s.GetCondition(ExperimentConditionExperimentCompleted).Status == corev1.ConditionTrue
@@ -305,6 +482,36 @@ func (s *ExperimentStatus) MarkTrafficUpdate(messageFormat string, messageA ...i
 		markCondition(corev1.ConditionFalse, reason, messageFormat, messageA...), reason
 }
 
+// MarkCandidateUnhealthy sets the condition that a candidate is rolled back since its pods are unhealthy
+func (s *ExperimentStatus) MarkCandidateUnhealthy(messageFormat string, messageA ...interface{}) (bool, string) {
+	reason := ReasonCandidateUnhealthy
+	message := composeMessage(reason, messageFormat, messageA...)
+	s.Phase = PhaseProgressing
+	s.Message = &message
+	return s.GetCondition(ExperimentConditionExperimentCompleted).
+		markCondition(corev1.ConditionFalse, reason, messageFormat, messageA...), reason
+}
+
+// MarkTrafficHeld sets the condition that traffic to targets is held steady
+func (s *ExperimentStatus) MarkTrafficHeld(messageFormat string, messageA ...interface{}) (bool, string) {
+	reason := ReasonTrafficHeld
+	message := composeMessage(reason, messageFormat, messageA...)
+	s.Phase = PhaseProgressing
+	s.Message = &message
+	return s.GetCondition(ExperimentConditionExperimentCompleted).
+		markCondition(corev1.ConditionFalse, reason, messageFormat, messageA...), reason
+}
+
+// MarkSampleSizeUnknown sets the condition that the required sample size of frequentist tests cannot be computed
+func (s *ExperimentStatus) MarkSampleSizeUnknown(messageFormat string, messageA ...interface{}) (bool, string) {
+	reason := ReasonSampleSizeUnknown
+	message := composeMessage(reason, messageFormat, messageA...)
+	s.Phase = PhaseProgressing
+	s.Message = &message
+	return s.GetCondition(ExperimentConditionExperimentCompleted).
+		markCondition(corev1.ConditionFalse, reason, messageFormat, messageA...), reason
+}
+
 // MarkExperimentPause sets the phase and status that experiment is paused by manualOverrides
 // returns true if this is a newly-set operation
 func (s *ExperimentStatus) MarkExperimentPause(messageFormat string, messageA ...interface{}) (bool, string) {
@@ -329,10 +536,45 @@ func (s *ExperimentStatus) MarkExperimentResume(messageFormat string, messageA .
 	return true, reason
 }
 
+// MarkExperimentQueued sets the phase and status that experiment waits for other experiments on its targets
+// Return true if it's newly queued or its position in the queue is changed
+func (s *ExperimentStatus) MarkExperimentQueued(position int32, messageFormat string, messageA ...interface{}) (bool, string) {
+	reason := ReasonExperimentQueued
+	message := composeMessage(reason, messageFormat, messageA...)
+	updated := s.Phase != PhaseQueued || s.QueuePosition == nil || *s.QueuePosition != position
+	s.Phase = PhaseQueued
+	s.QueuePosition = &position
+	s.Message = &message
+	if s.GetCondition(ExperimentConditionTargetsProvided).
+		markCondition(corev1.ConditionFalse, reason, messageFormat, messageA...) {
+		updated = true
+	}
+	return updated, reason
+}
+
+// MarkExperimentDequeued sets the phase and status that experiment starts after waiting in the queue
+func (s *ExperimentStatus) MarkExperimentDequeued(messageFormat string, messageA ...interface{}) (bool, string) {
+	reason := ReasonExperimentDequeued
+	message := composeMessage(reason, messageFormat, messageA...)
+	s.Phase = PhaseProgressing
+	s.QueuePosition = nil
+	s.Message = &message
+	return true, reason
+}
+
+// Queued tells whether experiment is waiting for other experiments on its targets
+func (s *ExperimentStatus) Queued() bool {
+	return s.Phase == PhaseQueued
+}
+
 // IsWinnerFound tells whether winner has been found by analytics
 func (s *ExperimentStatus) IsWinnerFound() bool {
-	return s.Assessment != nil && s.Assessment.Winner != nil &&
-		s.Assessment.Winner.WinnerAssessment != nil && s.Assessment.Winner.WinnerAssessment.WinnerFound
+	return s.Assessment != nil && s.Assessment.IsWinnerFound()
+}
+
+// IsWinnerFound tells whether winner has been found by analytics in the assessment
+func (a *Assessment) IsWinnerFound() bool {
+	return a.Winner != nil && a.Winner.WinnerAssessment != nil && a.Winner.WinnerAssessment.WinnerFound
 }
 
 // IsWinnerAssessmentAvailable tells whether winner assessment is presented in status or not
@@ -353,8 +595,15 @@ func (s *ExperimentStatus) WinnerToString() string {
 			return progress + fmt.Sprintf("Current winner (%s) has winning probability of %f.", name,
 				s.Assessment.Winner.Probability)
 		} else {
-			return progress + fmt.Sprintf("Winner has not been found yet. Current best version (%s) has winning probability of %f.", name,
+			out := progress + fmt.Sprintf("Winner has not been found yet. Current best version (%s) has winning probability of %f.", name,
 				s.Assessment.Winner.Probability)
+			if s.Assessment.RequiredSampleSize != nil {
+				out += fmt.Sprintf(" Required sample size per version is %d.", *s.Assessment.RequiredSampleSize)
+			}
+			if s.EarlyStop != nil && s.EarlyStop.RequiredSampleSize != nil {
+				out += fmt.Sprintf(" Required sample size per version for early stop is %d.", *s.EarlyStop.RequiredSampleSize)
+			}
+			return out
 		}
 	} else {
 		return progress + "Not available."
@@ -363,14 +612,24 @@ func (s *ExperimentStatus) WinnerToString() string {
 
 // TrafficToString outputs current traffic in human-readable format
 func (s *ExperimentStatus) TrafficToString() string {
+	out := s.Assessment.trafficToString()
+
+	// Segments
+	for i := range s.Segments {
+		out += fmt.Sprintf(" %s: %s", s.Segments[i].Name, s.Segments[i].Assessment.trafficToString())
+	}
+
+	return out
+}
+
+func (a *Assessment) trafficToString() string {
 	out := ""
 
-	assessment := s.Assessment
 	// Baseline
-	out += fmt.Sprintf("%s: %d", assessment.Baseline.Name, assessment.Baseline.Weight)
+	out += fmt.Sprintf("%s: %d", a.Baseline.Name, a.Baseline.Weight)
 
 	// Candidates
-	for _, candidate := range assessment.Candidates {
+	for _, candidate := range a.Candidates {
 		out += fmt.Sprintf(", %s: %d", candidate.Name, candidate.Weight)
 	}
 
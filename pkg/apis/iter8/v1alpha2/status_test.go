@@ -0,0 +1,172 @@
+/*
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha2
+
+import (
+	"reflect"
+	"testing"
+)
+
+func int32Ptr(i int32) *int32 {
+	return &i
+}
+
+func candidateAssessments(names ...string) []VersionAssessment {
+	out := make([]VersionAssessment, len(names))
+	for i, name := range names {
+		out[i] = VersionAssessment{Name: name}
+	}
+	return out
+}
+
+func TestCandidateIndex(t *testing.T) {
+	indexed := candidateAssessments("v2", "v4")
+	indexed[0].Index, indexed[1].Index = int32Ptr(0), int32Ptr(2)
+
+	tests := []struct {
+		name       string
+		candidates []VersionAssessment
+		candidate  string
+		want       int
+	}{
+		{name: "legacy position", candidates: candidateAssessments("v2", "v3"), candidate: "v3", want: 1},
+		{name: "stable index", candidates: indexed, candidate: "v4", want: 2},
+		{name: "not found", candidates: indexed, candidate: "v3", want: -1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := &Assessment{Candidates: tt.candidates}
+			if got := a.CandidateIndex(tt.candidate); got != tt.want {
+				t.Errorf("CandidateIndex(%s) = %d, want %d", tt.candidate, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCandidateMembership(t *testing.T) {
+	tests := []struct {
+		name        string
+		candidates  []string
+		weights     []int32
+		winner      string
+		drain       []string
+		retire      []string
+		add         []string
+		wantIndexes map[string]int
+		wantWeights map[string]int32
+		wantRetired []string
+		wantWinner  bool
+		wantNext    int32
+	}{
+		{
+			name:        "add after legacy candidates",
+			candidates:  []string{"v2", "v3"},
+			weights:     []int32{10, 20},
+			add:         []string{"v4"},
+			wantIndexes: map[string]int{"v2": 0, "v3": 1, "v4": 2},
+			wantWeights: map[string]int32{"baseline": 70, "v2": 10, "v3": 20, "v4": 0},
+			wantNext:    3,
+		},
+		{
+			name:        "retired index not reused",
+			candidates:  []string{"v2", "v3"},
+			weights:     []int32{10, 20},
+			retire:      []string{"v2"},
+			add:         []string{"v4"},
+			wantIndexes: map[string]int{"v2": -1, "v3": 1, "v4": 2},
+			wantWeights: map[string]int32{"baseline": 80, "v3": 20, "v4": 0},
+			wantNext:    3,
+		},
+		{
+			name:        "drained candidate keeps index without traffic",
+			candidates:  []string{"v2", "v3"},
+			weights:     []int32{10, 20},
+			winner:      "v3",
+			drain:       []string{"v3"},
+			wantIndexes: map[string]int{"v2": 0, "v3": 1},
+			wantWeights: map[string]int32{"baseline": 90, "v2": 10, "v3": 0},
+			wantRetired: []string{"v3"},
+		},
+		{
+			name:        "winner kept when other candidate retires",
+			candidates:  []string{"v2", "v3"},
+			weights:     []int32{10, 20},
+			winner:      "v3",
+			drain:       []string{"v2"},
+			retire:      []string{"v2"},
+			wantIndexes: map[string]int{"v2": -1, "v3": 1},
+			wantWeights: map[string]int32{"baseline": 80, "v3": 20},
+			wantWinner:  true,
+			wantNext:    2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := &Assessment{
+				Baseline:   VersionAssessment{Name: "baseline", Weight: 100},
+				Candidates: candidateAssessments(tt.candidates...),
+			}
+			for i := range a.Candidates {
+				a.Candidates[i].Weight = tt.weights[i]
+				a.Baseline.Weight -= tt.weights[i]
+			}
+			if tt.winner != "" {
+				a.Winner = &WinnerAssessment{Name: &tt.winner}
+			}
+
+			for _, name := range tt.drain {
+				a.DrainCandidate(name)
+			}
+			for _, name := range tt.retire {
+				a.RetireCandidate(name)
+			}
+			for _, name := range tt.add {
+				a.AddCandidate(name)
+			}
+
+			for name, want := range tt.wantIndexes {
+				if got := a.CandidateIndex(name); got != want {
+					t.Errorf("CandidateIndex(%s) = %d, want %d", name, got, want)
+				}
+			}
+			weights := map[string]int32{a.Baseline.Name: a.Baseline.Weight}
+			retired := []string{}
+			for _, candidate := range a.Candidates {
+				weights[candidate.Name] = candidate.Weight
+				if candidate.Retired {
+					retired = append(retired, candidate.Name)
+					if !candidate.Rollback {
+						t.Errorf("retired candidate %s should be rolled back", candidate.Name)
+					}
+				}
+			}
+			if !reflect.DeepEqual(weights, tt.wantWeights) {
+				t.Errorf("weights = %v, want %v", weights, tt.wantWeights)
+			}
+			if len(retired) != len(tt.wantRetired) || len(retired) > 0 && !reflect.DeepEqual(retired, tt.wantRetired) {
+				t.Errorf("retired = %v, want %v", retired, tt.wantRetired)
+			}
+			if (a.Winner != nil) != tt.wantWinner {
+				t.Errorf("winner = %v, want kept %v", a.Winner, tt.wantWinner)
+			}
+			if tt.wantNext > 0 && a.NextCandidateIndex != tt.wantNext {
+				t.Errorf("NextCandidateIndex = %d, want %d", a.NextCandidateIndex, tt.wantNext)
+			}
+		})
+	}
+}
@@ -21,6 +21,7 @@ package v1alpha2
 import (
 	apiv1alpha2 "github.com/iter8-tools/iter8/pkg/analytics/api/v1alpha2"
 	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	runtime "k8s.io/apimachinery/pkg/runtime"
 )
 
@@ -40,6 +41,11 @@ func (in *Assessment) DeepCopyInto(out *Assessment) {
 		*out = new(WinnerAssessment)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.RequiredSampleSize != nil {
+		in, out := &in.RequiredSampleSize, &out.RequiredSampleSize
+		*out = new(int32)
+		**out = **in
+	}
 	return
 }
 
@@ -53,6 +59,164 @@ func (in *Assessment) DeepCopy() *Assessment {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AssessmentConfig) DeepCopyInto(out *AssessmentConfig) {
+	*out = *in
+	if in.Method != nil {
+		in, out := &in.Method, &out.Method
+		*out = new(AssessmentMethodType)
+		**out = **in
+	}
+	if in.Frequentist != nil {
+		in, out := &in.Frequentist, &out.Frequentist
+		*out = new(FrequentistConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AssessmentConfig.
+func (in *AssessmentConfig) DeepCopy() *AssessmentConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(AssessmentConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Bake) DeepCopyInto(out *Bake) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Bake.
+func (in *Bake) DeepCopy() *Bake {
+	if in == nil {
+		return nil
+	}
+	out := new(Bake)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BakeStatus) DeepCopyInto(out *BakeStatus) {
+	*out = *in
+	if in.StartTimestamp != nil {
+		in, out := &in.StartTimestamp, &out.StartTimestamp
+		*out = (*in).DeepCopy()
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BakeStatus.
+func (in *BakeStatus) DeepCopy() *BakeStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(BakeStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BlueGreen) DeepCopyInto(out *BlueGreen) {
+	*out = *in
+	if in.TestMatch != nil {
+		in, out := &in.TestMatch, &out.TestMatch
+		*out = new(Match)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Mirror != nil {
+		in, out := &in.Mirror, &out.Mirror
+		*out = new(bool)
+		**out = **in
+	}
+	if in.RollbackWindow != nil {
+		in, out := &in.RollbackWindow, &out.RollbackWindow
+		*out = new(string)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BlueGreen.
+func (in *BlueGreen) DeepCopy() *BlueGreen {
+	if in == nil {
+		return nil
+	}
+	out := new(BlueGreen)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BlueGreenStatus) DeepCopyInto(out *BlueGreenStatus) {
+	*out = *in
+	if in.SwitchTimestamp != nil {
+		in, out := &in.SwitchTimestamp, &out.SwitchTimestamp
+		*out = (*in).DeepCopy()
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BlueGreenStatus.
+func (in *BlueGreenStatus) DeepCopy() *BlueGreenStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(BlueGreenStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CandidateTemplate) DeepCopyInto(out *CandidateTemplate) {
+	*out = *in
+	if in.Labels != nil {
+		in, out := &in.Labels, &out.Labels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Container != nil {
+		in, out := &in.Container, &out.Container
+		*out = new(string)
+		**out = **in
+	}
+	if in.Image != nil {
+		in, out := &in.Image, &out.Image
+		*out = new(string)
+		**out = **in
+	}
+	if in.Env != nil {
+		in, out := &in.Env, &out.Env
+		*out = make([]v1.EnvVar, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Args != nil {
+		in, out := &in.Args, &out.Args
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CandidateTemplate.
+func (in *CandidateTemplate) DeepCopy() *CandidateTemplate {
+	if in == nil {
+		return nil
+	}
+	out := new(CandidateTemplate)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in Conditions) DeepCopyInto(out *Conditions) {
 	{
@@ -118,6 +282,16 @@ func (in *Criterion) DeepCopyInto(out *Criterion) {
 		*out = new(bool)
 		**out = **in
 	}
+	if in.Weight != nil {
+		in, out := &in.Weight, &out.Weight
+		*out = new(float32)
+		**out = **in
+	}
+	if in.Priority != nil {
+		in, out := &in.Priority, &out.Priority
+		*out = new(int32)
+		**out = **in
+	}
 	return
 }
 
@@ -144,6 +318,16 @@ func (in *Duration) DeepCopyInto(out *Duration) {
 		*out = new(int32)
 		**out = **in
 	}
+	if in.Warmup != nil {
+		in, out := &in.Warmup, &out.Warmup
+		*out = new(string)
+		**out = **in
+	}
+	if in.ProgressDeadline != nil {
+		in, out := &in.ProgressDeadline, &out.ProgressDeadline
+		*out = new(string)
+		**out = **in
+	}
 	return
 }
 
@@ -157,6 +341,78 @@ func (in *Duration) DeepCopy() *Duration {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *EarlyStop) DeepCopyInto(out *EarlyStop) {
+	*out = *in
+	if in.Policy != nil {
+		in, out := &in.Policy, &out.Policy
+		*out = new(EarlyStopPolicyType)
+		**out = **in
+	}
+	if in.Threshold != nil {
+		in, out := &in.Threshold, &out.Threshold
+		*out = new(float32)
+		**out = **in
+	}
+	if in.ConsecutiveIterations != nil {
+		in, out := &in.ConsecutiveIterations, &out.ConsecutiveIterations
+		*out = new(int32)
+		**out = **in
+	}
+	if in.Alpha != nil {
+		in, out := &in.Alpha, &out.Alpha
+		*out = new(float32)
+		**out = **in
+	}
+	if in.Beta != nil {
+		in, out := &in.Beta, &out.Beta
+		*out = new(float32)
+		**out = **in
+	}
+	if in.Metric != nil {
+		in, out := &in.Metric, &out.Metric
+		*out = new(string)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EarlyStop.
+func (in *EarlyStop) DeepCopy() *EarlyStop {
+	if in == nil {
+		return nil
+	}
+	out := new(EarlyStop)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *EarlyStopStatus) DeepCopyInto(out *EarlyStopStatus) {
+	*out = *in
+	if in.Winner != nil {
+		in, out := &in.Winner, &out.Winner
+		*out = new(string)
+		**out = **in
+	}
+	if in.RequiredSampleSize != nil {
+		in, out := &in.RequiredSampleSize, &out.RequiredSampleSize
+		*out = new(int32)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EarlyStopStatus.
+func (in *EarlyStopStatus) DeepCopy() *EarlyStopStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(EarlyStopStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Experiment) DeepCopyInto(out *Experiment) {
 	*out = *in
@@ -259,6 +515,16 @@ func (in *ExperimentSpec) DeepCopyInto(out *ExperimentSpec) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.RewardPolicy != nil {
+		in, out := &in.RewardPolicy, &out.RewardPolicy
+		*out = new(RewardPolicyType)
+		**out = **in
+	}
+	if in.Assessment != nil {
+		in, out := &in.Assessment, &out.Assessment
+		*out = new(AssessmentConfig)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.TrafficControl != nil {
 		in, out := &in.TrafficControl, &out.TrafficControl
 		*out = new(TrafficControl)
@@ -274,6 +540,36 @@ func (in *ExperimentSpec) DeepCopyInto(out *ExperimentSpec) {
 		*out = new(Duration)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.EarlyStop != nil {
+		in, out := &in.EarlyStop, &out.EarlyStop
+		*out = new(EarlyStop)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Bake != nil {
+		in, out := &in.Bake, &out.Bake
+		*out = new(Bake)
+		**out = **in
+	}
+	if in.Promotion != nil {
+		in, out := &in.Promotion, &out.Promotion
+		*out = new(Promotion)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.OnTemplateChange != nil {
+		in, out := &in.OnTemplateChange, &out.OnTemplateChange
+		*out = new(TemplateChangePolicyType)
+		**out = **in
+	}
+	if in.PodHealth != nil {
+		in, out := &in.PodHealth, &out.PodHealth
+		*out = new(PodHealth)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Scaling != nil {
+		in, out := &in.Scaling, &out.Scaling
+		*out = new(Scaling)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.Cleanup != nil {
 		in, out := &in.Cleanup, &out.Cleanup
 		*out = new(bool)
@@ -342,11 +638,30 @@ func (in *ExperimentStatus) DeepCopyInto(out *ExperimentStatus) {
 		*out = new(int32)
 		**out = **in
 	}
+	if in.SelectedBaseline != nil {
+		in, out := &in.SelectedBaseline, &out.SelectedBaseline
+		*out = new(string)
+		**out = **in
+	}
+	if in.SelectedCandidates != nil {
+		in, out := &in.SelectedCandidates, &out.SelectedCandidates
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.UnavailableSince != nil {
+		in, out := &in.UnavailableSince, &out.UnavailableSince
+		*out = (*in).DeepCopy()
+	}
 	if in.Assessment != nil {
 		in, out := &in.Assessment, &out.Assessment
 		*out = new(Assessment)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.QueuePosition != nil {
+		in, out := &in.QueuePosition, &out.QueuePosition
+		*out = new(int32)
+		**out = **in
+	}
 	if in.Message != nil {
 		in, out := &in.Message, &out.Message
 		*out = new(string)
@@ -362,6 +677,69 @@ func (in *ExperimentStatus) DeepCopyInto(out *ExperimentStatus) {
 		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
+	if in.EarlyStop != nil {
+		in, out := &in.EarlyStop, &out.EarlyStop
+		*out = new(EarlyStopStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.CurrentStep != nil {
+		in, out := &in.CurrentStep, &out.CurrentStep
+		*out = new(int32)
+		**out = **in
+	}
+	if in.StepTimestamp != nil {
+		in, out := &in.StepTimestamp, &out.StepTimestamp
+		*out = (*in).DeepCopy()
+	}
+	if in.BlueGreen != nil {
+		in, out := &in.BlueGreen, &out.BlueGreen
+		*out = new(BlueGreenStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Ramp != nil {
+		in, out := &in.Ramp, &out.Ramp
+		*out = new(RampStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Bake != nil {
+		in, out := &in.Bake, &out.Bake
+		*out = new(BakeStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Promotion != nil {
+		in, out := &in.Promotion, &out.Promotion
+		*out = new(PromotionStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Scaling != nil {
+		in, out := &in.Scaling, &out.Scaling
+		*out = new(ScalingStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Outcome != nil {
+		in, out := &in.Outcome, &out.Outcome
+		*out = new(OutcomeType)
+		**out = **in
+	}
+	if in.Segments != nil {
+		in, out := &in.Segments, &out.Segments
+		*out = make([]SegmentStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Buckets != nil {
+		in, out := &in.Buckets, &out.Buckets
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.RestartCounts != nil {
+		in, out := &in.RestartCounts, &out.RestartCounts
+		*out = make(map[string]int32, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
 	return
 }
 
@@ -375,6 +753,42 @@ func (in *ExperimentStatus) DeepCopy() *ExperimentStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *FrequentistConfig) DeepCopyInto(out *FrequentistConfig) {
+	*out = *in
+	if in.Test != nil {
+		in, out := &in.Test, &out.Test
+		*out = new(StatisticalTestType)
+		**out = **in
+	}
+	if in.Alpha != nil {
+		in, out := &in.Alpha, &out.Alpha
+		*out = new(float32)
+		**out = **in
+	}
+	if in.Power != nil {
+		in, out := &in.Power, &out.Power
+		*out = new(float32)
+		**out = **in
+	}
+	if in.MinimumDetectableEffect != nil {
+		in, out := &in.MinimumDetectableEffect, &out.MinimumDetectableEffect
+		*out = new(float32)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FrequentistConfig.
+func (in *FrequentistConfig) DeepCopy() *FrequentistConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(FrequentistConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *HTTPMatchRequest) DeepCopyInto(out *HTTPMatchRequest) {
 	*out = *in
@@ -560,10 +974,137 @@ func (in *Networking) DeepCopy() *Networking {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *RatioMetric) DeepCopyInto(out *RatioMetric) {
+func (in *PodHealth) DeepCopyInto(out *PodHealth) {
 	*out = *in
-	if in.ZeroToOne != nil {
-		in, out := &in.ZeroToOne, &out.ZeroToOne
+	if in.RestartThreshold != nil {
+		in, out := &in.RestartThreshold, &out.RestartThreshold
+		*out = new(int32)
+		**out = **in
+	}
+	if in.ReadinessTimeout != nil {
+		in, out := &in.ReadinessTimeout, &out.ReadinessTimeout
+		*out = new(string)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PodHealth.
+func (in *PodHealth) DeepCopy() *PodHealth {
+	if in == nil {
+		return nil
+	}
+	out := new(PodHealth)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Promotion) DeepCopyInto(out *Promotion) {
+	*out = *in
+	if in.CandidateAction != nil {
+		in, out := &in.CandidateAction, &out.CandidateAction
+		*out = new(CandidateActionType)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Promotion.
+func (in *Promotion) DeepCopy() *Promotion {
+	if in == nil {
+		return nil
+	}
+	out := new(Promotion)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PromotionStatus) DeepCopyInto(out *PromotionStatus) {
+	*out = *in
+	if in.StartTimestamp != nil {
+		in, out := &in.StartTimestamp, &out.StartTimestamp
+		*out = (*in).DeepCopy()
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PromotionStatus.
+func (in *PromotionStatus) DeepCopy() *PromotionStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(PromotionStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Ramp) DeepCopyInto(out *Ramp) {
+	*out = *in
+	if in.Steps != nil {
+		in, out := &in.Steps, &out.Steps
+		*out = new(int32)
+		**out = **in
+	}
+	if in.Duration != nil {
+		in, out := &in.Duration, &out.Duration
+		*out = new(string)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Ramp.
+func (in *Ramp) DeepCopy() *Ramp {
+	if in == nil {
+		return nil
+	}
+	out := new(Ramp)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RampStatus) DeepCopyInto(out *RampStatus) {
+	*out = *in
+	if in.StartSplit != nil {
+		in, out := &in.StartSplit, &out.StartSplit
+		*out = make(map[string]int32, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.LastSafeSplit != nil {
+		in, out := &in.LastSafeSplit, &out.LastSafeSplit
+		*out = make(map[string]int32, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.StepTimestamp != nil {
+		in, out := &in.StepTimestamp, &out.StepTimestamp
+		*out = (*in).DeepCopy()
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RampStatus.
+func (in *RampStatus) DeepCopy() *RampStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(RampStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RatioMetric) DeepCopyInto(out *RatioMetric) {
+	*out = *in
+	if in.ZeroToOne != nil {
+		in, out := &in.ZeroToOne, &out.ZeroToOne
 		*out = new(bool)
 		**out = **in
 	}
@@ -585,6 +1126,120 @@ func (in *RatioMetric) DeepCopy() *RatioMetric {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RewardAssessment) DeepCopyInto(out *RewardAssessment) {
+	*out = *in
+	if in.Value != nil {
+		in, out := &in.Value, &out.Value
+		*out = new(float32)
+		**out = **in
+	}
+	if in.Improvement != nil {
+		in, out := &in.Improvement, &out.Improvement
+		*out = new(float32)
+		**out = **in
+	}
+	if in.Priority != nil {
+		in, out := &in.Priority, &out.Priority
+		*out = new(int32)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RewardAssessment.
+func (in *RewardAssessment) DeepCopy() *RewardAssessment {
+	if in == nil {
+		return nil
+	}
+	out := new(RewardAssessment)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Scaling) DeepCopyInto(out *Scaling) {
+	*out = *in
+	if in.MinReplicas != nil {
+		in, out := &in.MinReplicas, &out.MinReplicas
+		*out = new(int32)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Scaling.
+func (in *Scaling) DeepCopy() *Scaling {
+	if in == nil {
+		return nil
+	}
+	out := new(Scaling)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ScalingStatus) DeepCopyInto(out *ScalingStatus) {
+	*out = *in
+	if in.OriginalMinReplicas != nil {
+		in, out := &in.OriginalMinReplicas, &out.OriginalMinReplicas
+		*out = make(map[string]int32, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ScalingStatus.
+func (in *ScalingStatus) DeepCopy() *ScalingStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ScalingStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Segment) DeepCopyInto(out *Segment) {
+	*out = *in
+	in.Match.DeepCopyInto(&out.Match)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Segment.
+func (in *Segment) DeepCopy() *Segment {
+	if in == nil {
+		return nil
+	}
+	out := new(Segment)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SegmentStatus) DeepCopyInto(out *SegmentStatus) {
+	*out = *in
+	in.Assessment.DeepCopyInto(&out.Assessment)
+	if in.AnalysisState != nil {
+		in, out := &in.AnalysisState, &out.AnalysisState
+		*out = new(runtime.RawExtension)
+		(*in).DeepCopyInto(*out)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SegmentStatus.
+func (in *SegmentStatus) DeepCopy() *SegmentStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(SegmentStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Service) DeepCopyInto(out *Service) {
 	*out = *in
@@ -593,16 +1248,50 @@ func (in *Service) DeepCopyInto(out *Service) {
 		*out = new(v1.ObjectReference)
 		**out = **in
 	}
+	if in.PodTemplateLabelsPath != nil {
+		in, out := &in.PodTemplateLabelsPath, &out.PodTemplateLabelsPath
+		*out = new(string)
+		**out = **in
+	}
+	if in.BaselineSelector != nil {
+		in, out := &in.BaselineSelector, &out.BaselineSelector
+		*out = new(metav1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.Candidates != nil {
 		in, out := &in.Candidates, &out.Candidates
 		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
+	if in.CandidateTemplates != nil {
+		in, out := &in.CandidateTemplates, &out.CandidateTemplates
+		*out = make([]CandidateTemplate, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.CandidateSelector != nil {
+		in, out := &in.CandidateSelector, &out.CandidateSelector
+		*out = new(metav1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.MaxCandidates != nil {
+		in, out := &in.MaxCandidates, &out.MaxCandidates
+		*out = new(int32)
+		**out = **in
+	}
 	if in.Port != nil {
 		in, out := &in.Port, &out.Port
 		*out = new(int32)
 		**out = **in
 	}
+	if in.Ports != nil {
+		in, out := &in.Ports, &out.Ports
+		*out = make([]ServicePort, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 	return
 }
 
@@ -616,6 +1305,58 @@ func (in *Service) DeepCopy() *Service {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ServicePort) DeepCopyInto(out *ServicePort) {
+	*out = *in
+	if in.Protocol != nil {
+		in, out := &in.Protocol, &out.Protocol
+		*out = new(PortProtocolType)
+		**out = **in
+	}
+	if in.SNIHosts != nil {
+		in, out := &in.SNIHosts, &out.SNIHosts
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ServicePort.
+func (in *ServicePort) DeepCopy() *ServicePort {
+	if in == nil {
+		return nil
+	}
+	out := new(ServicePort)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Sticky) DeepCopyInto(out *Sticky) {
+	*out = *in
+	if in.Header != nil {
+		in, out := &in.Header, &out.Header
+		*out = new(string)
+		**out = **in
+	}
+	if in.Cookie != nil {
+		in, out := &in.Cookie, &out.Cookie
+		*out = new(string)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Sticky.
+func (in *Sticky) DeepCopy() *Sticky {
+	if in == nil {
+		return nil
+	}
+	out := new(Sticky)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *StringMatch) DeepCopyInto(out *StringMatch) {
 	*out = *in
@@ -676,16 +1417,45 @@ func (in *TrafficControl) DeepCopyInto(out *TrafficControl) {
 		*out = new(StrategyType)
 		**out = **in
 	}
+	if in.Steps != nil {
+		in, out := &in.Steps, &out.Steps
+		*out = make([]TrafficStep, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 	if in.OnTermination != nil {
 		in, out := &in.OnTermination, &out.OnTermination
 		*out = new(OnTerminationType)
 		**out = **in
 	}
+	if in.Ramp != nil {
+		in, out := &in.Ramp, &out.Ramp
+		*out = new(Ramp)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.Match != nil {
 		in, out := &in.Match, &out.Match
 		*out = new(Match)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.Disjoint != nil {
+		in, out := &in.Disjoint, &out.Disjoint
+		*out = new(bool)
+		**out = **in
+	}
+	if in.Segments != nil {
+		in, out := &in.Segments, &out.Segments
+		*out = make([]Segment, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Sticky != nil {
+		in, out := &in.Sticky, &out.Sticky
+		*out = new(Sticky)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.Percentage != nil {
 		in, out := &in.Percentage, &out.Percentage
 		*out = new(int32)
@@ -696,6 +1466,21 @@ func (in *TrafficControl) DeepCopyInto(out *TrafficControl) {
 		*out = new(int32)
 		**out = **in
 	}
+	if in.BlueGreen != nil {
+		in, out := &in.BlueGreen, &out.BlueGreen
+		*out = new(BlueGreen)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.MinRequestCount != nil {
+		in, out := &in.MinRequestCount, &out.MinRequestCount
+		*out = new(int32)
+		**out = **in
+	}
+	if in.MinObservationTime != nil {
+		in, out := &in.MinObservationTime, &out.MinObservationTime
+		*out = new(string)
+		**out = **in
+	}
 	if in.RouterID != nil {
 		in, out := &in.RouterID, &out.RouterID
 		*out = new(string)
@@ -714,10 +1499,52 @@ func (in *TrafficControl) DeepCopy() *TrafficControl {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TrafficStep) DeepCopyInto(out *TrafficStep) {
+	*out = *in
+	if in.Hold != nil {
+		in, out := &in.Hold, &out.Hold
+		*out = new(string)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TrafficStep.
+func (in *TrafficStep) DeepCopy() *TrafficStep {
+	if in == nil {
+		return nil
+	}
+	out := new(TrafficStep)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *VersionAssessment) DeepCopyInto(out *VersionAssessment) {
 	*out = *in
+	if in.Index != nil {
+		in, out := &in.Index, &out.Index
+		*out = new(int32)
+		**out = **in
+	}
 	in.VersionAssessment.DeepCopyInto(&out.VersionAssessment)
+	if in.TrafficStartTimestamp != nil {
+		in, out := &in.TrafficStartTimestamp, &out.TrafficStartTimestamp
+		*out = (*in).DeepCopy()
+	}
+	if in.Objective != nil {
+		in, out := &in.Objective, &out.Objective
+		*out = new(float32)
+		**out = **in
+	}
+	if in.RewardAssessments != nil {
+		in, out := &in.RewardAssessments, &out.RewardAssessments
+		*out = make([]RewardAssessment, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 	return
 }
 
@@ -744,6 +1571,11 @@ func (in *WinnerAssessment) DeepCopyInto(out *WinnerAssessment) {
 		*out = new(apiv1alpha2.WinnerAssessment)
 		**out = **in
 	}
+	if in.PValue != nil {
+		in, out := &in.PValue, &out.PValue
+		*out = new(float32)
+		**out = **in
+	}
 	return
 }
 
@@ -21,6 +21,9 @@ import (
 	"sync"
 
 	"github.com/go-logr/logr"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
 
 	iter8v1alpha2 "github.com/iter8-tools/iter8/pkg/apis/iter8/v1alpha2"
 )
@@ -31,8 +34,15 @@ type Interface interface {
 	DeploymentToExperiment(name, namespace string) (experiment, experimentNamespace string, exist bool)
 	// Given name and namespace of the target service, return the experiment key
 	ServiceToExperiment(name, namespace string) (experiment, experimentNamespace string, exist bool)
+	// Given namespace and labels of a deployment, return the key of experiment selecting it by labels
+	SelectorToExperiment(namespace string, labels map[string]string) (experiment, experimentNamespace string, exist bool)
+	// RegisterExperiment returns QueuedError if targets of a new experiment are involved in other experiments
 	RegisterExperiment(context context.Context, instance *iter8v1alpha2.Experiment) (context.Context, error)
 	RemoveExperiment(instance *iter8v1alpha2.Experiment)
+	// Return experiments waiting for their targets, in order of creation
+	QueuedExperiments() []types.NamespacedName
+	// Restore registers experiments holding targets and queues waiting ones after the controller restarts
+	Restore(context context.Context, instances []iter8v1alpha2.Experiment)
 
 	MarkDeploymentDetected(name, namespace string) bool
 	MarkServiceDetected(name, namespace string) bool
@@ -40,6 +50,8 @@ type Interface interface {
 	MarkDeploymentDeleted(name, namespace string) bool
 	MarkServiceDeleted(name, namespace string) bool
 
+	MarkDeploymentUpdated(name, namespace string) bool
+
 	Inspect()
 }
 
@@ -54,12 +66,31 @@ type Impl struct {
 	// an ExperimentAbstract store with experimentName.experimentNamespace as key for access
 	experimentAbstractStore map[string]*experiment
 
-	// a lookup map from target to experiment
+	// a lookup map from target workload (deployment or statefulset) to experiment
 	// targetName.targetNamespace -> experimentName.experimentNamespace
 	deployment2Experiment map[string]string
 
 	// a lookup map from target service to experiment
 	service2Experiment map[string]string
+
+	// lookup maps from targets shared by experiments in disjoint traffic segments
+	// to the experiments other than the one in deployment2Experiment or service2Experiment
+	// targetName.targetNamespace -> []experimentName.experimentNamespace
+	sharedDeployments map[string][]string
+	sharedServices    map[string][]string
+
+	// label selectors of targets per experiment
+	// experimentName.experimentNamespace -> selectors
+	selector2Experiment map[string]*targetSelector
+
+	// experiments waiting for targets involved in other experiments, in order of creation
+	queue []*queuedExperiment
+}
+
+// targetSelector includes label selectors of targets in a namespace
+type targetSelector struct {
+	namespace string
+	selectors []labels.Selector
 }
 
 // New returns a new iter8cache implementation
@@ -68,6 +99,9 @@ func New(logger logr.Logger) Interface {
 		experimentAbstractStore: make(map[string]*experiment),
 		deployment2Experiment:   make(map[string]string),
 		service2Experiment:      make(map[string]string),
+		sharedDeployments:       make(map[string][]string),
+		sharedServices:          make(map[string][]string),
+		selector2Experiment:     make(map[string]*targetSelector),
 		logger:                  logger,
 	}
 }
@@ -79,25 +113,37 @@ func (c *Impl) RegisterExperiment(ctx context.Context, instance *iter8v1alpha2.E
 
 	eakey := experimentKey(instance)
 	if _, ok := c.experimentAbstractStore[eakey]; !ok {
+		if err := c.checkQueue(instance); err != nil {
+			return ctx, c.enqueue(instance, err)
+		}
+
 		serviceKeys, err := c.checkAndGetServices(instance)
 		if err != nil {
-			return ctx, err
+			return ctx, c.enqueue(instance, err)
 		}
 
 		deploymentKeys, err := c.checkAndGetDeployments(instance)
 		if err != nil {
-			return ctx, err
+			return ctx, c.enqueue(instance, err)
 		}
+		c.dequeue(eakey)
 
 		c.experimentAbstractStore[eakey] = newExperiment(serviceKeys, deploymentKeys)
+		c.experimentAbstractStore[eakey].disjoint = instance.Spec.GetDisjoint()
 
 		for _, svc := range serviceKeys {
-			c.service2Experiment[svc] = eakey
+			claim(c.service2Experiment, c.sharedServices, svc, eakey)
 		}
 
 		for _, dep := range deploymentKeys {
-			c.deployment2Experiment[dep] = eakey
+			claim(c.deployment2Experiment, c.sharedDeployments, dep, eakey)
 		}
+
+		if selector := getSelector(instance); selector != nil {
+			c.selector2Experiment[eakey] = selector
+		}
+	} else if err := c.addDeployments(instance, eakey); err != nil {
+		return ctx, err
 	}
 
 	ea := c.experimentAbstractStore[eakey]
@@ -112,6 +158,8 @@ func (c *Impl) RegisterExperiment(ctx context.Context, instance *iter8v1alpha2.E
 func (c *Impl) Inspect() {
 	c.logger.Info("iter8Adapter", "deployment2Experiment", c.deployment2Experiment)
 	c.logger.Info("iter8Adapter", "service2Experiment", c.service2Experiment)
+	c.logger.Info("iter8Adapter", "sharedDeployments", c.sharedDeployments)
+	c.logger.Info("iter8Adapter", "sharedServices", c.sharedServices)
 }
 
 // DeploymentToExperiment returns the experiment key given name and namespace of target deployment
@@ -140,6 +188,9 @@ func (c *Impl) MarkDeploymentDetected(targetName, targetNamespace string) bool {
 	}
 
 	c.experimentAbstractStore[eaKey].MarkTargetDetected(targetName, "Deployment")
+	for _, key := range c.sharedDeployments[tKey] {
+		c.experimentAbstractStore[key].MarkTargetDetected(targetName, "Deployment")
+	}
 
 	return true
 }
@@ -156,10 +207,52 @@ func (c *Impl) MarkDeploymentDeleted(targetName, targetNamespace string) bool {
 	}
 
 	c.experimentAbstractStore[eaKey].MarkTargetDeleted(targetName, "Deployment")
+	for _, key := range c.sharedDeployments[tKey] {
+		c.experimentAbstractStore[key].MarkTargetDeleted(targetName, "Deployment")
+	}
 
 	return true
 }
 
+// MarkDeploymentUpdated marks the event that pod template of a target deployment is changed
+func (c *Impl) MarkDeploymentUpdated(targetName, targetNamespace string) bool {
+	c.m.Lock()
+	defer c.m.Unlock()
+
+	tKey := targetKey(targetName, targetNamespace)
+	eaKey, ok := c.deployment2Experiment[tKey]
+	if !ok {
+		return false
+	}
+
+	c.experimentAbstractStore[eaKey].MarkTargetUpdated(targetName, "Deployment")
+	for _, key := range c.sharedDeployments[tKey] {
+		c.experimentAbstractStore[key].MarkTargetUpdated(targetName, "Deployment")
+	}
+
+	return true
+}
+
+// SelectorToExperiment returns the experiment key given namespace and labels of a deployment selected by labels
+func (c *Impl) SelectorToExperiment(targetNamespace string, targetLabels map[string]string) (string, string, bool) {
+	c.m.Lock()
+	defer c.m.Unlock()
+
+	for eaKey, ts := range c.selector2Experiment {
+		if ts.namespace != targetNamespace {
+			continue
+		}
+		for _, selector := range ts.selectors {
+			if selector.Matches(labels.Set(targetLabels)) {
+				namespace, name := resolveExperimentKey(eaKey)
+				return name, namespace, true
+			}
+		}
+	}
+
+	return "", "", false
+}
+
 // ServiceToExperiment returns the experiment key given name and namespace of target service
 func (c *Impl) ServiceToExperiment(targetName, targetNamespace string) (string, string, bool) {
 	c.m.Lock()
@@ -187,6 +280,9 @@ func (c *Impl) MarkServiceDetected(targetName, targetNamespace string) bool {
 	}
 
 	c.experimentAbstractStore[eaKey].MarkTargetDetected(targetName, "Service")
+	for _, key := range c.sharedServices[tKey] {
+		c.experimentAbstractStore[key].MarkTargetDetected(targetName, "Service")
+	}
 
 	return true
 }
@@ -203,6 +299,9 @@ func (c *Impl) MarkServiceDeleted(targetName, targetNamespace string) bool {
 	}
 
 	c.experimentAbstractStore[eaKey].MarkTargetDeleted(targetName, "Service")
+	for _, key := range c.sharedServices[tKey] {
+		c.experimentAbstractStore[key].MarkTargetDeleted(targetName, "Service")
+	}
 
 	return true
 }
@@ -213,44 +312,92 @@ func (c *Impl) RemoveExperiment(instance *iter8v1alpha2.Experiment) {
 	defer c.m.Unlock()
 
 	eakey := experimentKey(instance)
+	c.dequeue(eakey)
 	ea, ok := c.experimentAbstractStore[eakey]
 	if !ok {
 		return
 	}
 
 	for _, key := range ea.serviceKeys {
-		delete(c.service2Experiment, key)
+		release(c.service2Experiment, c.sharedServices, key, eakey)
 	}
 
 	for _, key := range ea.deploymentKeys {
-		delete(c.deployment2Experiment, key)
+		release(c.deployment2Experiment, c.sharedDeployments, key, eakey)
 	}
+	delete(c.selector2Experiment, eakey)
 	delete(c.experimentAbstractStore, eakey)
 }
 
+// addDeployments adds lookup keys of deployments which become targets after the experiment is registered,
+// e.g. candidates added to spec or selected by labels
+func (c *Impl) addDeployments(instance *iter8v1alpha2.Experiment, eakey string) error {
+	if !workloadKind(instance.Spec.Service.Kind) {
+		return nil
+	}
+	ea := c.experimentAbstractStore[eakey]
+	names := append([]string{instance.GetBaseline()}, instance.GetCandidates()...)
+	for _, name := range names {
+		if name == "" {
+			continue
+		}
+		key := versionKey(instance, name)
+		if owner, ok := c.deployment2Experiment[key]; ok {
+			if owner == eakey || contains(c.sharedDeployments[key], eakey) {
+				continue
+			}
+			if !c.sharable(instance, owner, c.sharedDeployments[key]) {
+				return fmt.Errorf("Deployment %s is being involved in other experiment", key)
+			}
+		}
+		claim(c.deployment2Experiment, c.sharedDeployments, key, eakey)
+		ea.deploymentKeys = append(ea.deploymentKeys, key)
+	}
+	return nil
+}
+
+// getSelector returns label selectors of targets of the experiment, nil if no selector is specified
+func getSelector(instance *iter8v1alpha2.Experiment) *targetSelector {
+	service := instance.Spec.Service
+	out := &targetSelector{namespace: instance.ServiceNamespace()}
+	for _, labelSelector := range []*metav1.LabelSelector{service.BaselineSelector, service.CandidateSelector} {
+		if labelSelector == nil {
+			continue
+		}
+		if selector, err := metav1.LabelSelectorAsSelector(labelSelector); err == nil {
+			out.selectors = append(out.selectors, selector)
+		}
+	}
+	if len(out.selectors) == 0 {
+		return nil
+	}
+	return out
+}
+
 func (c *Impl) checkAndGetServices(instance *iter8v1alpha2.Experiment) ([]string, error) {
 	out := []string{}
 	service := instance.Spec.Service
 	ns := instance.ServiceNamespace()
 
-	if service.Name != "" {
+	// logical host of external services is not backed by an internal service
+	if service.Name != "" && service.Kind != "ServiceEntry" {
 		key := targetKey(service.Name, ns)
-		if _, ok := c.service2Experiment[key]; ok {
+		if owner, ok := c.service2Experiment[key]; ok && !c.sharable(instance, owner, c.sharedServices[key]) {
 			return nil, fmt.Errorf("Service %s is being involved in other experiment", key)
 		}
 		out = append(out, key)
 	}
 
 	if service.Kind == "Service" {
-		baselineKey := targetKey(service.Baseline, ns)
-		if _, ok := c.service2Experiment[baselineKey]; ok {
+		baselineKey := versionKey(instance, instance.GetBaseline())
+		if owner, ok := c.service2Experiment[baselineKey]; ok && !c.sharable(instance, owner, c.sharedServices[baselineKey]) {
 			return nil, fmt.Errorf("Baseline %s is being involved in other experiment", baselineKey)
 		}
 		out = append(out, baselineKey)
 
-		for _, candidate := range service.Candidates {
-			candidateKey := targetKey(candidate, ns)
-			if _, ok := c.service2Experiment[candidateKey]; ok {
+		for _, candidate := range instance.GetCandidates() {
+			candidateKey := versionKey(instance, candidate)
+			if owner, ok := c.service2Experiment[candidateKey]; ok && !c.sharable(instance, owner, c.sharedServices[candidateKey]) {
 				return nil, fmt.Errorf("Candidate %s is being involved in other experiment", candidateKey)
 			}
 			out = append(out, candidateKey)
@@ -261,20 +408,21 @@ func (c *Impl) checkAndGetServices(instance *iter8v1alpha2.Experiment) ([]string
 }
 
 func (c *Impl) checkAndGetDeployments(instance *iter8v1alpha2.Experiment) ([]string, error) {
-	service := instance.Spec.Service
-	if service.Kind == "Deployment" || service.Kind == "" {
+	if workloadKind(instance.Spec.Service.Kind) {
 		out := []string{}
-		ns := instance.ServiceNamespace()
 
-		baselineKey := targetKey(service.Baseline, ns)
-		if _, ok := c.deployment2Experiment[baselineKey]; ok {
-			return nil, fmt.Errorf("Baseline %s is being involved in other experiment", baselineKey)
+		// baseline may not be selected yet
+		if instance.GetBaseline() != "" {
+			baselineKey := versionKey(instance, instance.GetBaseline())
+			if owner, ok := c.deployment2Experiment[baselineKey]; ok && !c.sharable(instance, owner, c.sharedDeployments[baselineKey]) {
+				return nil, fmt.Errorf("Baseline %s is being involved in other experiment", baselineKey)
+			}
+			out = append(out, baselineKey)
 		}
-		out = append(out, baselineKey)
 
-		for _, candidate := range service.Candidates {
-			candidateKey := targetKey(candidate, ns)
-			if _, ok := c.service2Experiment[candidateKey]; ok {
+		for _, candidate := range instance.GetCandidates() {
+			candidateKey := versionKey(instance, candidate)
+			if owner, ok := c.deployment2Experiment[candidateKey]; ok && !c.sharable(instance, owner, c.sharedDeployments[candidateKey]) {
 				return nil, fmt.Errorf("Candidate %s is being involved in other experiment", candidateKey)
 			}
 			out = append(out, candidateKey)
@@ -283,3 +431,71 @@ func (c *Impl) checkAndGetDeployments(instance *iter8v1alpha2.Experiment) ([]str
 	}
 	return nil, nil
 }
+
+// sharable tells whether a target used by the owner and sharing experiments can also be used by the instance
+// Targets are only shared among experiments in disjoint traffic segments with distinct names,
+// since names of experiments qualify their subsets in the shared routing rules
+func (c *Impl) sharable(instance *iter8v1alpha2.Experiment, owner string, shared []string) bool {
+	if !instance.Spec.GetDisjoint() {
+		return false
+	}
+	for _, key := range append([]string{owner}, shared...) {
+		if ea, ok := c.experimentAbstractStore[key]; !ok || !ea.disjoint {
+			return false
+		}
+		if _, name := resolveExperimentKey(key); name == instance.Name {
+			return false
+		}
+	}
+	return true
+}
+
+// claim records the experiment as a user of the target in lookup maps
+func claim(owners map[string]string, shared map[string][]string, key, eakey string) {
+	if owner, ok := owners[key]; ok && owner != eakey {
+		shared[key] = append(shared[key], eakey)
+		return
+	}
+	owners[key] = eakey
+}
+
+// release removes the experiment from users of the target in lookup maps
+// The first sharing experiment, if any, becomes the owner of the target
+func release(owners map[string]string, shared map[string][]string, key, eakey string) {
+	others := make([]string, 0)
+	for _, other := range shared[key] {
+		if other != eakey {
+			others = append(others, other)
+		}
+	}
+
+	if owners[key] == eakey {
+		if len(others) == 0 {
+			delete(owners, key)
+			delete(shared, key)
+			return
+		}
+		owners[key] = others[0]
+		others = others[1:]
+	}
+
+	if len(others) == 0 {
+		delete(shared, key)
+	} else {
+		shared[key] = others
+	}
+}
+
+func contains(keys []string, key string) bool {
+	for _, k := range keys {
+		if k == key {
+			return true
+		}
+	}
+	return false
+}
+
+// workloadKind tells whether targets of the kind are watched as deployments or statefulsets
+func workloadKind(kind string) bool {
+	return kind == "Deployment" || kind == "StatefulSet" || kind == ""
+}
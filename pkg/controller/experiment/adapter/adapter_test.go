@@ -0,0 +1,133 @@
+/*
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package adapter
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	iter8v1alpha2 "github.com/iter8-tools/iter8/pkg/apis/iter8/v1alpha2"
+)
+
+func TestClaimRelease(t *testing.T) {
+	const key = "reviews.bookinfo"
+
+	tests := []struct {
+		name       string
+		claims     []string
+		releases   []string
+		wantOwner  string
+		wantShared []string
+	}{
+		{name: "single owner", claims: []string{"a"}, wantOwner: "a"},
+		{name: "claimed twice by owner", claims: []string{"a", "a"}, wantOwner: "a"},
+		{name: "shared", claims: []string{"a", "b", "c"}, wantOwner: "a", wantShared: []string{"b", "c"}},
+		{name: "owner released", claims: []string{"a", "b", "c"}, releases: []string{"a"},
+			wantOwner: "b", wantShared: []string{"c"}},
+		{name: "sharing experiment released", claims: []string{"a", "b", "c"}, releases: []string{"b"},
+			wantOwner: "a", wantShared: []string{"c"}},
+		{name: "all released", claims: []string{"a", "b"}, releases: []string{"a", "b"}},
+		{name: "unknown released", claims: []string{"a"}, releases: []string{"x"}, wantOwner: "a"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			owners := make(map[string]string)
+			shared := make(map[string][]string)
+			for _, eakey := range tt.claims {
+				claim(owners, shared, key, eakey)
+			}
+			for _, eakey := range tt.releases {
+				release(owners, shared, key, eakey)
+			}
+
+			if owner, ok := owners[key]; owner != tt.wantOwner || ok != (tt.wantOwner != "") {
+				t.Errorf("owner = %q, want %q", owner, tt.wantOwner)
+			}
+			if got, ok := shared[key]; !reflect.DeepEqual(got, tt.wantShared) || ok != (len(tt.wantShared) > 0) {
+				t.Errorf("shared = %v, want %v", got, tt.wantShared)
+			}
+		})
+	}
+}
+
+func TestDisjointSharing(t *testing.T) {
+	disjointExperiment := func(name string, disjoint bool, baseline string, candidates ...string) *iter8v1alpha2.Experiment {
+		instance := queueExperiment(name, 0, baseline, candidates...)
+		// experiments with other baselines are on service ratings, so that only candidates are shared
+		if baseline != "reviews-v1" {
+			instance.Spec.Service.Name = "ratings"
+		}
+		instance.Spec.TrafficControl = &iter8v1alpha2.TrafficControl{Disjoint: &disjoint}
+		return instance
+	}
+
+	tests := []struct {
+		name       string
+		first      *iter8v1alpha2.Experiment
+		second     *iter8v1alpha2.Experiment
+		wantShared bool
+	}{
+		{name: "disjoint experiments share baseline",
+			first:  disjointExperiment("a", true, "reviews-v1", "reviews-v2"),
+			second: disjointExperiment("b", true, "reviews-v1", "reviews-v3"), wantShared: true},
+		{name: "overlapping experiment waits for baseline",
+			first:  disjointExperiment("a", true, "reviews-v1", "reviews-v2"),
+			second: disjointExperiment("b", false, "reviews-v1", "reviews-v3")},
+		{name: "disjoint experiments share candidate",
+			first:  disjointExperiment("a", true, "reviews-v1", "reviews-v3"),
+			second: disjointExperiment("b", true, "ratings-v1", "reviews-v3"), wantShared: true},
+		{name: "overlapping experiment waits for candidate",
+			first:  disjointExperiment("a", true, "reviews-v1", "reviews-v3"),
+			second: disjointExperiment("b", false, "ratings-v1", "reviews-v3")},
+		{name: "disjoint experiment waits for candidate of overlapping one",
+			first:  disjointExperiment("a", false, "reviews-v1", "reviews-v3"),
+			second: disjointExperiment("b", true, "ratings-v1", "reviews-v3")},
+		{name: "experiments of same name are not shared",
+			first: disjointExperiment("a", true, "reviews-v1", "reviews-v3"),
+			second: func() *iter8v1alpha2.Experiment {
+				instance := disjointExperiment("a", true, "reviews-v1", "reviews-v3")
+				instance.Namespace = "preview"
+				instance.Spec.Service.Namespace = "bookinfo"
+				return instance
+			}()},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := New(log.Log).(*Impl)
+			if _, err := c.RegisterExperiment(context.Background(), tt.first); err != nil {
+				t.Fatalf("RegisterExperiment() error = %v", err)
+			}
+			_, err := c.RegisterExperiment(context.Background(), tt.second)
+			if (err == nil) != tt.wantShared {
+				t.Errorf("RegisterExperiment() error = %v, wantShared %v", err, tt.wantShared)
+			}
+			if !tt.wantShared {
+				return
+			}
+
+			// the shared candidate stays with the other experiment once one of them is removed
+			c.RemoveExperiment(tt.first)
+			if name, _, ok := c.DeploymentToExperiment("reviews-v3", "bookinfo"); !ok || name != tt.second.Name {
+				t.Errorf("candidate taken by %q, want %s", name, tt.second.Name)
+			}
+		})
+	}
+}
@@ -23,6 +23,7 @@ const (
 
 	targetActionDetected = targetAction("detected")
 	targetActionDeleted  = targetAction("deleted")
+	targetActionUpdated  = targetAction("updated")
 )
 
 // Catcher defines functions can be invoked by Adapter
@@ -30,12 +31,14 @@ const (
 type Catcher interface {
 	MarkTargetDetected(name string, kind string)
 	MarkTargetDeleted(name string, kind string)
+	MarkTargetUpdated(name string, kind string)
 }
 
 // Action specifies desired actions to be performed by controller to the experiment
 type Action interface {
 	Refresh() bool
 	Resume() bool
+	Updated() bool
 }
 
 var _ Catcher = &experiment{}
@@ -46,6 +49,8 @@ type experiment struct {
 	serviceKeys    []string
 	deploymentKeys []string
 	targetAction   targetAction
+	// whether the experiment runs in a disjoint traffic segment, so that its targets can be shared
+	disjoint bool
 }
 
 // NewExperiment returns an Experiment instance used in controlelr adapter
@@ -66,6 +71,11 @@ func (e *experiment) Resume() bool {
 	return e.targetAction == targetActionDetected
 }
 
+// Updated indicates whether pod template of some target has been changed
+func (e *experiment) Updated() bool {
+	return e.targetAction == targetActionUpdated
+}
+
 func (e *experiment) clearAction() {
 	e.targetAction = ""
 }
@@ -80,6 +90,14 @@ func (e *experiment) MarkTargetDeleted(name string, kind string) {
 	e.targetAction = targetActionDeleted
 }
 
+// MarkTargetUpdated captures a change of pod template of a target
+// A pending detection or deletion takes precedence, since it refreshes the whole experiment
+func (e *experiment) MarkTargetUpdated(name string, kind string) {
+	if e.targetAction == "" {
+		e.targetAction = targetActionUpdated
+	}
+}
+
 // GetAction returns the action indicator of the experiment
 func (e *experiment) GetAction() Action {
 	out := &experiment{}
@@ -0,0 +1,59 @@
+/*
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package adapter
+
+import "testing"
+
+func TestPendingAction(t *testing.T) {
+	tests := []struct {
+		name    string
+		marks   []targetAction
+		refresh bool
+		resume  bool
+		updated bool
+	}{
+		{name: "none"},
+		{name: "updated", marks: []targetAction{targetActionUpdated}, updated: true},
+		{name: "detected then updated", marks: []targetAction{targetActionDetected, targetActionUpdated}, resume: true},
+		{name: "deleted then updated", marks: []targetAction{targetActionDeleted, targetActionUpdated}, refresh: true},
+		{name: "updated then deleted", marks: []targetAction{targetActionUpdated, targetActionDeleted}, refresh: true},
+		{name: "deleted then detected", marks: []targetAction{targetActionDeleted, targetActionDetected}, resume: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := newExperiment(nil, nil)
+			for _, m := range tt.marks {
+				switch m {
+				case targetActionDetected:
+					e.MarkTargetDetected("reviews-v2", "Deployment")
+				case targetActionDeleted:
+					e.MarkTargetDeleted("reviews-v2", "Deployment")
+				case targetActionUpdated:
+					e.MarkTargetUpdated("reviews-v2", "Deployment")
+				}
+			}
+			action := e.GetAction()
+			if action.Refresh() != tt.refresh || action.Resume() != tt.resume || action.Updated() != tt.updated {
+				t.Errorf("action = (refresh %v, resume %v, updated %v), want (%v, %v, %v)",
+					action.Refresh(), action.Resume(), action.Updated(), tt.refresh, tt.resume, tt.updated)
+			}
+			if e.GetAction().Updated() || e.GetAction().Refresh() || e.GetAction().Resume() {
+				t.Errorf("pending action not cleared")
+			}
+		})
+	}
+}
@@ -33,6 +33,12 @@ func targetKey(name, namespace string) string {
 	return namespace + keySeparator + name
 }
 
+// versionKey returns key of the target object of a version, which may be in a namespace other than that of service
+func versionKey(instance *iter8v1alpha2.Experiment, version string) string {
+	nn := instance.VersionNamespacedName(version)
+	return targetKey(nn.Name, nn.Namespace)
+}
+
 // return namespace, name of experiment
 func resolveExperimentKey(val string) (string, string) {
 	out := strings.Split(val, keySeparator)
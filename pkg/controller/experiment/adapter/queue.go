@@ -0,0 +1,169 @@
+/*
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package adapter
+
+// This file contains functions used for queueing experiments whose targets are involved in other experiments
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	iter8v1alpha2 "github.com/iter8-tools/iter8/pkg/apis/iter8/v1alpha2"
+)
+
+// QueuedError is returned when the experiment is queued since its targets are involved in other experiments
+type QueuedError struct {
+	// Position of the experiment in the queue, starting from 1
+	Position int32
+	err      error
+}
+
+func (e *QueuedError) Error() string {
+	return e.err.Error()
+}
+
+// queuedExperiment includes keys of an experiment waiting in the queue, keys of its targets and its creation time
+type queuedExperiment struct {
+	key     string
+	targets map[string]bool
+	created metav1.Time
+}
+
+// QueuedExperiments returns experiments waiting in the queue, in order of creation
+func (c *Impl) QueuedExperiments() []types.NamespacedName {
+	c.m.RLock()
+	defer c.m.RUnlock()
+
+	out := make([]types.NamespacedName, 0, len(c.queue))
+	for _, qe := range c.queue {
+		namespace, name := resolveExperimentKey(qe.key)
+		out = append(out, types.NamespacedName{Name: name, Namespace: namespace})
+	}
+	return out
+}
+
+// checkQueue returns error if targets of the experiment are waited for by experiments queued ahead of it
+func (c *Impl) checkQueue(instance *iter8v1alpha2.Experiment) error {
+	eakey := experimentKey(instance)
+	for _, qe := range c.queue {
+		if qe.key == eakey {
+			break
+		}
+		for _, key := range getTargetKeys(instance) {
+			if qe.targets[key] {
+				return fmt.Errorf("Target %s is waited for by experiment %s queued ahead", key, qe.key)
+			}
+		}
+	}
+	return nil
+}
+
+// enqueue inserts the experiment into the queue by creation time if absent, and returns its position in the queue
+func (c *Impl) enqueue(instance *iter8v1alpha2.Experiment, err error) *QueuedError {
+	eakey := experimentKey(instance)
+	for i, qe := range c.queue {
+		if qe.key == eakey {
+			return &QueuedError{Position: int32(i + 1), err: err}
+		}
+	}
+
+	qe := &queuedExperiment{
+		key:     eakey,
+		targets: make(map[string]bool),
+		created: instance.CreationTimestamp,
+	}
+	for _, key := range getTargetKeys(instance) {
+		qe.targets[key] = true
+	}
+	position := len(c.queue)
+	for i := range c.queue {
+		if qe.created.Before(&c.queue[i].created) {
+			position = i
+			break
+		}
+	}
+	c.queue = append(c.queue, nil)
+	copy(c.queue[position+1:], c.queue[position:])
+	c.queue[position] = qe
+	return &QueuedError{Position: int32(position + 1), err: err}
+}
+
+// dequeue removes the experiment from the queue
+func (c *Impl) dequeue(eakey string) {
+	queue := make([]*queuedExperiment, 0, len(c.queue))
+	for _, qe := range c.queue {
+		if qe.key != eakey {
+			queue = append(queue, qe)
+		}
+	}
+	c.queue = queue
+}
+
+// Restore registers experiments holding targets before the controller restarts, and queues those waiting for targets,
+// both in order of creation, so that no queued experiment takes targets of a running one
+func (c *Impl) Restore(ctx context.Context, instances []iter8v1alpha2.Experiment) {
+	sorted := make([]*iter8v1alpha2.Experiment, len(instances))
+	for i := range instances {
+		sorted[i] = &instances[i]
+	}
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].CreationTimestamp.Before(&sorted[j].CreationTimestamp)
+	})
+
+	for _, instance := range sorted {
+		if !holdsTargets(instance) {
+			continue
+		}
+		if _, err := c.RegisterExperiment(ctx, instance); err != nil {
+			c.logger.Error(err, "Fail to restore experiment", "experiment", experimentKey(instance))
+		}
+	}
+
+	c.m.Lock()
+	defer c.m.Unlock()
+	for _, instance := range sorted {
+		if instance.Status.Queued() {
+			c.enqueue(instance, nil)
+		}
+	}
+}
+
+// holdsTargets tells whether the experiment has taken its targets, i.e. it has started or set up routing rules
+func holdsTargets(instance *iter8v1alpha2.Experiment) bool {
+	status := instance.Status
+	return !status.ExperimentCompleted() && !status.Queued() &&
+		(status.StartTimestamp != nil || status.RoutingRulesReady())
+}
+
+// getTargetKeys returns keys of service and versions of the experiment
+func getTargetKeys(instance *iter8v1alpha2.Experiment) []string {
+	out := make([]string, 0)
+	service := instance.Spec.Service
+	if service.Name != "" && service.Kind != "ServiceEntry" {
+		out = append(out, targetKey(service.Name, instance.ServiceNamespace()))
+	}
+	if instance.GetBaseline() != "" {
+		out = append(out, versionKey(instance, instance.GetBaseline()))
+	}
+	for _, candidate := range instance.GetCandidates() {
+		out = append(out, versionKey(instance, candidate))
+	}
+	return out
+}
@@ -0,0 +1,221 @@
+/*
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package adapter
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	iter8v1alpha2 "github.com/iter8-tools/iter8/pkg/apis/iter8/v1alpha2"
+)
+
+var created = time.Date(2020, time.September, 1, 0, 0, 0, 0, time.UTC)
+
+// queueExperiment returns an experiment on service reviews created the given minutes after created
+func queueExperiment(name string, minutes int, baseline string, candidates ...string) *iter8v1alpha2.Experiment {
+	instance := &iter8v1alpha2.Experiment{}
+	instance.Name = name
+	instance.Namespace = "bookinfo"
+	instance.CreationTimestamp = metav1.NewTime(created.Add(time.Duration(minutes) * time.Minute))
+	instance.Spec.Service.ObjectReference = &corev1.ObjectReference{Kind: "Deployment", Name: "reviews"}
+	instance.Spec.Service.Baseline = baseline
+	instance.Spec.Service.Candidates = candidates
+	return instance
+}
+
+func queuedNames(c *Impl) []string {
+	out := []string{}
+	for _, nn := range c.QueuedExperiments() {
+		out = append(out, nn.Name)
+	}
+	return out
+}
+
+func TestEnqueue(t *testing.T) {
+	tests := []struct {
+		name          string
+		arrivals      []*iter8v1alpha2.Experiment
+		wantOrder     []string
+		wantPositions []int32
+	}{
+		{
+			name: "in order of creation",
+			arrivals: []*iter8v1alpha2.Experiment{
+				queueExperiment("a", 1, "reviews-v1", "reviews-v2"),
+				queueExperiment("b", 2, "reviews-v1", "reviews-v3"),
+			},
+			wantOrder:     []string{"a", "b"},
+			wantPositions: []int32{1, 2},
+		},
+		{
+			name: "earlier experiment arriving later",
+			arrivals: []*iter8v1alpha2.Experiment{
+				queueExperiment("b", 2, "reviews-v1", "reviews-v3"),
+				queueExperiment("c", 3, "reviews-v1", "reviews-v4"),
+				queueExperiment("a", 1, "reviews-v1", "reviews-v2"),
+			},
+			wantOrder:     []string{"a", "b", "c"},
+			wantPositions: []int32{1, 2, 1},
+		},
+		{
+			name: "queued again",
+			arrivals: []*iter8v1alpha2.Experiment{
+				queueExperiment("a", 1, "reviews-v1", "reviews-v2"),
+				queueExperiment("b", 2, "reviews-v1", "reviews-v3"),
+				queueExperiment("a", 1, "reviews-v1", "reviews-v2"),
+			},
+			wantOrder:     []string{"a", "b"},
+			wantPositions: []int32{1, 2, 1},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := New(log.Log).(*Impl)
+			positions := []int32{}
+			for _, instance := range tt.arrivals {
+				positions = append(positions, c.enqueue(instance, nil).Position)
+			}
+			if got := queuedNames(c); !reflect.DeepEqual(got, tt.wantOrder) {
+				t.Errorf("queue = %v, want %v", got, tt.wantOrder)
+			}
+			if !reflect.DeepEqual(positions, tt.wantPositions) {
+				t.Errorf("positions = %v, want %v", positions, tt.wantPositions)
+			}
+		})
+	}
+}
+
+func TestCheckQueue(t *testing.T) {
+	a := queueExperiment("a", 1, "reviews-v1", "reviews-v2")
+	b := queueExperiment("b", 2, "ratings-v1", "ratings-v2")
+	b.Spec.Service.Name = "ratings"
+
+	tests := []struct {
+		name     string
+		queued   []*iter8v1alpha2.Experiment
+		instance *iter8v1alpha2.Experiment
+		wantErr  bool
+	}{
+		{name: "empty queue", instance: queueExperiment("c", 3, "reviews-v1", "reviews-v3")},
+		{name: "shared target queued ahead", queued: []*iter8v1alpha2.Experiment{a},
+			instance: queueExperiment("c", 3, "reviews-v1", "reviews-v3"), wantErr: true},
+		{name: "other targets queued ahead", queued: []*iter8v1alpha2.Experiment{b},
+			instance: queueExperiment("c", 3, "reviews-v1", "reviews-v3")},
+		{name: "first in queue", queued: []*iter8v1alpha2.Experiment{a, queueExperiment("c", 3, "reviews-v1", "reviews-v3")},
+			instance: a},
+		{name: "queued behind shared target", queued: []*iter8v1alpha2.Experiment{a, queueExperiment("c", 3, "reviews-v1", "reviews-v3")},
+			instance: queueExperiment("c", 3, "reviews-v1", "reviews-v3"), wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := New(log.Log).(*Impl)
+			for _, instance := range tt.queued {
+				c.enqueue(instance, nil)
+			}
+			if err := c.checkQueue(tt.instance); (err != nil) != tt.wantErr {
+				t.Errorf("checkQueue() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestRestore(t *testing.T) {
+	running := queueExperiment("running", 2, "reviews-v1", "reviews-v2")
+	now := metav1.Now()
+	running.Status.StartTimestamp = &now
+
+	queued := queueExperiment("queued", 1, "reviews-v1", "reviews-v3")
+	queued.Status.Phase = iter8v1alpha2.PhaseQueued
+
+	completed := queueExperiment("completed", 0, "reviews-v1", "reviews-v4")
+	completed.Status.StartTimestamp = &now
+	completed.Status.MarkExperimentCompleted("done")
+
+	c := New(log.Log).(*Impl)
+	c.Restore(context.Background(), []iter8v1alpha2.Experiment{*completed, *queued, *running})
+
+	if name, _, ok := c.DeploymentToExperiment("reviews-v1", "bookinfo"); !ok || name != "running" {
+		t.Errorf("baseline taken by %q, want running", name)
+	}
+	if got := c.QueuedExperiments(); !reflect.DeepEqual(got, []types.NamespacedName{{Name: "queued", Namespace: "bookinfo"}}) {
+		t.Errorf("queue = %v, want [bookinfo/queued]", got)
+	}
+
+	// queued experiment created earlier waits for the running one
+	if _, err := c.RegisterExperiment(context.Background(), queued); err == nil {
+		t.Errorf("queued experiment should not be registered while targets are taken")
+	}
+	c.RemoveExperiment(running)
+	if _, err := c.RegisterExperiment(context.Background(), queued); err != nil {
+		t.Errorf("queued experiment should be registered once targets are released: %v", err)
+	}
+	if len(c.QueuedExperiments()) != 0 {
+		t.Errorf("registered experiment should be dequeued")
+	}
+}
+
+func TestSharedCandidate(t *testing.T) {
+	tests := []struct {
+		name       string
+		service    string
+		baseline   string
+		candidates []string
+		wantQueued bool
+	}{
+		{name: "other candidate", service: "ratings", baseline: "ratings-v1", candidates: []string{"ratings-v2"}},
+		{name: "shared candidate only", service: "ratings", baseline: "ratings-v1", candidates: []string{"reviews-v2"},
+			wantQueued: true},
+		{name: "candidate taken as baseline", service: "ratings", baseline: "reviews-v2", candidates: []string{"ratings-v2"},
+			wantQueued: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := New(log.Log).(*Impl)
+			running := queueExperiment("running", 1, "reviews-v1", "reviews-v2")
+			if _, err := c.RegisterExperiment(context.Background(), running); err != nil {
+				t.Fatalf("RegisterExperiment() error = %v", err)
+			}
+
+			instance := queueExperiment("next", 2, tt.baseline, tt.candidates...)
+			instance.Spec.Service.Name = tt.service
+			_, err := c.RegisterExperiment(context.Background(), instance)
+			if (err != nil) != tt.wantQueued {
+				t.Errorf("RegisterExperiment() error = %v, wantQueued %v", err, tt.wantQueued)
+			}
+			if queued := len(c.QueuedExperiments()) > 0; queued != tt.wantQueued {
+				t.Errorf("queued = %v, want %v", queued, tt.wantQueued)
+			}
+
+			// the candidate is released with the running experiment
+			if tt.wantQueued {
+				c.RemoveExperiment(running)
+				if _, err := c.RegisterExperiment(context.Background(), instance); err != nil {
+					t.Errorf("RegisterExperiment() after release error = %v", err)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,108 @@
+/*
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package experiment
+
+// This file contains functions used for evaluating criteria after traffic goes to the winner.
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	iter8v1alpha2 "github.com/iter8-tools/iter8/pkg/apis/iter8/v1alpha2"
+	"github.com/iter8-tools/iter8/pkg/controller/experiment/util"
+)
+
+// toBake tells whether the experiment should stay in bake period before it completes.
+// When bake period starts, traffic is routed to the winner and bake status is initialized.
+func (r *ReconcileExperiment) toBake(context context.Context, instance *iter8v1alpha2.Experiment) bool {
+	duration, _ := instance.Spec.GetBakeDuration()
+	if duration <= 0 || instance.Spec.Terminate() {
+		return false
+	}
+
+	if instance.Status.Bake != nil {
+		return time.Now().Before(instance.Status.Bake.StartTimestamp.Add(duration))
+	}
+
+	// bake only if traffic is promoted to a candidate
+	if instance.Spec.GetOnTermination() != iter8v1alpha2.OnTerminationToWinner {
+		return false
+	}
+	winner := currentWinner(instance)
+	if winner == nil || winner.ID == instance.Status.Assessment.Baseline.ID {
+		return false
+	}
+
+	// traffic is shifted once winner is scaled up to serve all of it
+	if waiting := r.scaleForSplit(context, instance, map[string]int32{winner.Name: 100}); len(waiting) > 0 {
+		r.markTrafficHeld(context, instance, "Bake period waiting for replicas: %s", strings.Join(waiting, "; "))
+		r.markRequeue()
+		return true
+	}
+
+	for _, va := range allVersions(instance) {
+		va.Weight = 0
+	}
+	winner.Weight = 100
+	markTrafficStart(instance)
+	if err := r.router.UpdateRouteWithTrafficUpdate(context, instance); err != nil {
+		// retry in next iteration
+		r.markRoutingRulesError(context, instance, "%v", err)
+		return true
+	}
+	r.markTrafficUpdate(context, instance, "Traffic: %s", instance.Status.TrafficToString())
+
+	now := metav1.Now()
+	instance.Status.Bake = &iter8v1alpha2.BakeStatus{
+		StartTimestamp: &now,
+	}
+	util.Logger(context).Info("BakeStarted", "winner", winner.Name, "duration", duration)
+	r.markStatusUpdate()
+	return true
+}
+
+// processBake evaluates criteria against versions receiving traffic during bake period.
+// Only metrics collected since the bake period starts are assessed, so that a regression is not diluted by earlier iterations.
+// If criteria are breached, traffic is routed back to baseline and the experiment completes.
+// returns non-nil error if reconcile process should be terminated right after this function
+func (r *ReconcileExperiment) processBake(context context.Context, instance *iter8v1alpha2.Experiment) error {
+	if len(instance.Spec.Criteria) > 0 {
+		if _, err := r.fetchAssessment(context, instance, instance.Status.Bake.StartTimestamp.Time); err != nil {
+			return err
+		}
+		r.markAnalyticsServiceRunning(context, instance, "")
+
+		if name := breachedVersion(instance); name != "" {
+			instance.Status.Bake.RolledBack = true
+			instance.Spec.ManualOverride = &iter8v1alpha2.ManualOverride{
+				Action: iter8v1alpha2.ActionTerminate,
+				TrafficSplit: map[string]int32{
+					instance.Status.Assessment.Baseline.Name: 100,
+				},
+			}
+			util.Logger(context).Info("RollbackAfterPromotion", "criteria breached by", name)
+			return nil
+		}
+	}
+
+	duration, _ := instance.Spec.GetBakeDuration()
+	elapsed := time.Since(instance.Status.Bake.StartTimestamp.Time).Round(time.Second)
+	r.markIterationUpdate(context, instance, "Bake %s/%s elapsed", elapsed, duration)
+	return nil
+}
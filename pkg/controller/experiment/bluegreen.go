@@ -0,0 +1,76 @@
+/*
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package experiment
+
+// This file contains functions used for validating and switching traffic by blue_green strategy.
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	iter8v1alpha2 "github.com/iter8-tools/iter8/pkg/apis/iter8/v1alpha2"
+)
+
+func isBlueGreen(instance *iter8v1alpha2.Experiment) bool {
+	return instance.Spec.GetStrategy() == string(iter8v1alpha2.StrategyBlueGreen)
+}
+
+// applyBlueGreen moves blue_green strategy forward with the latest assessment.
+// The candidate is switched to 100 percent of traffic once it has served minRequestCount requests without failing criteria.
+// A candidate failing criteria is rolled back before this is called, which switches traffic back to baseline.
+// returns true if traffic split is changed
+func applyBlueGreen(instance *iter8v1alpha2.Experiment) bool {
+	status := &instance.Status
+	if status.BlueGreen == nil {
+		status.BlueGreen = &iter8v1alpha2.BlueGreenStatus{
+			Phase: iter8v1alpha2.BlueGreenPhaseValidating,
+		}
+	}
+	assessment := status.Assessment
+	candidate := &assessment.Candidates[0]
+	now := metav1.Now()
+
+	switch status.BlueGreen.Phase {
+	case iter8v1alpha2.BlueGreenPhaseValidating:
+		if candidate.Rollback || candidate.RequestCount < instance.Spec.GetMinRequestCount() {
+			// no winner is declared until candidate is validated
+			if status.IsWinnerAssessmentAvailable() {
+				assessment.Winner.WinnerFound = false
+			}
+			return false
+		}
+		status.BlueGreen.Phase = iter8v1alpha2.BlueGreenPhaseRollbackWindow
+		status.BlueGreen.SwitchTimestamp = &now
+	case iter8v1alpha2.BlueGreenPhaseRollbackWindow:
+		window, _ := instance.Spec.GetRollbackWindow()
+		if !now.Time.Before(status.BlueGreen.SwitchTimestamp.Add(window)) {
+			status.BlueGreen.Phase = iter8v1alpha2.BlueGreenPhaseSwitched
+		}
+	}
+
+	// candidate is the winner once traffic is switched
+	if status.IsWinnerAssessmentAvailable() {
+		name := candidate.Name
+		assessment.Winner.Name = &name
+		assessment.Winner.Winner = candidate.ID
+		assessment.Winner.Probability = candidate.WinProbability
+		assessment.Winner.WinnerFound = true
+	}
+
+	updated := assessment.Baseline.Weight != 0 || candidate.Weight != 100
+	assessment.Baseline.Weight = 0
+	candidate.Weight = 100
+	return updated
+}
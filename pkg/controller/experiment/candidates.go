@@ -0,0 +1,156 @@
+/*
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package experiment
+
+// This file contains functions used for generating candidates from templates.
+
+import (
+	"context"
+	"fmt"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	iter8v1alpha2 "github.com/iter8-tools/iter8/pkg/apis/iter8/v1alpha2"
+	"github.com/iter8-tools/iter8/pkg/controller/experiment/util"
+)
+
+// CandidateLabel is the label put on candidate deployments generated by iter8
+const CandidateLabel = "iter8-tools/candidate"
+
+// generateCandidates creates candidate deployments from templates if they don't exist
+// returns non-nil error if some candidate cannot be created
+func (r *ReconcileExperiment) generateCandidates(context context.Context, instance *iter8v1alpha2.Experiment,
+	baseline *appsv1.Deployment) error {
+	for i := range instance.Spec.CandidateTemplates {
+		template := &instance.Spec.CandidateTemplates[i]
+		err := r.Get(context, types.NamespacedName{Name: template.Name, Namespace: baseline.Namespace}, &appsv1.Deployment{})
+		if err == nil {
+			continue
+		}
+		if !errors.IsNotFound(err) {
+			return err
+		}
+
+		candidate, err := candidateFromTemplate(baseline, template)
+		if err != nil {
+			return err
+		}
+		// candidate is not owned by the experiment, so that the winner survives deletion of the experiment;
+		// other candidates are deleted by cleanup at the end of the experiment
+		if err := r.Create(context, candidate); err != nil && !errors.IsAlreadyExists(err) {
+			return err
+		}
+		util.Logger(context).Info("CandidateGenerated", "name", candidate.Name)
+	}
+	return nil
+}
+
+// candidateFromTemplate returns a copy of baseline deployment patched by the template
+func candidateFromTemplate(baseline *appsv1.Deployment, template *iter8v1alpha2.CandidateTemplate) (*appsv1.Deployment, error) {
+	candidate := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        template.Name,
+			Namespace:   baseline.Namespace,
+			Labels:      make(map[string]string),
+			Annotations: make(map[string]string),
+		},
+		Spec: *baseline.Spec.DeepCopy(),
+	}
+	for k, v := range baseline.Labels {
+		candidate.Labels[k] = v
+	}
+	candidate.Labels[CandidateLabel] = template.Name
+
+	// pods of candidate are differentiated from pods of baseline by labels,
+	// otherwise routing subset of baseline would also select pods of candidate
+	labels := template.Labels
+	if len(labels) == 0 {
+		labels = map[string]string{"version": template.Name}
+	}
+	if !overridesLabel(baseline.Spec.Template.Labels, labels) {
+		return nil, fmt.Errorf("Labels of candidate template %s should change the value of a pod label of baseline %s",
+			template.Name, baseline.Name)
+	}
+	if candidate.Spec.Template.Labels == nil {
+		candidate.Spec.Template.Labels = make(map[string]string)
+	}
+	for k, v := range labels {
+		candidate.Spec.Template.Labels[k] = v
+		candidate.Labels[k] = v
+	}
+	if candidate.Spec.Selector == nil {
+		candidate.Spec.Selector = &metav1.LabelSelector{}
+	}
+	if candidate.Spec.Selector.MatchLabels == nil {
+		candidate.Spec.Selector.MatchLabels = make(map[string]string)
+	}
+	for k, v := range labels {
+		candidate.Spec.Selector.MatchLabels[k] = v
+	}
+
+	containers := candidate.Spec.Template.Spec.Containers
+	if len(containers) == 0 {
+		return nil, fmt.Errorf("No container in baseline %s", baseline.Name)
+	}
+	container := &containers[0]
+	if template.Container != nil {
+		container = nil
+		for i := range containers {
+			if containers[i].Name == *template.Container {
+				container = &containers[i]
+			}
+		}
+		if container == nil {
+			return nil, fmt.Errorf("Container %s not found in baseline %s", *template.Container, baseline.Name)
+		}
+	}
+
+	if template.Image != nil {
+		container.Image = *template.Image
+	}
+	if template.Args != nil {
+		container.Args = template.Args
+	}
+	for _, env := range template.Env {
+		container.Env = withEnv(container.Env, env)
+	}
+	return candidate, nil
+}
+
+// overridesLabel tells whether some of the labels changes the value of an existing label
+func overridesLabel(existing, labels map[string]string) bool {
+	for k, v := range labels {
+		if old, ok := existing[k]; ok && old != v {
+			return true
+		}
+	}
+	return false
+}
+
+// withEnv adds or replaces an environment variable
+func withEnv(envs []corev1.EnvVar, env corev1.EnvVar) []corev1.EnvVar {
+	for i := range envs {
+		if envs[i].Name == env.Name {
+			envs[i] = env
+			return envs
+		}
+	}
+	return append(envs, env)
+}
@@ -0,0 +1,132 @@
+/*
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package experiment
+
+import (
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	iter8v1alpha2 "github.com/iter8-tools/iter8/pkg/apis/iter8/v1alpha2"
+)
+
+func TestCandidateFromTemplate(t *testing.T) {
+	image := "reviews:v2"
+	sidecar := "sidecar"
+	missing := "missing"
+
+	tests := []struct {
+		name         string
+		podLabels    map[string]string
+		template     iter8v1alpha2.CandidateTemplate
+		wantErr      bool
+		wantLabels   map[string]string
+		wantImages   []string
+		wantSelector map[string]string
+	}{
+		{
+			name:         "default version label",
+			podLabels:    map[string]string{"app": "reviews", "version": "v1"},
+			template:     iter8v1alpha2.CandidateTemplate{Name: "reviews-v2", Image: &image},
+			wantLabels:   map[string]string{"app": "reviews", "version": "reviews-v2"},
+			wantImages:   []string{"reviews:v2", "proxy:v1"},
+			wantSelector: map[string]string{"app": "reviews", "version": "reviews-v2"},
+		},
+		{
+			name:      "default version label missing in baseline",
+			podLabels: map[string]string{"app": "reviews"},
+			template:  iter8v1alpha2.CandidateTemplate{Name: "reviews-v2", Image: &image},
+			wantErr:   true,
+		},
+		{
+			name:      "labels only added",
+			podLabels: map[string]string{"app": "reviews", "version": "v1"},
+			template: iter8v1alpha2.CandidateTemplate{Name: "reviews-v2",
+				Labels: map[string]string{"track": "canary"}},
+			wantErr: true,
+		},
+		{
+			name:      "labels unchanged",
+			podLabels: map[string]string{"app": "reviews", "version": "v1"},
+			template: iter8v1alpha2.CandidateTemplate{Name: "reviews-v2",
+				Labels: map[string]string{"version": "v1"}},
+			wantErr: true,
+		},
+		{
+			name:      "custom label",
+			podLabels: map[string]string{"app": "reviews", "track": "stable"},
+			template: iter8v1alpha2.CandidateTemplate{Name: "reviews-v2", Container: &sidecar, Image: &image,
+				Labels: map[string]string{"track": "canary"}},
+			wantLabels:   map[string]string{"app": "reviews", "track": "canary"},
+			wantImages:   []string{"reviews:v1", "reviews:v2"},
+			wantSelector: map[string]string{"app": "reviews", "track": "canary"},
+		},
+		{
+			name:      "missing container",
+			podLabels: map[string]string{"app": "reviews", "version": "v1"},
+			template:  iter8v1alpha2.CandidateTemplate{Name: "reviews-v2", Container: &missing},
+			wantErr:   true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			baseline := &appsv1.Deployment{
+				ObjectMeta: metav1.ObjectMeta{Name: "reviews-v1", Namespace: "bookinfo"},
+			}
+			baseline.Spec.Selector = &metav1.LabelSelector{MatchLabels: map[string]string{"app": "reviews"}}
+			baseline.Spec.Template.Labels = tt.podLabels
+			baseline.Spec.Template.Spec.Containers = []corev1.Container{
+				{Name: "reviews", Image: "reviews:v1"},
+				{Name: "sidecar", Image: "proxy:v1"},
+			}
+
+			candidate, err := candidateFromTemplate(baseline, &tt.template)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("candidateFromTemplate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if candidate.Name != tt.template.Name || candidate.Namespace != baseline.Namespace {
+				t.Errorf("candidate = %s/%s, want bookinfo/%s", candidate.Namespace, candidate.Name, tt.template.Name)
+			}
+			if len(candidate.OwnerReferences) > 0 {
+				t.Errorf("candidate should not be owned, got %v", candidate.OwnerReferences)
+			}
+			for k, v := range tt.wantLabels {
+				if candidate.Spec.Template.Labels[k] != v {
+					t.Errorf("pod label %s = %q, want %q", k, candidate.Spec.Template.Labels[k], v)
+				}
+			}
+			for k, v := range tt.wantSelector {
+				if candidate.Spec.Selector.MatchLabels[k] != v {
+					t.Errorf("selector %s = %q, want %q", k, candidate.Spec.Selector.MatchLabels[k], v)
+				}
+			}
+			for i, image := range tt.wantImages {
+				if got := candidate.Spec.Template.Spec.Containers[i].Image; got != image {
+					t.Errorf("image of container %d = %q, want %q", i, got, image)
+				}
+			}
+			if baseline.Spec.Template.Spec.Containers[0].Image != "reviews:v1" || baseline.Spec.Template.Labels["app"] != "reviews" {
+				t.Errorf("baseline should not be modified")
+			}
+		})
+	}
+}
@@ -0,0 +1,193 @@
+/*
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package experiment
+
+// This file contains functions used for stopping an experiment before maxIterations
+// once its result is clear.
+
+import (
+	"math"
+
+	"github.com/iter8-tools/iter8/pkg/analytics"
+	iter8v1alpha2 "github.com/iter8-tools/iter8/pkg/apis/iter8/v1alpha2"
+)
+
+// applyEarlyStop evaluates the early-stop policy against the latest assessment.
+// When the policy is met, the winner assessment is updated and the early-stop status is marked as met,
+// so that the experiment completes without running the remaining iterations.
+func applyEarlyStop(instance *iter8v1alpha2.Experiment) {
+	es := instance.Spec.EarlyStop
+	if es == nil {
+		return
+	}
+	if instance.Status.EarlyStop == nil {
+		instance.Status.EarlyStop = &iter8v1alpha2.EarlyStopStatus{}
+	}
+
+	var winner *iter8v1alpha2.VersionAssessment
+	switch es.GetEarlyStopPolicy() {
+	case iter8v1alpha2.EarlyStopPolicyWinProbability:
+		winner = winProbabilityWinner(instance)
+	case iter8v1alpha2.EarlyStopPolicySPRT:
+		winner = sprtWinner(instance)
+	}
+	if winner == nil {
+		return
+	}
+
+	assessment := instance.Status.Assessment
+	if assessment.Winner == nil || assessment.Winner.WinnerAssessment == nil {
+		return
+	}
+	name := winner.Name
+	assessment.Winner.Name = &name
+	assessment.Winner.Winner = winner.ID
+	assessment.Winner.Probability = winner.WinProbability
+	assessment.Winner.WinnerFound = true
+	instance.Status.EarlyStop.Met = true
+}
+
+// winProbabilityWinner tracks how many consecutive iterations the current winner has held the threshold,
+// and returns the winner once the required number of iterations is reached
+func winProbabilityWinner(instance *iter8v1alpha2.Experiment) *iter8v1alpha2.VersionAssessment {
+	es, status := instance.Spec.EarlyStop, instance.Status.EarlyStop
+
+	winner := currentWinner(instance)
+	if winner == nil || winner.WinProbability < es.GetThreshold() {
+		status.Winner = nil
+		status.ConsecutiveIterations = 0
+		return nil
+	}
+
+	if status.Winner == nil || *status.Winner != winner.Name {
+		name := winner.Name
+		status.Winner = &name
+		status.ConsecutiveIterations = 0
+	}
+	status.ConsecutiveIterations++
+
+	if status.ConsecutiveIterations < es.GetConsecutiveIterations() {
+		return nil
+	}
+	return winner
+}
+
+// currentWinner returns the assessment of the version declared as winner, nil if no winner is found
+func currentWinner(instance *iter8v1alpha2.Experiment) *iter8v1alpha2.VersionAssessment {
+	assessment := instance.Status.Assessment
+	if !instance.Status.IsWinnerFound() {
+		return nil
+	}
+	if assessment.Baseline.ID == assessment.Winner.Winner {
+		return &assessment.Baseline
+	}
+	for i := range assessment.Candidates {
+		if assessment.Candidates[i].ID == assessment.Winner.Winner && !assessment.Candidates[i].Rollback {
+			return &assessment.Candidates[i]
+		}
+	}
+	return nil
+}
+
+// sprtWinner runs a sequential probability ratio test on each active candidate, with
+// H0: the candidate has the baseline rate, and
+// H1: the candidate improves on the baseline rate by the minimum detectable effect.
+// The accepted candidate with the largest log-likelihood ratio wins; baseline wins once all candidates are rejected.
+// No decision is accepted before every active version reaches the sample size required by a fixed-horizon z-test
+// with the same error rates, which guards the test against noise in the first few requests.
+// nil is returned if no decision can be made yet.
+func sprtWinner(instance *iter8v1alpha2.Experiment) *iter8v1alpha2.VersionAssessment {
+	es := instance.Spec.EarlyStop
+	metric := instance.Spec.GetEarlyStopMetric()
+	if !isZeroToOneRatio(instance, metric) {
+		return nil
+	}
+
+	assessment := instance.Status.Assessment
+	baseline := criterionAssessment(&assessment.Baseline, metric)
+	if baseline == nil || baseline.Statistics == nil || baseline.Statistics.Value == nil {
+		return nil
+	}
+	p0 := float64(*baseline.Statistics.Value)
+	p1 := p0 * (1 + float64(instance.Spec.GetMinimumDetectableEffect()))
+	if preferredDirection(instance, metric) == preferredDirectionLower {
+		p1 = p0 * (1 - float64(instance.Spec.GetMinimumDetectableEffect()))
+	}
+	if p0 <= 0 || p0 >= 1 || p1 <= 0 || p1 >= 1 {
+		return nil
+	}
+
+	alpha, beta := float64(es.GetAlpha()), float64(es.GetBeta())
+	required, err := analytics.RequiredSampleSize(iter8v1alpha2.TestZ, alpha, 1-beta,
+		float64(instance.Spec.GetMinimumDetectableEffect()), p0, 0)
+	if err != nil {
+		return nil
+	}
+	instance.Status.EarlyStop.RequiredSampleSize = &required
+	if assessment.Baseline.RequestCount < required {
+		return nil
+	}
+	for _, candidate := range assessment.Candidates {
+		if !candidate.Rollback && candidate.RequestCount < required {
+			return nil
+		}
+	}
+
+	upper := math.Log((1 - beta) / alpha)
+	lower := math.Log(beta / (1 - alpha))
+
+	var winner *iter8v1alpha2.VersionAssessment
+	best := upper
+	rejected := true
+	for i := range assessment.Candidates {
+		candidate := &assessment.Candidates[i]
+		if candidate.Rollback {
+			continue
+		}
+		ca := criterionAssessment(candidate, metric)
+		if ca == nil || ca.Statistics == nil || ca.Statistics.Value == nil {
+			rejected = false
+			continue
+		}
+		p := float64(*ca.Statistics.Value)
+		n := float64(candidate.RequestCount)
+		llr := n * (p*math.Log(p1/p0) + (1-p)*math.Log((1-p1)/(1-p0)))
+		if llr >= best {
+			winner, best = candidate, llr
+		}
+		if llr > lower {
+			rejected = false
+		}
+	}
+
+	if winner == nil && rejected {
+		return &assessment.Baseline
+	}
+	return winner
+}
+
+// isZeroToOneRatio tells whether the metric is a ratio metric with value in range 0 to 1
+func isZeroToOneRatio(instance *iter8v1alpha2.Experiment, metric string) bool {
+	if instance.Spec.Metrics == nil {
+		return false
+	}
+	for _, m := range instance.Spec.Metrics.RatioMetrics {
+		if m.Name == metric {
+			return m.IsZeroToOne()
+		}
+	}
+	return false
+}
@@ -0,0 +1,85 @@
+/*
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package experiment
+
+import (
+	"testing"
+
+	analyticsv1alpha2 "github.com/iter8-tools/iter8/pkg/analytics/api/v1alpha2"
+	iter8v1alpha2 "github.com/iter8-tools/iter8/pkg/apis/iter8/v1alpha2"
+)
+
+func sprtExperiment(count int32, candidateRate float32) *iter8v1alpha2.Experiment {
+	policy := iter8v1alpha2.EarlyStopPolicySPRT
+	zeroToOne := true
+	version := func(rate float32) analyticsv1alpha2.VersionAssessment {
+		return analyticsv1alpha2.VersionAssessment{
+			RequestCount: count,
+			CriterionAssessments: []analyticsv1alpha2.CriterionAssessment{{
+				MetricID:   "conversion",
+				Statistics: &analyticsv1alpha2.Statistics{Value: &rate},
+			}},
+		}
+	}
+	return &iter8v1alpha2.Experiment{
+		Spec: iter8v1alpha2.ExperimentSpec{
+			Criteria:  []iter8v1alpha2.Criterion{{Metric: "conversion"}},
+			EarlyStop: &iter8v1alpha2.EarlyStop{Policy: &policy},
+			Metrics: &iter8v1alpha2.Metrics{
+				RatioMetrics: []iter8v1alpha2.RatioMetric{{Name: "conversion", ZeroToOne: &zeroToOne}},
+			},
+		},
+		Status: iter8v1alpha2.ExperimentStatus{
+			EarlyStop: &iter8v1alpha2.EarlyStopStatus{},
+			Assessment: &iter8v1alpha2.Assessment{
+				Baseline: iter8v1alpha2.VersionAssessment{Name: "baseline", VersionAssessment: version(0.1)},
+				Candidates: []iter8v1alpha2.VersionAssessment{
+					{Name: "candidate", VersionAssessment: version(candidateRate)},
+				},
+			},
+		},
+	}
+}
+
+func TestSprtWinner(t *testing.T) {
+	cases := []struct {
+		name  string
+		count int32
+		rate  float32
+		want  string
+	}{
+		{"below required sample size", 1000, 0.2, ""},
+		{"candidate accepted", 100000, 0.11, "candidate"},
+		{"candidate rejected", 100000, 0.09, "baseline"},
+		{"no decision yet", 100000, 0.1025, ""},
+	}
+	for _, c := range cases {
+		instance := sprtExperiment(c.count, c.rate)
+		got := ""
+		if winner := sprtWinner(instance); winner != nil {
+			got = winner.Name
+		}
+		if got != c.want {
+			t.Errorf("%s: got winner %q, want %q", c.name, got, c.want)
+		}
+		if instance.Status.EarlyStop.RequiredSampleSize == nil {
+			t.Errorf("%s: required sample size not reported", c.name)
+		}
+		if instance.Status.Assessment.RequiredSampleSize != nil {
+			t.Errorf("%s: required sample size of frequentist assessment should not be set", c.name)
+		}
+	}
+}
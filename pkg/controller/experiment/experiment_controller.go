@@ -22,6 +22,7 @@ import (
 	istioclient "istio.io/client-go/pkg/clientset/versioned"
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
 	"k8s.io/apimachinery/pkg/api/errors"
 	runtime "k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
@@ -42,6 +43,7 @@ import (
 	"github.com/iter8-tools/iter8/pkg/controller/experiment/adapter"
 	"github.com/iter8-tools/iter8/pkg/controller/experiment/routing"
 	"github.com/iter8-tools/iter8/pkg/controller/experiment/routing/router"
+	"github.com/iter8-tools/iter8/pkg/controller/experiment/targets"
 	"github.com/iter8-tools/iter8/pkg/controller/experiment/util"
 	iter8notifier "github.com/iter8-tools/iter8/pkg/notifier"
 )
@@ -96,6 +98,7 @@ func newReconciler(mgr manager.Manager) (*ReconcileExperiment, error) {
 		eventRecorder:      mgr.GetEventRecorderFor(Iter8Controller),
 		notificationCenter: nc,
 		iter8Adapter:       iter8Adapter,
+		queueEvents:        make(chan event.GenericEvent, queueEventBufferSize),
 	}, nil
 }
 
@@ -112,15 +115,34 @@ func add(mgr manager.Manager, r *ReconcileExperiment) error {
 			name, namespace := e.Meta.GetName(), e.Meta.GetNamespace()
 			ok := r.iter8Adapter.MarkDeploymentDetected(name, namespace)
 			if !ok {
-				return false
+				// new deployment may be selected by labels as a target
+				_, _, ok = r.iter8Adapter.SelectorToExperiment(namespace, e.Meta.GetLabels())
+				if ok {
+					log.Info("DeploymentSelected", "", name+"."+namespace)
+				}
+				return ok
 			}
 
 			log.Info("DeploymentDetected", "", name+"."+namespace)
 
 			return true
 		},
-		UpdateFunc: func(event.UpdateEvent) bool {
-			return false
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			oldTemplate, newTemplate := targets.PodTemplate(e.ObjectOld), targets.PodTemplate(e.ObjectNew)
+			if oldTemplate == nil || newTemplate == nil ||
+				equality.Semantic.DeepEqual(oldTemplate, newTemplate) {
+				return false
+			}
+
+			name, namespace := e.MetaNew.GetName(), e.MetaNew.GetNamespace()
+			ok := r.iter8Adapter.MarkDeploymentUpdated(name, namespace)
+			if !ok {
+				return false
+			}
+
+			log.Info("DeploymentUpdated", "", name+"."+namespace)
+
+			return true
 		},
 		DeleteFunc: func(e event.DeleteEvent) bool {
 			name, namespace := e.Meta.GetName(), e.Meta.GetNamespace()
@@ -139,6 +161,10 @@ func add(mgr manager.Manager, r *ReconcileExperiment) error {
 		func(a handler.MapObject) []reconcile.Request {
 			name, namespace := a.Meta.GetName(), a.Meta.GetNamespace()
 			experimentName, experimentNamespace, ok := r.iter8Adapter.DeploymentToExperiment(name, namespace)
+			if !ok {
+				// deployment may be selected by labels
+				experimentName, experimentNamespace, ok = r.iter8Adapter.SelectorToExperiment(namespace, a.Meta.GetLabels())
+			}
 			if !ok {
 				return nil
 			}
@@ -156,6 +182,66 @@ func add(mgr manager.Manager, r *ReconcileExperiment) error {
 	err = c.Watch(&source.Kind{Type: &appsv1.Deployment{}},
 		&handler.EnqueueRequestsFromMapFunc{ToRequests: deploymentToExperiment},
 		deploymentPredicate)
+	if err != nil {
+		return err
+	}
+
+	// statefulsets are tracked in the same way as deployments
+	err = c.Watch(&source.Kind{Type: &appsv1.StatefulSet{}},
+		&handler.EnqueueRequestsFromMapFunc{ToRequests: deploymentToExperiment},
+		deploymentPredicate)
+	if err != nil {
+		return err
+	}
+
+	podPredicate := predicate.Funcs{
+		CreateFunc: func(event.CreateEvent) bool {
+			return false
+		},
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			oldPod, _ := e.ObjectOld.(*corev1.Pod)
+			newPod, _ := e.ObjectNew.(*corev1.Pod)
+			if oldPod == nil || newPod == nil ||
+				equality.Semantic.DeepEqual(oldPod.Status.ContainerStatuses, newPod.Status.ContainerStatuses) &&
+					equality.Semantic.DeepEqual(oldPod.Status.Conditions, newPod.Status.Conditions) {
+				return false
+			}
+
+			_, _, ok := r.iter8Adapter.DeploymentToExperiment(podWorkload(newPod), newPod.GetNamespace())
+			return ok
+		},
+		DeleteFunc: func(event.DeleteEvent) bool {
+			return false
+		},
+	}
+
+	podToExperiment := handler.ToRequestsFunc(
+		func(a handler.MapObject) []reconcile.Request {
+			pod, ok := a.Object.(*corev1.Pod)
+			if !ok {
+				return nil
+			}
+			experimentName, experimentNamespace, ok := r.iter8Adapter.DeploymentToExperiment(podWorkload(pod), pod.GetNamespace())
+			if !ok {
+				return nil
+			}
+			return []reconcile.Request{
+				{
+					NamespacedName: types.NamespacedName{
+						Name:      experimentName,
+						Namespace: experimentNamespace,
+					},
+				},
+			}
+		},
+	)
+
+	err = c.Watch(&source.Kind{Type: &corev1.Pod{}},
+		&handler.EnqueueRequestsFromMapFunc{ToRequests: podToExperiment},
+		podPredicate)
+	if err != nil {
+		return err
+	}
 
 	servicePredicate := predicate.Funcs{
 		CreateFunc: func(e event.CreateEvent) bool {
@@ -206,6 +292,12 @@ func add(mgr manager.Manager, r *ReconcileExperiment) error {
 		&handler.EnqueueRequestsFromMapFunc{ToRequests: serviceToExperiment},
 		servicePredicate)
 
+	// Watch for queued experiments to be started or updated
+	err = c.Watch(&source.Channel{Source: r.queueEvents}, &handler.EnqueueRequestForObject{})
+	if err != nil {
+		return err
+	}
+
 	// Watch for changes to Experiment
 	err = c.Watch(&source.Kind{Type: &iter8v1alpha2.Experiment{}}, &handler.EnqueueRequestForObject{},
 		// Ignore status update event
@@ -252,6 +344,10 @@ type ReconcileExperiment struct {
 	notificationCenter *iter8notifier.NotificationCenter
 	istioClient        istioclient.Interface
 	iter8Adapter       adapter.Interface
+	// events triggering reconcile of queued experiments
+	queueEvents chan event.GenericEvent
+	// whether experiments existing before the controller starts are registered in adapter
+	restored bool
 
 	router router.Interface
 	interState
@@ -263,11 +359,22 @@ type ReconcileExperiment struct {
 // +kubebuilder:rbac:groups=iter8.tools,resources=experiments/status,verbs=get;update;patch
 // +kubebuilder:rbac:groups=networking.istio.io,resources=destinationrules,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=networking.istio.io,resources=virtualservices,verbs=get;list;watch;create;update;patch;delete
-// +kubebuilder:rbac:groups=apps,resources=deployments,verbs=get;list;watch;update;patch;delete
+// +kubebuilder:rbac:groups=networking.istio.io,resources=serviceentries,verbs=get;list;watch
+// +kubebuilder:rbac:groups=networking.istio.io,resources=envoyfilters,verbs=get;list;watch;create;delete
+// +kubebuilder:rbac:groups=apps,resources=deployments,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=apps,resources=deployments/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=apps,resources=statefulsets,verbs=get;list;watch;delete
+// +kubebuilder:rbac:groups="",resources=pods,verbs=get;list;watch
+// +kubebuilder:rbac:groups=autoscaling,resources=horizontalpodautoscalers,verbs=get;list;watch;update;patch
 func (r *ReconcileExperiment) Reconcile(request reconcile.Request) (reconcile.Result, error) {
 	ctx := context.Background()
 
+	// experiments holding targets before restart are registered ahead of any other
+	if err := r.restoreAdapter(ctx); err != nil {
+		log.Error(err, "Fail to restore experiments")
+		return reconcile.Result{}, err
+	}
+
 	// Fetch the Experiment instance
 	instance := &iter8v1alpha2.Experiment{}
 	err := r.Get(ctx, request.NamespacedName, instance)
@@ -313,12 +420,19 @@ func (r *ReconcileExperiment) Reconcile(request reconcile.Request) (reconcile.Re
 		return reconcile.Result{}, nil
 	}
 
-	ctx, err = r.iter8Adapter.RegisterExperiment(ctx, instance)
+	// resolve baseline and candidates from label selectors
+	if err := r.selectTargets(ctx, instance); err != nil {
+		return r.endRequest(ctx, instance)
+	}
+
+	ctx, err = r.registerExperiment(ctx, instance)
 	if err != nil {
-		r.markTargetsError(ctx, instance, "%v", err)
 		return r.endRequest(ctx, instance)
 	}
 	ctx = r.syncExperiment(ctx, instance)
+	if instance.Status.Queued() {
+		r.markExperimentDequeued(ctx, instance, "")
+	}
 
 	if err := r.proceed(ctx, instance); err != nil {
 		log.Info("NotToProceed", "status", err.Error())
@@ -397,6 +511,9 @@ func (r *ReconcileExperiment) syncExperiment(context context.Context, instance *
 	if eas != nil && (eas.Refresh() || eas.Resume()) {
 		r.markRefresh()
 	}
+	if eas != nil && eas.Updated() {
+		r.markTemplateChange()
+	}
 
 	context = r.injectClients(context)
 	r.router = routing.GetRouter(context, instance)
@@ -0,0 +1,118 @@
+/*
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package experiment
+
+// This file contains functions used for deciding the winner of an experiment
+// with fixed-horizon frequentist tests.
+
+import (
+	"fmt"
+
+	"github.com/iter8-tools/iter8/pkg/analytics"
+	analyticsapi "github.com/iter8-tools/iter8/pkg/analytics/api/v1alpha2"
+	iter8v1alpha2 "github.com/iter8-tools/iter8/pkg/apis/iter8/v1alpha2"
+)
+
+// applyFrequentistAssessment overrides the winner assessment from analytics with the result of frequentist tests.
+// No winner is declared until every active version has reached the required sample size.
+// Once reached, the best candidate significantly better than baseline on the primary criterion wins;
+// baseline wins if there is no such candidate.
+// It is a no-op unless frequentist assessment method is specified.
+// An error is returned if the required sample size cannot be computed, in which case no winner is declared.
+func applyFrequentistAssessment(instance *iter8v1alpha2.Experiment) error {
+	if instance.Spec.GetAssessmentMethod() != iter8v1alpha2.AssessmentMethodFrequentist {
+		return nil
+	}
+
+	assessment := instance.Status.Assessment
+	assessment.RequiredSampleSize = nil
+	if assessment.Winner == nil || assessment.Winner.WinnerAssessment == nil {
+		return nil
+	}
+	winner := assessment.Winner
+	winner.WinnerFound = false
+
+	criterion := instance.Spec.GetPrimaryCriterion()
+	if criterion == nil {
+		return nil
+	}
+	baseline := criterionAssessment(&assessment.Baseline, criterion.Metric)
+	if baseline == nil || baseline.Statistics == nil || baseline.Statistics.Value == nil {
+		return nil
+	}
+	stdDev := float64(0)
+	if baseline.Statistics.StandardDeviation != nil {
+		stdDev = float64(*baseline.Statistics.StandardDeviation)
+	}
+
+	alpha := instance.Spec.GetAlpha()
+	required, err := analytics.RequiredSampleSize(instance.Spec.GetStatisticalTest(criterion.Metric),
+		float64(alpha), float64(instance.Spec.GetPower()), float64(instance.Spec.GetMinimumDetectableEffect()),
+		float64(*baseline.Statistics.Value), stdDev)
+	if err != nil {
+		return fmt.Errorf("Cannot compute required sample size for %s: %v", criterion.Metric, err)
+	}
+	assessment.RequiredSampleSize = &required
+
+	if assessment.Baseline.RequestCount < required {
+		return nil
+	}
+	for _, candidate := range assessment.Candidates {
+		if !candidate.Rollback && candidate.RequestCount < required {
+			return nil
+		}
+	}
+
+	lower := preferredDirection(instance, criterion.Metric) == preferredDirectionLower
+	best := &assessment.Baseline
+	bestValue := *baseline.Statistics.Value
+	var pValue *float32
+	for i := range assessment.Candidates {
+		candidate := &assessment.Candidates[i]
+		if candidate.Rollback {
+			continue
+		}
+		ca := criterionAssessment(candidate, criterion.Metric)
+		if ca == nil || ca.PValue == nil || *ca.PValue >= alpha ||
+			ca.Statistics == nil || ca.Statistics.Value == nil {
+			continue
+		}
+		value := *ca.Statistics.Value
+		if (lower && value < bestValue) || (!lower && value > bestValue) {
+			best, bestValue = candidate, value
+			p := *ca.PValue
+			pValue = &p
+		}
+	}
+
+	name := best.Name
+	winner.Name = &name
+	winner.Winner = best.ID
+	winner.Probability = best.WinProbability
+	winner.PValue = pValue
+	winner.WinnerFound = true
+	return nil
+}
+
+// criterionAssessment returns the assessment of the metric in a version, nil if not available
+func criterionAssessment(va *iter8v1alpha2.VersionAssessment, metric string) *analyticsapi.CriterionAssessment {
+	for i := range va.CriterionAssessments {
+		if va.CriterionAssessments[i].MetricID == metric {
+			return &va.CriterionAssessments[i]
+		}
+	}
+	return nil
+}
@@ -0,0 +1,96 @@
+/*
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package experiment
+
+import (
+	"testing"
+
+	analyticsv1alpha2 "github.com/iter8-tools/iter8/pkg/analytics/api/v1alpha2"
+	iter8v1alpha2 "github.com/iter8-tools/iter8/pkg/apis/iter8/v1alpha2"
+)
+
+func frequentistExperiment(stdDev *float32, count int32, pValue float32) *iter8v1alpha2.Experiment {
+	method := iter8v1alpha2.AssessmentMethodFrequentist
+	version := func(value float32, p *float32) analyticsv1alpha2.VersionAssessment {
+		return analyticsv1alpha2.VersionAssessment{
+			RequestCount:   count,
+			WinProbability: 60,
+			CriterionAssessments: []analyticsv1alpha2.CriterionAssessment{{
+				MetricID:   "latency",
+				PValue:     p,
+				Statistics: &analyticsv1alpha2.Statistics{Value: &value, StandardDeviation: stdDev},
+			}},
+		}
+	}
+	return &iter8v1alpha2.Experiment{
+		Spec: iter8v1alpha2.ExperimentSpec{
+			Criteria:   []iter8v1alpha2.Criterion{{Metric: "latency"}},
+			Assessment: &iter8v1alpha2.AssessmentConfig{Method: &method},
+		},
+		Status: iter8v1alpha2.ExperimentStatus{
+			Assessment: &iter8v1alpha2.Assessment{
+				Baseline: iter8v1alpha2.VersionAssessment{Name: "baseline", VersionAssessment: version(100, nil)},
+				Candidates: []iter8v1alpha2.VersionAssessment{
+					{Name: "candidate", VersionAssessment: version(110, &pValue)},
+				},
+				Winner: &iter8v1alpha2.WinnerAssessment{WinnerAssessment: &analyticsv1alpha2.WinnerAssessment{}},
+			},
+		},
+	}
+}
+
+func TestApplyFrequentistAssessment(t *testing.T) {
+	stdDev := float32(10)
+
+	instance := frequentistExperiment(nil, 1000, 0.01)
+	if err := applyFrequentistAssessment(instance); err == nil {
+		t.Error("expected error without standard deviation")
+	}
+	if instance.Status.IsWinnerFound() {
+		t.Error("winner found without required sample size")
+	}
+
+	instance = frequentistExperiment(&stdDev, 10, 0.01)
+	if err := applyFrequentistAssessment(instance); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if instance.Status.IsWinnerFound() {
+		t.Error("winner found before required sample size is reached")
+	}
+
+	instance = frequentistExperiment(&stdDev, 1000, 0.01)
+	if err := applyFrequentistAssessment(instance); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	winner := instance.Status.Assessment.Winner
+	if !winner.WinnerFound || *winner.Name != "candidate" {
+		t.Fatalf("got winner %v, want candidate", winner.Name)
+	}
+	if winner.PValue == nil || *winner.PValue != 0.01 {
+		t.Errorf("got p-value %v, want 0.01", winner.PValue)
+	}
+	if winner.Probability != 60 {
+		t.Errorf("got win probability %f, want 60", winner.Probability)
+	}
+
+	instance = frequentistExperiment(&stdDev, 1000, 0.2)
+	if err := applyFrequentistAssessment(instance); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if winner := instance.Status.Assessment.Winner; *winner.Name != "baseline" || winner.PValue != nil {
+		t.Errorf("got winner %s with p-value %v, want baseline without p-value", *winner.Name, winner.PValue)
+	}
+}
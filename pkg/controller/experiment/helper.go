@@ -28,6 +28,10 @@ type interState struct {
 	statusUpdate bool
 	refresh      bool
 	progress     bool
+	// pod template of some target is changed
+	templateChange bool
+	// waiting for cluster state that is not watched
+	requeue bool
 }
 
 func (r *ReconcileExperiment) initState() {
@@ -50,6 +54,22 @@ func (r *ReconcileExperiment) needRefresh() bool {
 	return r.interState.refresh
 }
 
+func (r *ReconcileExperiment) markTemplateChange() {
+	r.interState.templateChange = true
+}
+
+func (r *ReconcileExperiment) hasTemplateChange() bool {
+	return r.interState.templateChange
+}
+
+func (r *ReconcileExperiment) markRequeue() {
+	r.interState.requeue = true
+}
+
+func (r *ReconcileExperiment) needRequeue() bool {
+	return r.interState.requeue
+}
+
 func (r *ReconcileExperiment) markProgress() {
 	r.interState.progress = true
 }
@@ -80,23 +100,18 @@ func validUpdateErr(err error) bool {
 
 // overrideAssessment sets the assessment when experiment is being terminated
 func overrideAssessment(instance *iter8v1alpha2.Experiment) {
+	if len(instance.Spec.GetSegments()) > 0 {
+		syncSegments(instance)
+	}
+
 	// set onTermination strategy from manualOverrides if configured
 	if instance.Spec.Terminate() && instance.Spec.ManualOverride != nil {
 		onTermination := iter8v1alpha2.OnTerminationToBaseline
 		if len(instance.Spec.ManualOverride.TrafficSplit) > 0 {
 			trafficSplit := instance.Spec.ManualOverride.TrafficSplit
-			if ts, ok := trafficSplit[instance.Status.Assessment.Baseline.Name]; ok {
-				instance.Status.Assessment.Baseline.Weight = ts
-			} else {
-				instance.Status.Assessment.Baseline.Weight = 0
-			}
-
-			for i := range instance.Status.Assessment.Candidates {
-				if ts, ok := trafficSplit[instance.Status.Assessment.Candidates[i].Name]; ok {
-					instance.Status.Assessment.Candidates[i].Weight = ts
-				} else {
-					instance.Status.Assessment.Candidates[i].Weight = 0
-				}
+			applyTrafficSplit(instance.Status.Assessment, trafficSplit)
+			for i := range instance.Status.Segments {
+				applyTrafficSplit(&instance.Status.Segments[i].Assessment, trafficSplit)
 			}
 
 			onTermination = iter8v1alpha2.OnTerminationKeepLast
@@ -108,10 +123,36 @@ func overrideAssessment(instance *iter8v1alpha2.Experiment) {
 	}
 
 	// set final traffic status in assessment
-	assessment := instance.Status.Assessment
+	finalizeTraffic(instance, instance.Status.Assessment)
+
+	// segments end with their own winners
+	for i := range instance.Status.Segments {
+		finalizeTraffic(instance, &instance.Status.Segments[i].Assessment)
+	}
+}
+
+// applyTrafficSplit sets traffic of versions in the assessment by the split from manualOverrides
+func applyTrafficSplit(assessment *iter8v1alpha2.Assessment, trafficSplit map[string]int32) {
+	if ts, ok := trafficSplit[assessment.Baseline.Name]; ok {
+		assessment.Baseline.Weight = ts
+	} else {
+		assessment.Baseline.Weight = 0
+	}
+
+	for i := range assessment.Candidates {
+		if ts, ok := trafficSplit[assessment.Candidates[i].Name]; ok {
+			assessment.Candidates[i].Weight = ts
+		} else {
+			assessment.Candidates[i].Weight = 0
+		}
+	}
+}
+
+// finalizeTraffic sets final traffic status in the assessment by onTermination strategy
+func finalizeTraffic(instance *iter8v1alpha2.Experiment, assessment *iter8v1alpha2.Assessment) {
 	switch instance.Spec.GetOnTermination() {
 	case iter8v1alpha2.OnTerminationToWinner:
-		if instance.Status.IsWinnerFound() {
+		if assessment.IsWinnerFound() {
 			// all traffic to winner
 			if assessment.Winner.Winner == assessment.Baseline.ID {
 				assessment.Baseline.Weight = 100
@@ -147,5 +188,11 @@ func overrideAssessment(instance *iter8v1alpha2.Experiment) {
 		// do nothing
 	}
 
-	instance.Status.Assessment = assessment
+	// winner runs as baseline once promoted
+	if instance.Status.Promoted() {
+		assessment.Baseline.Weight = 100
+		for i := range assessment.Candidates {
+			assessment.Candidates[i].Weight = 0
+		}
+	}
 }
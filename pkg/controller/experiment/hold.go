@@ -0,0 +1,118 @@
+/*
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package experiment
+
+// This file contains functions used for holding traffic steady until every version
+// has collected the minimum number of samples.
+
+import (
+	"fmt"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	iter8v1alpha2 "github.com/iter8-tools/iter8/pkg/apis/iter8/v1alpha2"
+)
+
+// trafficHoldReason returns why traffic should be held steady, empty if it can be changed.
+// Only versions that have started receiving traffic and are not rolled back are checked;
+// versions without traffic are covered by noTrafficReason.
+func trafficHoldReason(instance *iter8v1alpha2.Experiment) string {
+	minCount := instance.Spec.GetMinRequestCount()
+	minTime, _ := instance.Spec.GetMinObservationTime()
+	if minCount <= 0 && minTime <= 0 {
+		return ""
+	}
+
+	now := time.Now()
+	check := func(va *iter8v1alpha2.VersionAssessment) string {
+		if va.TrafficStartTimestamp == nil {
+			return ""
+		}
+		if va.RequestCount < minCount {
+			return fmt.Sprintf("%s has served %d/%d requests", va.Name, va.RequestCount, minCount)
+		}
+		if observed := now.Sub(va.TrafficStartTimestamp.Time); observed < minTime {
+			return fmt.Sprintf("%s has been observed for %s/%s", va.Name, observed.Round(time.Second), minTime)
+		}
+		return ""
+	}
+
+	assessment := instance.Status.Assessment
+	if reason := check(&assessment.Baseline); reason != "" {
+		return reason
+	}
+	for i := range assessment.Candidates {
+		if assessment.Candidates[i].Rollback {
+			continue
+		}
+		if reason := check(&assessment.Candidates[i]); reason != "" {
+			return reason
+		}
+	}
+	return ""
+}
+
+// noTrafficReason returns why no winner can be declared while traffic may still change, empty if it can be declared.
+// A version that has not received any traffic has not served enough requests to be assessed,
+// although traffic to it is not held so that it can start receiving requests.
+func noTrafficReason(instance *iter8v1alpha2.Experiment) string {
+	minCount := instance.Spec.GetMinRequestCount()
+	minTime, _ := instance.Spec.GetMinObservationTime()
+	if minCount <= 0 && minTime <= 0 {
+		return ""
+	}
+
+	assessment := instance.Status.Assessment
+	if assessment.Baseline.TrafficStartTimestamp == nil {
+		return fmt.Sprintf("%s has not received traffic", assessment.Baseline.Name)
+	}
+	for _, candidate := range assessment.Candidates {
+		if !candidate.Rollback && candidate.TrafficStartTimestamp == nil {
+			return fmt.Sprintf("%s has not received traffic", candidate.Name)
+		}
+	}
+	return ""
+}
+
+// holdTraffic keeps the current traffic split, except that traffic to candidates to be rolled back goes to baseline
+// returns true if traffic split is changed
+func holdTraffic(instance *iter8v1alpha2.Experiment) bool {
+	updated := false
+	assessment := instance.Status.Assessment
+	for i, candidate := range assessment.Candidates {
+		if candidate.Rollback && candidate.Weight > 0 {
+			assessment.Baseline.Weight += candidate.Weight
+			assessment.Candidates[i].Weight = 0
+			updated = true
+		}
+	}
+	return updated
+}
+
+// markTrafficStart records the time when each version starts to receive traffic
+func markTrafficStart(instance *iter8v1alpha2.Experiment) {
+	assessment := instance.Status.Assessment
+	if assessment.Baseline.TrafficStartTimestamp == nil {
+		assessment.Baseline.TrafficStartTimestamp = instance.Status.StartTimestamp.DeepCopy()
+	}
+	now := metav1.Now()
+	for i, candidate := range assessment.Candidates {
+		if candidate.TrafficStartTimestamp == nil && candidate.Weight > 0 {
+			assessment.Candidates[i].TrafficStartTimestamp = &now
+		}
+	}
+}
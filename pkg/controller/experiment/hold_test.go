@@ -0,0 +1,88 @@
+/*
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package experiment
+
+import (
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	analyticsv1alpha2 "github.com/iter8-tools/iter8/pkg/analytics/api/v1alpha2"
+	iter8v1alpha2 "github.com/iter8-tools/iter8/pkg/apis/iter8/v1alpha2"
+)
+
+func holdVersion(name string, count int32, since *time.Duration, rollback bool) iter8v1alpha2.VersionAssessment {
+	va := iter8v1alpha2.VersionAssessment{
+		Name:              name,
+		Rollback:          rollback,
+		VersionAssessment: analyticsv1alpha2.VersionAssessment{RequestCount: count},
+	}
+	if since != nil {
+		start := metav1.NewTime(time.Now().Add(-*since))
+		va.TrafficStartTimestamp = &start
+	}
+	return va
+}
+
+func TestTrafficHoldReason(t *testing.T) {
+	minute, hour := time.Minute, time.Hour
+	cases := []struct {
+		name          string
+		minCount      int32
+		minTime       string
+		baseline      iter8v1alpha2.VersionAssessment
+		candidate     iter8v1alpha2.VersionAssessment
+		wantHold      bool
+		wantNoTraffic bool
+	}{
+		{"no minimum", 0, "0s", holdVersion("baseline", 0, &hour, false), holdVersion("candidate", 0, nil, false),
+			false, false},
+		{"enough requests", 100, "0s", holdVersion("baseline", 200, &hour, false), holdVersion("candidate", 100, &hour, false),
+			false, false},
+		{"candidate short of requests", 100, "0s", holdVersion("baseline", 200, &hour, false), holdVersion("candidate", 10, &hour, false),
+			true, false},
+		{"candidate short of observation time", 0, "5m", holdVersion("baseline", 200, &hour, false), holdVersion("candidate", 10, &minute, false),
+			true, false},
+		{"candidate without traffic", 100, "0s", holdVersion("baseline", 200, &hour, false), holdVersion("candidate", 0, nil, false),
+			false, true},
+		{"rolled back candidate", 100, "5m", holdVersion("baseline", 200, &hour, false), holdVersion("candidate", 0, nil, true),
+			false, false},
+	}
+	for _, c := range cases {
+		minCount, minTime := c.minCount, c.minTime
+		instance := &iter8v1alpha2.Experiment{
+			Spec: iter8v1alpha2.ExperimentSpec{
+				TrafficControl: &iter8v1alpha2.TrafficControl{
+					MinRequestCount:    &minCount,
+					MinObservationTime: &minTime,
+				},
+			},
+			Status: iter8v1alpha2.ExperimentStatus{
+				Assessment: &iter8v1alpha2.Assessment{
+					Baseline:   c.baseline,
+					Candidates: []iter8v1alpha2.VersionAssessment{c.candidate},
+				},
+			},
+		}
+		if got := trafficHoldReason(instance) != ""; got != c.wantHold {
+			t.Errorf("%s: got traffic held %t, want %t", c.name, got, c.wantHold)
+		}
+		if got := noTrafficReason(instance) != ""; got != c.wantNoTraffic {
+			t.Errorf("%s: got no traffic %t, want %t", c.name, got, c.wantNoTraffic)
+		}
+	}
+}
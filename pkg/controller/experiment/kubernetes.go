@@ -19,9 +19,11 @@ import (
 	"context"
 	"time"
 
+	"k8s.io/apimachinery/pkg/api/errors"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	iter8v1alpha2 "github.com/iter8-tools/iter8/pkg/apis/iter8/v1alpha2"
+	"github.com/iter8-tools/iter8/pkg/controller/experiment/targets"
 	"github.com/iter8-tools/iter8/pkg/controller/experiment/util"
 )
 
@@ -36,10 +38,41 @@ func (r *ReconcileExperiment) syncKubernetes(context context.Context, instance *
 		return r.endRequest(context, instance)
 	}
 
+	// add or retire candidates changed during the experiment
+	if err := r.syncCandidates(context, instance); err != nil {
+		return r.endRequest(context, instance)
+	}
+
 	// detect targets of this experiment if necessary
 	if r.toDetectTargets(context, instance) {
 		found, err := r.detectTargets(context, instance)
-		if err != nil || !found {
+		if err != nil {
+			if !targets.Watched(instance.Spec.Service.Kind) && !instance.Spec.Terminate() && errors.IsNotFound(err) {
+				// targets of generic kind are polled until they are found;
+				// other errors, e.g. missing access to the kind, fail the experiment
+				r.endRequest(context, instance)
+				return reconcile.Result{RequeueAfter: iterationInterval(instance)}, nil
+			}
+			return r.endRequest(context, instance)
+		}
+		if !found && !instance.Spec.Terminate() {
+			// wait for candidates to become available
+			r.endRequest(context, instance)
+			interval, _ := instance.Spec.GetInterval()
+			return reconcile.Result{RequeueAfter: interval}, nil
+		}
+	}
+
+	// roll back candidates with unhealthy pods without waiting for next iteration
+	if instance.Status.TargetsFound() && !instance.Spec.Terminate() {
+		if err := r.checkPodHealth(context, instance); err != nil {
+			return r.endRequest(context, instance)
+		}
+	}
+
+	// react to change of pod template of targets
+	if r.hasTemplateChange() && instance.Status.TargetsFound() {
+		if err := r.handleTemplateChange(context, instance); err != nil {
 			return r.endRequest(context, instance)
 		}
 	}
@@ -51,8 +84,10 @@ func (r *ReconcileExperiment) syncKubernetes(context context.Context, instance *
 		}
 	}
 
-	// complete experiment
-	if r.toComplete(context, instance) {
+	// complete experiment, unless traffic is to be shifted to winner by ramp, promotion is baking,
+	// winner is being promoted to baseline, or versions receiving final traffic are being scaled up
+	if r.toComplete(context, instance) && !r.toRamp(context, instance) &&
+		!r.toBake(context, instance) && !r.toPromote(context, instance) && !r.toScaleFinal(context, instance) {
 		err := r.completeExperiment(context, instance)
 		if err != nil {
 			// retry
@@ -66,18 +101,28 @@ func (r *ReconcileExperiment) syncKubernetes(context context.Context, instance *
 	if r.hasProgress() {
 		r.updateIteration(instance)
 		r.endRequest(context, instance)
-		interval, _ := instance.Spec.GetInterval()
+		interval := iterationInterval(instance)
 		log.Info("Requeue for next iteration", "interval", interval, "iteration", *instance.Status.CurrentIteration)
 		return reconcile.Result{RequeueAfter: interval}, nil
 	}
 
+	// poll cluster state that does not trigger reconcile
+	if r.needRequeue() {
+		r.endRequest(context, instance)
+		return reconcile.Result{RequeueAfter: iterationInterval(instance)}, nil
+	}
+
 	log.Info("Request not processed")
 	return r.endRequest(context, instance)
 }
 
 func (r *ReconcileExperiment) finalize(context context.Context, instance *iter8v1alpha2.Experiment) (reconcile.Result, error) {
 	util.Logger(context).Info("finalizing")
-	if !instance.Status.ExperimentCompleted() {
+	if instance.Status.Queued() {
+		// queued experiment has not changed targets or routing rules
+		r.iter8Adapter.RemoveExperiment(instance)
+		r.notifyQueued()
+	} else if !instance.Status.ExperimentCompleted() {
 		instance.Spec.ManualOverride = &iter8v1alpha2.ManualOverride{
 			Action: iter8v1alpha2.ActionTerminate,
 		}
@@ -104,14 +149,25 @@ func (r *ReconcileExperiment) toProcessIteration(context context.Context, instan
 	}
 
 	now := time.Now()
-	interval, _ := instance.Spec.GetInterval()
+	interval := iterationInterval(instance)
 
 	return instance.Status.LastUpdateTime == nil || now.After(instance.Status.LastUpdateTime.Add(interval))
 }
 
 func (r *ReconcileExperiment) toComplete(context context.Context, instance *iter8v1alpha2.Experiment) bool {
 	return instance.Spec.GetMaxIterations() <= *instance.Status.CurrentIteration ||
-		instance.Spec.Terminate()
+		instance.Spec.Terminate() || instance.Status.EarlyStopped() ||
+		instance.Status.GetBlueGreenPhase() == iter8v1alpha2.BlueGreenPhaseSwitched
+}
+
+// returns duration between iterations, which is the ramp interval once traffic ramp to winner starts
+func iterationInterval(instance *iter8v1alpha2.Experiment) time.Duration {
+	if instance.Status.Ramp != nil {
+		interval, _ := instance.Spec.GetRampInterval()
+		return interval
+	}
+	interval, _ := instance.Spec.GetInterval()
+	return interval
 }
 
 func (r *ReconcileExperiment) endRequest(context context.Context, instance *iter8v1alpha2.Experiment) (reconcile.Result, error) {
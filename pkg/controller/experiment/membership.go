@@ -0,0 +1,113 @@
+/*
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package experiment
+
+// This file contains functions used for adding and retiring candidates during the experiment.
+
+import (
+	"context"
+	"strings"
+
+	iter8v1alpha2 "github.com/iter8-tools/iter8/pkg/apis/iter8/v1alpha2"
+	"github.com/iter8-tools/iter8/pkg/controller/experiment/targets"
+	"github.com/iter8-tools/iter8/pkg/controller/experiment/util"
+)
+
+// syncCandidates aligns candidates in assessment with candidates of the experiment
+// New candidates start with weight 0 once they exist; traffic of retired candidates is drained
+// to baseline, and their subsets are removed in a later pass
+// returns non-nil error if reconcile process should be terminated right after this function
+func (r *ReconcileExperiment) syncCandidates(context context.Context, instance *iter8v1alpha2.Experiment) error {
+	assessment := instance.Status.Assessment
+	if assessment == nil || instance.Spec.Terminate() || instance.Status.Ramp != nil ||
+		instance.Status.Bake != nil || instance.Status.Promotion != nil {
+		return nil
+	}
+
+	desired := make(map[string]bool)
+	added := []string{}
+	for _, name := range instance.GetCandidates() {
+		desired[name] = true
+		if assessment.CandidateIndex(name) >= 0 {
+			continue
+		}
+		// candidates added after targets are found join the experiment once they exist
+		if instance.Status.TargetsFound() &&
+			targets.InitWithCandidates(instance, r.Client, []string{name}).GetCandidates(context) != nil {
+			util.Logger(context).Info("CandidatePending", "name", name)
+			continue
+		}
+		added = append(added, name)
+	}
+	// retired candidates are drained in one pass and removed in a later one,
+	// so that routing rules stop sending traffic to their subsets before the subsets are removed
+	draining := []string{}
+	retired := []string{}
+	for _, candidate := range assessment.Candidates {
+		if candidate.Retired {
+			retired = append(retired, candidate.Name)
+		} else if !desired[candidate.Name] {
+			draining = append(draining, candidate.Name)
+		}
+	}
+	if len(added) == 0 && len(draining) == 0 && len(retired) == 0 {
+		return nil
+	}
+
+	for _, name := range draining {
+		assessment.DrainCandidate(name)
+	}
+	for _, name := range retired {
+		assessment.RetireCandidate(name)
+	}
+	for _, name := range added {
+		assessment.AddCandidate(name)
+	}
+	r.markStatusUpdate()
+
+	// routing rules are set up with all candidates once targets are found
+	if !instance.Status.TargetsFound() {
+		return nil
+	}
+
+	if err := r.router.UpdateRouteWithTrafficUpdate(context, instance); err != nil {
+		r.markRoutingRulesError(context, instance, "%v", err)
+		return err
+	}
+	if len(added) > 0 || len(retired) > 0 {
+		targetsHandler := targets.Init(instance, r.Client)
+		if err := targetsHandler.GetBaseline(context); err != nil {
+			r.markTargetsError(context, instance, "Missing Baseline")
+			return err
+		}
+		if err := targetsHandler.GetCandidates(context); err != nil {
+			r.markTargetsError(context, instance, "Missing Candidate")
+			return err
+		}
+		if err := r.router.RefreshSubsets(context, instance, targetsHandler.Baseline, targetsHandler.Candidates); err != nil {
+			r.markRoutingRulesError(context, instance, "Fail in refreshing subsets: %v", err)
+			return err
+		}
+	}
+	if len(draining) > 0 {
+		// subsets of drained candidates are removed in the next pass
+		r.markRequeue()
+	}
+
+	r.markTrafficUpdate(context, instance, "Candidates added: [%s], draining: [%s], retired: [%s]",
+		strings.Join(added, ", "), strings.Join(draining, ", "), strings.Join(retired, ", "))
+	return nil
+}
@@ -0,0 +1,200 @@
+/*
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package experiment
+
+// This file contains functions used for rolling back candidates with unhealthy pods.
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	iter8v1alpha2 "github.com/iter8-tools/iter8/pkg/apis/iter8/v1alpha2"
+	"github.com/iter8-tools/iter8/pkg/controller/experiment/targets"
+	"github.com/iter8-tools/iter8/pkg/controller/experiment/util"
+)
+
+// checkPodHealth cuts off traffic to candidates with unhealthy pods immediately, without waiting for analytics
+// returns non-nil error if reconcile process should be terminated right after this function
+func (r *ReconcileExperiment) checkPodHealth(context context.Context, instance *iter8v1alpha2.Experiment) error {
+	if instance.Spec.Service.Kind == "Service" || instance.Spec.Service.Kind == "ServiceEntry" {
+		return nil
+	}
+
+	assessment := instance.Status.Assessment
+	updated := false
+	for i := range assessment.Candidates {
+		candidate := &assessment.Candidates[i]
+		if candidate.Unhealthy {
+			continue
+		}
+		reason, err := r.unhealthyReason(context, instance, candidate.Name)
+		if err != nil {
+			util.Logger(context).Error(err, "Error when checking pod health", "candidate", candidate.Name)
+			continue
+		}
+		if reason == "" {
+			continue
+		}
+
+		candidate.Unhealthy = true
+		candidate.Rollback = true
+		assessment.Baseline.Weight += candidate.Weight
+		candidate.Weight = 0
+		updated = true
+		r.markCandidateUnhealthy(context, instance, "Candidate %s rolled back: %s", candidate.Name, reason)
+	}
+
+	if !updated {
+		return nil
+	}
+	r.scaleToTraffic(context, instance)
+	if err := r.router.UpdateRouteWithTrafficUpdate(context, instance); err != nil {
+		r.markRoutingRulesError(context, instance, "%v", err)
+		return err
+	}
+	r.markTrafficUpdate(context, instance, "Traffic: %s", instance.Status.TrafficToString())
+	return nil
+}
+
+// unhealthyReason checks pods of a candidate, and returns why they are unhealthy; empty if healthy
+// Pods are selected by labels in pod template of the candidate, so that pods of any workload kind are checked
+func (r *ReconcileExperiment) unhealthyReason(context context.Context, instance *iter8v1alpha2.Experiment, name string) (string, error) {
+	targetsHandler := targets.InitWithCandidates(instance, r.Client, []string{name})
+	if err := targetsHandler.GetCandidates(context); err != nil {
+		return "", client.IgnoreNotFound(err)
+	}
+	obj := targetsHandler.Candidates[0]
+	podLabels, err := targets.PodTemplateLabels(obj, instance.Spec.Service.GetPodTemplateLabelsPath())
+	if err != nil {
+		return "", err
+	}
+	accessor, err := meta.Accessor(obj)
+	if err != nil {
+		return "", err
+	}
+
+	pods := &corev1.PodList{}
+	if err := r.List(context, pods, client.InNamespace(accessor.GetNamespace()), client.MatchingLabels(podLabels)); err != nil {
+		return "", err
+	}
+
+	since := time.Time{}
+	if instance.Status.StartTimestamp != nil {
+		since = instance.Status.StartTimestamp.Time
+	}
+	threshold := instance.Spec.GetRestartThreshold()
+	readinessTimeout, _ := instance.Spec.GetReadinessTimeout()
+	for i := range pods.Items {
+		restarts, recorded := restartsBefore(instance, &pods.Items[i], since)
+		if recorded {
+			r.markStatusUpdate()
+		}
+		if reason := podUnhealthyReason(&pods.Items[i], threshold, readinessTimeout, since, restarts); reason != "" {
+			return reason, nil
+		}
+	}
+	return "", nil
+}
+
+// restartsBefore returns restart counts of containers of the pod before since, keyed by container name
+// Counts of pods created before since are recorded in status of the experiment when first seen; pods created later have none
+// The returned bool tells whether new counts are recorded
+func restartsBefore(instance *iter8v1alpha2.Experiment, pod *corev1.Pod, since time.Time) (map[string]int32, bool) {
+	out := make(map[string]int32)
+	if since.IsZero() || !pod.CreationTimestamp.Time.Before(since) {
+		return out, false
+	}
+
+	recorded := false
+	for _, cs := range pod.Status.ContainerStatuses {
+		key := pod.Name + "/" + cs.Name
+		count, ok := instance.Status.RestartCounts[key]
+		if !ok {
+			if instance.Status.RestartCounts == nil {
+				instance.Status.RestartCounts = make(map[string]int32)
+			}
+			count = cs.RestartCount
+			instance.Status.RestartCounts[key] = count
+			recorded = true
+		}
+		out[cs.Name] = count
+	}
+	return out, recorded
+}
+
+// podUnhealthyReason returns why the pod is unhealthy; empty if healthy
+// Containers OOMKilled before since, e.g. before the experiment started, are not counted,
+// neither are restarts given by container name, counted before since
+func podUnhealthyReason(pod *corev1.Pod, threshold int32, readinessTimeout time.Duration, since time.Time,
+	restarts map[string]int32) string {
+	if pod.DeletionTimestamp != nil {
+		return ""
+	}
+
+	for _, cs := range pod.Status.ContainerStatuses {
+		if cs.State.Waiting != nil && cs.State.Waiting.Reason == "CrashLoopBackOff" {
+			return fmt.Sprintf("container %s of pod %s in CrashLoopBackOff", cs.Name, pod.Name)
+		}
+		if oomKilledSince(cs.State.Terminated, since) || oomKilledSince(cs.LastTerminationState.Terminated, since) {
+			return fmt.Sprintf("container %s of pod %s OOMKilled", cs.Name, pod.Name)
+		}
+		if count := cs.RestartCount - restarts[cs.Name]; count > threshold {
+			return fmt.Sprintf("container %s of pod %s restarted %d times", cs.Name, pod.Name, count)
+		}
+	}
+
+	if pod.Status.Phase == corev1.PodRunning {
+		for _, c := range pod.Status.Conditions {
+			if c.Type == corev1.PodReady && c.Status == corev1.ConditionFalse &&
+				time.Since(c.LastTransitionTime.Time) > readinessTimeout {
+				return fmt.Sprintf("pod %s not ready for more than %s", pod.Name, readinessTimeout)
+			}
+		}
+	}
+	return ""
+}
+
+// oomKilledSince tells whether the container termination is an OOMKill finished after since
+func oomKilledSince(terminated *corev1.ContainerStateTerminated, since time.Time) bool {
+	return terminated != nil && terminated.Reason == "OOMKilled" && !terminated.FinishedAt.Time.Before(since)
+}
+
+// podWorkload returns name of the deployment or statefulset owning the pod; empty if the pod is owned by neither
+func podWorkload(pod *corev1.Pod) string {
+	for _, ref := range pod.OwnerReferences {
+		if ref.Kind == "StatefulSet" {
+			return ref.Name
+		}
+	}
+
+	hash, ok := pod.Labels[appsv1.DefaultDeploymentUniqueLabelKey]
+	if !ok {
+		return ""
+	}
+	for _, ref := range pod.OwnerReferences {
+		if ref.Kind == "ReplicaSet" && strings.HasSuffix(ref.Name, "-"+hash) {
+			return strings.TrimSuffix(ref.Name, "-"+hash)
+		}
+	}
+	return ""
+}
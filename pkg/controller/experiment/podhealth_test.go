@@ -0,0 +1,135 @@
+/*
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package experiment
+
+import (
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	iter8v1alpha2 "github.com/iter8-tools/iter8/pkg/apis/iter8/v1alpha2"
+)
+
+func TestPodUnhealthyReason(t *testing.T) {
+	start := time.Now().Add(-10 * time.Minute)
+	before := metav1.NewTime(start.Add(-time.Minute))
+	after := metav1.NewTime(start.Add(time.Minute))
+	now := metav1.Now()
+
+	tests := []struct {
+		name      string
+		phase     corev1.PodPhase
+		status    corev1.ContainerStatus
+		ready     *metav1.Time
+		deleted   bool
+		restarts  int32
+		unhealthy bool
+	}{
+		{name: "healthy", phase: corev1.PodRunning},
+		{name: "crash loop", phase: corev1.PodRunning, unhealthy: true,
+			status: corev1.ContainerStatus{State: corev1.ContainerState{
+				Waiting: &corev1.ContainerStateWaiting{Reason: "CrashLoopBackOff"}}}},
+		{name: "oom killed after start", phase: corev1.PodRunning, unhealthy: true,
+			status: corev1.ContainerStatus{State: corev1.ContainerState{
+				Terminated: &corev1.ContainerStateTerminated{Reason: "OOMKilled", FinishedAt: after}}}},
+		{name: "last oom killed after start", phase: corev1.PodRunning, unhealthy: true,
+			status: corev1.ContainerStatus{LastTerminationState: corev1.ContainerState{
+				Terminated: &corev1.ContainerStateTerminated{Reason: "OOMKilled", FinishedAt: after}}}},
+		{name: "last oom killed before start", phase: corev1.PodRunning,
+			status: corev1.ContainerStatus{LastTerminationState: corev1.ContainerState{
+				Terminated: &corev1.ContainerStateTerminated{Reason: "OOMKilled", FinishedAt: before}}}},
+		{name: "restarts within threshold", phase: corev1.PodRunning,
+			status: corev1.ContainerStatus{RestartCount: 3}},
+		{name: "restarts over threshold", phase: corev1.PodRunning, unhealthy: true,
+			status: corev1.ContainerStatus{RestartCount: 4}},
+		{name: "restarts before start", phase: corev1.PodRunning, restarts: 4,
+			status: corev1.ContainerStatus{RestartCount: 5}},
+		{name: "restarts over threshold since start", phase: corev1.PodRunning, restarts: 4, unhealthy: true,
+			status: corev1.ContainerStatus{RestartCount: 8}},
+		{name: "not ready too long", phase: corev1.PodRunning, ready: &before, unhealthy: true},
+		{name: "not ready recently", phase: corev1.PodRunning, ready: &now},
+		{name: "not ready while pending", phase: corev1.PodPending, ready: &before},
+		{name: "terminating", phase: corev1.PodRunning, deleted: true,
+			status: corev1.ContainerStatus{State: corev1.ContainerState{
+				Waiting: &corev1.ContainerStateWaiting{Reason: "CrashLoopBackOff"}}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pod := &corev1.Pod{}
+			pod.Name = "reviews-v2-abc"
+			pod.Status.Phase = tt.phase
+			tt.status.Name = "reviews"
+			pod.Status.ContainerStatuses = []corev1.ContainerStatus{tt.status}
+			if tt.ready != nil {
+				pod.Status.Conditions = []corev1.PodCondition{{
+					Type:               corev1.PodReady,
+					Status:             corev1.ConditionFalse,
+					LastTransitionTime: *tt.ready,
+				}}
+			}
+			if tt.deleted {
+				pod.DeletionTimestamp = &now
+			}
+			reason := podUnhealthyReason(pod, 3, 2*time.Minute, start, map[string]int32{"reviews": tt.restarts})
+			if (reason != "") != tt.unhealthy {
+				t.Errorf("podUnhealthyReason() = %q, want unhealthy %v", reason, tt.unhealthy)
+			}
+		})
+	}
+}
+
+func TestRestartsBefore(t *testing.T) {
+	start := time.Now().Add(-10 * time.Minute)
+	before := metav1.NewTime(start.Add(-time.Minute))
+	after := metav1.NewTime(start.Add(time.Minute))
+
+	tests := []struct {
+		name         string
+		created      metav1.Time
+		since        time.Time
+		recorded     map[string]int32
+		want         int32
+		wantRecorded bool
+	}{
+		{name: "pod created before start", created: before, since: start, want: 5, wantRecorded: true},
+		{name: "pod seen before", created: before, since: start,
+			recorded: map[string]int32{"reviews-v2-abc/reviews": 2}, want: 2},
+		{name: "pod created after start", created: after, since: start},
+		{name: "experiment not started", created: before},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			instance := &iter8v1alpha2.Experiment{}
+			instance.Status.RestartCounts = tt.recorded
+			pod := &corev1.Pod{}
+			pod.Name = "reviews-v2-abc"
+			pod.CreationTimestamp = tt.created
+			pod.Status.ContainerStatuses = []corev1.ContainerStatus{{Name: "reviews", RestartCount: 5}}
+
+			restarts, recorded := restartsBefore(instance, pod, tt.since)
+			if restarts["reviews"] != tt.want || recorded != tt.wantRecorded {
+				t.Errorf("restartsBefore() = %d, %v, want %d, %v", restarts["reviews"], recorded, tt.want, tt.wantRecorded)
+			}
+			if reason := podUnhealthyReason(pod, 3, 2*time.Minute, tt.since, restarts); (reason != "") != (tt.want == 0) {
+				t.Errorf("podUnhealthyReason() = %q, want unhealthy %v", reason, tt.want == 0)
+			}
+		})
+	}
+}
@@ -0,0 +1,177 @@
+/*
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package experiment
+
+// This file contains functions used for promoting the winner to baseline in place.
+
+import (
+	"context"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	iter8v1alpha2 "github.com/iter8-tools/iter8/pkg/apis/iter8/v1alpha2"
+	"github.com/iter8-tools/iter8/pkg/controller/experiment/util"
+)
+
+// toPromote tells whether the experiment should wait for promotion of the winner before it completes.
+// Promotion copies pod template of the winner onto baseline, waits for the rollout of baseline,
+// routes all traffic to baseline and then deletes or scales down the winner.
+// Promotion is retried in each interval until progress deadline, after which it fails and traffic stays with the winner.
+func (r *ReconcileExperiment) toPromote(context context.Context, instance *iter8v1alpha2.Experiment) bool {
+	if instance.Spec.Promotion == nil || instance.Spec.Terminate() || instance.Status.Promoted() {
+		return false
+	}
+
+	if instance.Status.Promotion == nil {
+		if instance.Spec.GetOnTermination() != iter8v1alpha2.OnTerminationToWinner {
+			return false
+		}
+		winner := currentWinner(instance)
+		if winner == nil || winner.ID == instance.Status.Assessment.Baseline.ID {
+			return false
+		}
+
+		now := metav1.Now()
+		instance.Status.Promotion = &iter8v1alpha2.PromotionStatus{
+			Phase:          iter8v1alpha2.PromotionPhaseUpdating,
+			Winner:         winner.Name,
+			StartTimestamp: &now,
+		}
+		r.markStatusUpdate()
+	}
+	promotion := instance.Status.Promotion
+
+	deadline, _ := instance.Spec.GetProgressDeadline()
+	if time.Now().After(promotion.StartTimestamp.Add(deadline)) {
+		promotion.Phase = iter8v1alpha2.PromotionPhaseFailed
+		instance.Spec.ManualOverride = &iter8v1alpha2.ManualOverride{
+			Action: iter8v1alpha2.ActionTerminate,
+			TrafficSplit: map[string]int32{
+				promotion.Winner: 100,
+			},
+		}
+		util.Logger(context).Info("PromotionFailed", "winner", promotion.Winner, "progressDeadline", deadline)
+		r.markStatusUpdate()
+		return false
+	}
+
+	if promotion.Phase == iter8v1alpha2.PromotionPhaseUpdating {
+		if err := r.updateBaseline(context, instance, promotion.Winner); err != nil {
+			// retried without pausing the experiment
+			util.Logger(context).Error(err, "Error when promoting winner", "winner", promotion.Winner)
+			r.markIterationUpdate(context, instance, "Promotion: fail to update baseline %s: %v", instance.GetBaseline(), err)
+			r.markRequeue()
+			return true
+		}
+		promotion.Phase = iter8v1alpha2.PromotionPhaseRollingOut
+		r.markIterationUpdate(context, instance, "Promotion: rolling out %s to baseline %s", promotion.Winner, instance.GetBaseline())
+		r.markRequeue()
+		return true
+	}
+
+	baseline := &appsv1.Deployment{}
+	if err := r.Get(context, instance.VersionNamespacedName(instance.GetBaseline()), baseline); err != nil {
+		r.markIterationUpdate(context, instance, "Promotion: fail to get baseline %s: %v", instance.GetBaseline(), err)
+		r.markRequeue()
+		return true
+	}
+	if !rolledOut(baseline) {
+		r.markIterationUpdate(context, instance, "Promotion: waiting for rollout of baseline %s", instance.GetBaseline())
+		r.markRequeue()
+		return true
+	}
+
+	// baseline serves all traffic after promotion
+	if instance.Status.Scaling != nil {
+		desired := desiredReplicas(instance, 100)
+		available, err := r.scaleVersion(context, instance, instance.GetBaseline(), desired, true)
+		if err != nil || available < desired {
+			r.markIterationUpdate(context, instance, "Promotion: waiting for baseline %s to scale up", instance.GetBaseline())
+			r.markRequeue()
+			return true
+		}
+	}
+
+	// all traffic to promoted baseline
+	for _, va := range allVersions(instance) {
+		va.Weight = 0
+	}
+	instance.Status.Assessment.Baseline.Weight = 100
+	if err := r.router.UpdateRouteWithTrafficUpdate(context, instance); err != nil {
+		r.markRoutingRulesError(context, instance, "%v", err)
+		r.markRequeue()
+		return true
+	}
+	r.markTrafficUpdate(context, instance, "Traffic: %s", instance.Status.TrafficToString())
+
+	if err := r.retireCandidate(context, instance, promotion.Winner); err != nil {
+		util.Logger(context).Error(err, "Error when retiring promoted candidate", "candidate", promotion.Winner)
+	}
+
+	promotion.Phase = iter8v1alpha2.PromotionPhaseCompleted
+	r.markStatusUpdate()
+	return false
+}
+
+// updateBaseline copies pod template of the winner onto baseline, preserving labels of baseline
+func (r *ReconcileExperiment) updateBaseline(context context.Context, instance *iter8v1alpha2.Experiment, winnerName string) error {
+	winner := &appsv1.Deployment{}
+	if err := r.Get(context, instance.VersionNamespacedName(winnerName), winner); err != nil {
+		return err
+	}
+	baseline := &appsv1.Deployment{}
+	if err := r.Get(context, instance.VersionNamespacedName(instance.GetBaseline()), baseline); err != nil {
+		return err
+	}
+
+	labels := baseline.Spec.Template.Labels
+	baseline.Spec.Template = *winner.Spec.Template.DeepCopy()
+	baseline.Spec.Template.Labels = labels
+
+	return r.Update(context, baseline)
+}
+
+// retireCandidate deletes or scales down the promoted candidate
+func (r *ReconcileExperiment) retireCandidate(context context.Context, instance *iter8v1alpha2.Experiment, name string) error {
+	candidate := &appsv1.Deployment{}
+	if err := r.Get(context, instance.VersionNamespacedName(name), candidate); err != nil {
+		return client.IgnoreNotFound(err)
+	}
+
+	switch instance.Spec.GetCandidateAction() {
+	case iter8v1alpha2.CandidateActionScaleDown:
+		replicas := int32(0)
+		candidate.Spec.Replicas = &replicas
+		return r.Update(context, candidate)
+	default:
+		return client.IgnoreNotFound(r.Delete(context, candidate))
+	}
+}
+
+// rolledOut tells whether all replicas of the deployment run the latest pod template
+func rolledOut(d *appsv1.Deployment) bool {
+	replicas := int32(1)
+	if d.Spec.Replicas != nil {
+		replicas = *d.Spec.Replicas
+	}
+	return d.Status.ObservedGeneration >= d.Generation &&
+		d.Status.UpdatedReplicas == replicas &&
+		d.Status.Replicas == replicas &&
+		d.Status.AvailableReplicas == replicas
+}
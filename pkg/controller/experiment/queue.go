@@ -0,0 +1,77 @@
+/*
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package experiment
+
+// This file contains functions used for queueing experiments on busy targets.
+
+import (
+	"context"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+
+	iter8v1alpha2 "github.com/iter8-tools/iter8/pkg/apis/iter8/v1alpha2"
+	"github.com/iter8-tools/iter8/pkg/controller/experiment/adapter"
+)
+
+// queueEventBufferSize is the capacity of channel of events triggering reconcile of queued experiments
+const queueEventBufferSize = 1024
+
+// registerExperiment registers the experiment in adapter
+// An experiment whose targets are involved in other experiments is queued, and started
+// once they are released; returns non-nil error if the experiment is not registered
+func (r *ReconcileExperiment) registerExperiment(ctx context.Context, instance *iter8v1alpha2.Experiment) (context.Context, error) {
+	ctx, err := r.iter8Adapter.RegisterExperiment(ctx, instance)
+	if qerr, ok := err.(*adapter.QueuedError); ok {
+		r.markExperimentQueued(ctx, instance, qerr.Position, "Position %d: %v", qerr.Position, err)
+	} else if err != nil {
+		r.markTargetsError(ctx, instance, "%v", err)
+	}
+	return ctx, err
+}
+
+// restoreAdapter registers experiments in adapter once after the controller starts, before any experiment is reconciled,
+// since registrations and the queue of adapter are not persisted
+// returns non-nil error if experiments cannot be listed, so that restore is retried
+func (r *ReconcileExperiment) restoreAdapter(ctx context.Context) error {
+	if r.restored {
+		return nil
+	}
+	experiments := &iter8v1alpha2.ExperimentList{}
+	if err := r.List(ctx, experiments); err != nil {
+		return err
+	}
+	r.iter8Adapter.Restore(ctx, experiments.Items)
+	r.restored = true
+	return nil
+}
+
+// notifyQueued triggers reconcile of queued experiments, so that they start in order of creation
+// if their targets are released, or update their positions otherwise
+func (r *ReconcileExperiment) notifyQueued() {
+	for _, nn := range r.iter8Adapter.QueuedExperiments() {
+		instance := &iter8v1alpha2.Experiment{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      nn.Name,
+				Namespace: nn.Namespace,
+			},
+		}
+		r.queueEvents <- event.GenericEvent{
+			Meta:   instance,
+			Object: instance,
+		}
+	}
+}
@@ -0,0 +1,167 @@
+/*
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package experiment
+
+// This file contains functions used for shifting traffic to winner gradually at the end of experiment.
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	iter8v1alpha2 "github.com/iter8-tools/iter8/pkg/apis/iter8/v1alpha2"
+	"github.com/iter8-tools/iter8/pkg/controller/experiment/util"
+)
+
+// toRamp tells whether traffic should be shifted to winner by ramp before the experiment completes.
+// Ramp status is initialized when the ramp starts.
+func (r *ReconcileExperiment) toRamp(context context.Context, instance *iter8v1alpha2.Experiment) bool {
+	if instance.Spec.TrafficControl == nil || instance.Spec.TrafficControl.Ramp == nil ||
+		instance.Spec.Terminate() || instance.Spec.GetOnTermination() != iter8v1alpha2.OnTerminationToWinner {
+		return false
+	}
+
+	if instance.Status.Ramp != nil {
+		return instance.Status.Ramp.Step < instance.Spec.GetRampSteps()
+	}
+
+	winner := currentWinner(instance)
+	if winner == nil || winner.Weight == 100 {
+		return false
+	}
+
+	now := metav1.Now()
+	instance.Status.Ramp = &iter8v1alpha2.RampStatus{
+		StartSplit:    currentSplit(instance),
+		LastSafeSplit: currentSplit(instance),
+		StepTimestamp: &now,
+	}
+	util.Logger(context).Info("RampStarted", "winner", winner.Name, "steps", instance.Spec.GetRampSteps())
+	r.markStatusUpdate()
+	return true
+}
+
+// processRamp moves traffic ramp to winner by one step.
+// If criteria are breached by any version receiving traffic since the last step,
+// the experiment is aborted with the last safe split.
+// returns non-nil error if reconcile process should be terminated right after this function
+func (r *ReconcileExperiment) processRamp(context context.Context, instance *iter8v1alpha2.Experiment) error {
+	ramp := instance.Status.Ramp
+	steps := instance.Spec.GetRampSteps()
+
+	if len(instance.Spec.Criteria) > 0 {
+		if _, err := r.fetchAssessment(context, instance, rampStepStartTime(instance)); err != nil {
+			return err
+		}
+		r.markAnalyticsServiceRunning(context, instance, "")
+
+		if name := breachedVersion(instance); name != "" {
+			split := make(map[string]int32, len(ramp.LastSafeSplit))
+			for version, weight := range ramp.LastSafeSplit {
+				split[version] = weight
+			}
+			instance.Spec.ManualOverride = &iter8v1alpha2.ManualOverride{
+				Action:       iter8v1alpha2.ActionTerminate,
+				TrafficSplit: split,
+			}
+			util.Logger(context).Info("RampAborted", "criteria breached by", name)
+			return nil
+		}
+	}
+
+	// versions other than winner lose traffic linearly from the start split
+	step := ramp.Step + 1
+	winnerID := instance.Status.Assessment.Winner.Winner
+	split := make(map[string]int32)
+	remaining := int32(100)
+	var winner *iter8v1alpha2.VersionAssessment
+	for _, va := range allVersions(instance) {
+		if va.ID == winnerID {
+			winner = va
+			continue
+		}
+		split[va.Name] = ramp.StartSplit[va.Name] * (steps - step) / steps
+		remaining -= split[va.Name]
+	}
+	if winner != nil {
+		split[winner.Name] = remaining
+	}
+
+	// the step is taken once winner is scaled up to serve its traffic
+	if waiting := r.scaleForSplit(context, instance, split); len(waiting) > 0 {
+		r.markTrafficHeld(context, instance, "Ramp step %d/%d waiting for replicas: %s", step, steps, strings.Join(waiting, "; "))
+		r.markRequeue()
+		return nil
+	}
+
+	now := metav1.Now()
+	ramp.LastSafeSplit = currentSplit(instance)
+	ramp.Step = step
+	ramp.StepTimestamp = &now
+	for _, va := range allVersions(instance) {
+		va.Weight = split[va.Name]
+	}
+
+	markTrafficStart(instance)
+	if err := r.router.UpdateRouteWithTrafficUpdate(context, instance); err != nil {
+		r.markRoutingRulesError(context, instance, "%v", err)
+		return err
+	}
+	r.markTrafficUpdate(context, instance, "Traffic: %s", instance.Status.TrafficToString())
+	r.scaleDown(context, instance)
+	r.markIterationUpdate(context, instance, "Ramp step %d/%d completed", ramp.Step, steps)
+	return nil
+}
+
+// rampStepStartTime returns the time when traffic of the current ramp step is applied,
+// so that each step is assessed by metrics of its own traffic split
+func rampStepStartTime(instance *iter8v1alpha2.Experiment) time.Time {
+	if ramp := instance.Status.Ramp; ramp.StepTimestamp != nil {
+		return ramp.StepTimestamp.Time
+	}
+	return assessmentStartTime(instance)
+}
+
+// breachedVersion returns name of a version receiving traffic that fails criteria, empty if none
+func breachedVersion(instance *iter8v1alpha2.Experiment) string {
+	for _, va := range allVersions(instance) {
+		if va.Weight > 0 && (va.Rollback || failsCriteria(&va.VersionAssessment)) {
+			return va.Name
+		}
+	}
+	return ""
+}
+
+// currentSplit returns current traffic split keyed by name of version
+func currentSplit(instance *iter8v1alpha2.Experiment) map[string]int32 {
+	out := make(map[string]int32)
+	for _, va := range allVersions(instance) {
+		out[va.Name] = va.Weight
+	}
+	return out
+}
+
+// allVersions returns assessments of baseline and candidates
+func allVersions(instance *iter8v1alpha2.Experiment) []*iter8v1alpha2.VersionAssessment {
+	assessment := instance.Status.Assessment
+	out := []*iter8v1alpha2.VersionAssessment{&assessment.Baseline}
+	for i := range assessment.Candidates {
+		out = append(out, &assessment.Candidates[i])
+	}
+	return out
+}
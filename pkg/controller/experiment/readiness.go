@@ -0,0 +1,104 @@
+/*
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package experiment
+
+// This file contains functions used for gating traffic on availability of candidates.
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	iter8v1alpha2 "github.com/iter8-tools/iter8/pkg/apis/iter8/v1alpha2"
+	"github.com/iter8-tools/iter8/pkg/controller/experiment/targets"
+)
+
+// checkCandidates checks availability of candidates, and terminates the experiment if some candidate
+// does not become available within the progress deadline
+// returns names of candidates not available
+func (r *ReconcileExperiment) checkCandidates(context context.Context, instance *iter8v1alpha2.Experiment,
+	candidates []runtime.Object) []string {
+	unavailable := []string{}
+	reasons := []string{}
+	failure := ""
+	names := instance.GetCandidates()
+	for i, obj := range candidates {
+		available, failed, reason := targets.Available(obj)
+		if available {
+			continue
+		}
+		name := names[i]
+		unavailable = append(unavailable, name)
+		reasons = append(reasons, name+": "+reason)
+		if failed && failure == "" {
+			failure = name + ": " + reason
+		}
+	}
+
+	if len(unavailable) == 0 {
+		if instance.Status.UnavailableSince != nil {
+			instance.Status.UnavailableSince = nil
+			r.markStatusUpdate()
+		}
+		return nil
+	}
+
+	now := metav1.Now()
+	if instance.Status.UnavailableSince == nil {
+		instance.Status.UnavailableSince = &now
+		r.markStatusUpdate()
+	}
+	deadline, _ := instance.Spec.GetProgressDeadline()
+	if failure == "" && now.Time.After(instance.Status.UnavailableSince.Add(deadline)) {
+		failure = fmt.Sprintf("%s not available within %s", strings.Join(unavailable, ", "), deadline)
+	}
+
+	if failure != "" {
+		r.markProgressDeadlineExceeded(context, instance, "%s", failure)
+		instance.Spec.TerminateExperiment()
+	} else {
+		r.markTrafficHeld(context, instance, "Candidates not available: %s", strings.Join(reasons, "; "))
+	}
+	return unavailable
+}
+
+// holdUnavailable reverts traffic increase of candidates not available back to baseline
+// returns non-nil error if candidates cannot be fetched from cluster
+func (r *ReconcileExperiment) holdUnavailable(context context.Context, instance *iter8v1alpha2.Experiment,
+	previous map[string]int32) error {
+	targetsHandler := targets.Init(instance, r.Client)
+	if err := targetsHandler.GetCandidates(context); err != nil {
+		r.markTargetsError(context, instance, "Missing Candidate")
+		return err
+	}
+
+	unavailable := r.checkCandidates(context, instance, targetsHandler.Candidates)
+	assessment := instance.Status.Assessment
+	for _, name := range unavailable {
+		for i := range assessment.Candidates {
+			candidate := &assessment.Candidates[i]
+			if candidate.Name != name || candidate.Weight <= previous[name] {
+				continue
+			}
+			assessment.Baseline.Weight += candidate.Weight - previous[name]
+			candidate.Weight = previous[name]
+		}
+	}
+	return nil
+}
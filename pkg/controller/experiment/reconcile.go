@@ -19,11 +19,15 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"time"
 
+	appsv1 "k8s.io/api/apps/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	runtime "k8s.io/apimachinery/pkg/runtime"
 
 	"github.com/iter8-tools/iter8/pkg/analytics"
+	analyticsv1alpha2 "github.com/iter8-tools/iter8/pkg/analytics/api/v1alpha2"
 	iter8v1alpha2 "github.com/iter8-tools/iter8/pkg/apis/iter8/v1alpha2"
 	"github.com/iter8-tools/iter8/pkg/controller/experiment/targets"
 	"github.com/iter8-tools/iter8/pkg/controller/experiment/util"
@@ -32,8 +36,12 @@ import (
 func (r *ReconcileExperiment) completeExperiment(context context.Context, instance *iter8v1alpha2.Experiment) error {
 	// remove experiment and targets from adapter
 	r.iter8Adapter.RemoveExperiment(instance)
+	r.notifyQueued()
 
 	overrideAssessment(instance)
+	outcome := experimentOutcome(instance)
+	instance.Status.Outcome = &outcome
+	r.restoreScaling(context, instance)
 	targets.Cleanup(context, instance, r.Client)
 	err := r.router.UpdateRouteToStable(context, instance)
 	if err != nil {
@@ -67,13 +75,40 @@ func completeStatusMessage(instance *iter8v1alpha2.Experiment) string {
 		}
 	}
 
-	if instance.Spec.Terminate() {
+	if instance.Status.RolledBackAfterPromotion() {
+		out += " (Rolled Back After Promotion)"
+	} else if instance.Status.PromotionFailed() {
+		out += " (Promotion Failed)"
+	} else if instance.Status.ProgressDeadlineExceeded() {
+		out += " (Progress Deadline Exceeded)"
+	} else if instance.Spec.Terminate() {
 		out += " (Abort)"
+	} else if instance.Status.Promoted() {
+		out += " (Promoted To Baseline)"
+	} else if instance.Status.EarlyStopped() {
+		out += " (Early Stop)"
 	}
 
 	return out
 }
 
+// returns outcome of the experiment at completion
+func experimentOutcome(instance *iter8v1alpha2.Experiment) iter8v1alpha2.OutcomeType {
+	if instance.Status.RolledBackAfterPromotion() {
+		return iter8v1alpha2.OutcomeRolledBackAfterPromotion
+	}
+	if instance.Status.ProgressDeadlineExceeded() || instance.Status.PromotionFailed() {
+		return iter8v1alpha2.OutcomeFailed
+	}
+	if instance.Spec.Terminate() {
+		return iter8v1alpha2.OutcomeAborted
+	}
+	if instance.Status.IsWinnerFound() {
+		return iter8v1alpha2.OutcomeWinnerFound
+	}
+	return iter8v1alpha2.OutcomeNoWinnerFound
+}
+
 func (r *ReconcileExperiment) checkOrInitRules(context context.Context, instance *iter8v1alpha2.Experiment) error {
 	err := r.router.Fetch(context, instance)
 	if err != nil {
@@ -90,7 +125,10 @@ func (r *ReconcileExperiment) detectTargets(context context.Context, instance *i
 	targetsHandler := targets.Init(instance, r.Client)
 
 	if err := targetsHandler.GetService(context); err != nil {
-		if instance.Status.TargetsFound() {
+		if !errors.IsNotFound(err) {
+			r.markTargetsError(context, instance, "Fail to get service: %v", err)
+			return false, err
+		} else if instance.Status.TargetsFound() {
 			r.markTargetsError(context, instance, "Service Deleted")
 			return false, err
 		} else {
@@ -100,7 +138,10 @@ func (r *ReconcileExperiment) detectTargets(context context.Context, instance *i
 	}
 
 	if err := targetsHandler.GetBaseline(context); err != nil {
-		if instance.Status.TargetsFound() {
+		if !errors.IsNotFound(err) {
+			r.markTargetsError(context, instance, "Fail to get baseline: %v", err)
+			return false, err
+		} else if instance.Status.TargetsFound() {
 			r.markTargetsError(context, instance, "Baseline Deleted")
 			return false, err
 		} else {
@@ -108,6 +149,14 @@ func (r *ReconcileExperiment) detectTargets(context context.Context, instance *i
 			return false, err
 		}
 	} else {
+		// generate candidates from templates as copies of baseline
+		if baseline, ok := targetsHandler.Baseline.(*appsv1.Deployment); ok && len(instance.Spec.CandidateTemplates) > 0 {
+			if err := r.generateCandidates(context, instance, baseline); err != nil {
+				r.markTargetsError(context, instance, "Fail to generate candidates: %v", err)
+				return false, err
+			}
+		}
+
 		// UpdateBaseline will create DestinationRule and VirtualService if needed
 		if err = r.router.UpdateRouteWithBaseline(context, instance, targetsHandler.Baseline); err != nil {
 			r.markRoutingRulesError(context, instance, "Fail in updating routing rule: %v", err)
@@ -116,7 +165,10 @@ func (r *ReconcileExperiment) detectTargets(context context.Context, instance *i
 	}
 
 	if err := targetsHandler.GetCandidates(context); err != nil {
-		if instance.Status.TargetsFound() {
+		if !errors.IsNotFound(err) {
+			r.markTargetsError(context, instance, "Fail to get candidates: %v", err)
+			return false, err
+		} else if instance.Status.TargetsFound() {
 			r.markTargetsError(context, instance, "Candidate Deleted")
 			return false, err
 		} else {
@@ -124,6 +176,11 @@ func (r *ReconcileExperiment) detectTargets(context context.Context, instance *i
 			return false, err
 		}
 	} else {
+		// no traffic is routed to candidates until they are available
+		if unavailable := r.checkCandidates(context, instance, targetsHandler.Candidates); len(unavailable) > 0 {
+			return false, nil
+		}
+
 		// Update DestinationRule for candidates
 		// If baseline is also configured (see above), we move set rule to progressing
 		if err = r.router.UpdateRouteWithCandidates(context, instance, targetsHandler.Candidates); err != nil {
@@ -148,11 +205,35 @@ func (r *ReconcileExperiment) processIteration(context context.Context, instance
 		r.markStatusUpdate()
 	}
 
-	if len(instance.Spec.Criteria) == 0 {
+	// no more assessment once winner is being promoted to baseline
+	if instance.Status.Promotion != nil {
+		return nil
+	}
+
+	// criteria keep being evaluated after promotion
+	if instance.Status.Bake != nil {
+		return r.processBake(context, instance)
+	}
+
+	// traffic is shifted to winner by ramp after all iterations
+	if instance.Status.Ramp != nil {
+		return r.processRamp(context, instance)
+	}
+
+	previous := currentSplit(instance)
+	warmup := inWarmup(instance)
+	holdReason := ""
+	assessed := false
+	if warmup {
+		// traffic is held at the initial split until metrics are assessed after warm-up
+	} else if isFixedSteps(instance) && len(instance.Spec.Criteria) == 0 {
+		// no criteria to gate the steps
+		trafficUpdated = applyFixedSteps(instance)
+	} else if len(instance.Spec.Criteria) == 0 {
 		// each candidate gets maxincrement traffic at each interval
 		// until no more traffic can be deducted from baseline
 		basetraffic := instance.Status.Assessment.Baseline.Weight
-		diff := instance.Spec.GetMaxIncrements() * int32(len(instance.Spec.Candidates))
+		diff := instance.Spec.GetMaxIncrements() * int32(len(instance.Status.Assessment.Candidates))
 		if basetraffic-diff >= 0 {
 			instance.Status.Assessment.Baseline.Weight = basetraffic - diff
 			for i := range instance.Status.Assessment.Candidates {
@@ -161,36 +242,16 @@ func (r *ReconcileExperiment) processIteration(context context.Context, instance
 			trafficUpdated = true
 		}
 	} else {
+		assessed = true
 		// Get latest analysis
-		payload, err := analytics.MakeRequest(instance)
+		response, err := r.fetchAssessment(context, instance, assessmentStartTime(instance))
 		if err != nil {
-			r.markAnalyticsServiceError(context, instance, "%s", err.Error())
 			return err
 		}
 
-		response, err := analytics.Invoke(log, instance.Spec.GetAnalyticsEndpoint(), payload)
-		if err != nil {
-			r.markAnalyticsServiceError(context, instance, "%s", err.Error())
-			return err
-		}
-
-		if response.LastState == nil {
-			instance.Status.AnalysisState.Raw = []byte("{}")
-		} else {
-			lastState, err := json.Marshal(response.LastState)
-			if err != nil {
-				r.markAnalyticsServiceError(context, instance, "%s", err.Error())
-				return err
-			}
-			instance.Status.AnalysisState = &runtime.RawExtension{Raw: lastState}
-		}
-
 		abort := true
-		instance.Status.Assessment.Baseline.VersionAssessment = *response.BaselineAssessment.DeepCopy()
-		for i, ca := range response.CandidateAssessments {
-			instance.Status.Assessment.Candidates[i].VersionAssessment = *ca.VersionAssessment.DeepCopy()
-			instance.Status.Assessment.Candidates[i].Rollback = ca.Rollback
-			if !ca.Rollback {
+		for _, candidate := range instance.Status.Assessment.Candidates {
+			if !candidate.Rollback {
 				abort = false
 			}
 		}
@@ -215,48 +276,93 @@ func (r *ReconcileExperiment) processIteration(context context.Context, instance
 				}
 			}
 		}
+		applyRewardPolicy(instance)
+		sampleSizeErr := applyFrequentistAssessment(instance)
+		holdReason = trafficHoldReason(instance)
+		if holdReason != "" {
+			// no winner is declared until every version has enough samples
+			instance.Status.Assessment.Winner.WinnerFound = false
+		} else if noTrafficReason(instance) != "" {
+			// nor before every version has received traffic
+			instance.Status.Assessment.Winner.WinnerFound = false
+		} else {
+			applyEarlyStop(instance)
+		}
 		r.markAssessmentUpdate(context, instance, "Winner assessment: %s", instance.Status.WinnerToString())
-
-		// check traffic split update
-		strategy := instance.Spec.GetStrategy()
-		_, ok := response.TrafficSplitRecommendation[strategy]
-		if !ok {
-			err := fmt.Errorf("Missing traffic split recommendation for strategy %s", strategy)
-			r.markAnalyticsServiceError(context, instance, "%v", err)
-			return err
+		if sampleSizeErr != nil {
+			r.markSampleSizeUnknown(context, instance, "%v", sampleSizeErr)
 		}
-		trafficSplit := response.TrafficSplitRecommendation[strategy]
 
-		if baselineWeight, ok := trafficSplit[analytics.GetBaselineID()]; ok {
-			if instance.Status.Assessment.Baseline.Weight != baselineWeight {
-				trafficUpdated = true
-			}
-			instance.Status.Assessment.Baseline.Weight = baselineWeight
+		if holdReason != "" {
+			// keep traffic steady except for candidates to be rolled back
+			trafficUpdated = holdTraffic(instance)
+			r.markTrafficHeld(context, instance, "%s", holdReason)
+		} else if isFixedSteps(instance) {
+			trafficUpdated = applyFixedSteps(instance)
+		} else if isBlueGreen(instance) {
+			trafficUpdated = applyBlueGreen(instance)
 		} else {
-			err := fmt.Errorf("traffic split recommendation for baseline not found")
-			r.markAnalyticsServiceError(context, instance, "%v", err)
-			return err
-		}
+			// check traffic split update
+			strategy := instance.Spec.GetStrategy()
+			_, ok := response.TrafficSplitRecommendation[strategy]
+			if !ok {
+				err := fmt.Errorf("Missing traffic split recommendation for strategy %s", strategy)
+				r.markAnalyticsServiceError(context, instance, "%v", err)
+				return err
+			}
+			trafficSplit := response.TrafficSplitRecommendation[strategy]
 
-		for i, candidate := range instance.Status.Assessment.Candidates {
-			if candidate.Rollback {
-				trafficUpdated = true
-				instance.Status.Assessment.Candidates[i].Weight = int32(0)
-			} else if weight, ok := trafficSplit[analytics.GetCandidateID(i)]; ok {
-				if candidate.Weight != weight {
+			if baselineWeight, ok := trafficSplit[analytics.GetBaselineID()]; ok {
+				if instance.Status.Assessment.Baseline.Weight != baselineWeight {
 					trafficUpdated = true
 				}
-				instance.Status.Assessment.Candidates[i].Weight = weight
+				instance.Status.Assessment.Baseline.Weight = baselineWeight
 			} else {
-				err := fmt.Errorf("traffic split recommendation for candidate %s not found", candidate.Name)
+				err := fmt.Errorf("traffic split recommendation for baseline not found")
 				r.markAnalyticsServiceError(context, instance, "%v", err)
 				return err
 			}
+
+			for i, candidate := range instance.Status.Assessment.Candidates {
+				if candidate.Rollback {
+					trafficUpdated = true
+					instance.Status.Assessment.Candidates[i].Weight = int32(0)
+				} else if weight, ok := trafficSplit[analytics.GetCandidateID(
+					instance.Status.Assessment.CandidateIndex(candidate.Name))]; ok {
+					if candidate.Weight != weight {
+						trafficUpdated = true
+					}
+					instance.Status.Assessment.Candidates[i].Weight = weight
+				} else {
+					err := fmt.Errorf("traffic split recommendation for candidate %s not found", candidate.Name)
+					r.markAnalyticsServiceError(context, instance, "%v", err)
+					return err
+				}
+			}
 		}
 
 		r.markAnalyticsServiceRunning(context, instance, "")
 	}
 
+	if trafficUpdated {
+		// no traffic increase for candidates not available
+		if err := r.holdUnavailable(context, instance, previous); err != nil {
+			return err
+		}
+		if instance.Spec.Terminate() {
+			return nil
+		}
+	}
+
+	segmentsUpdated, err := r.processSegments(context, instance, assessed, holdReason != "")
+	if err != nil {
+		return err
+	}
+	trafficUpdated = trafficUpdated || segmentsUpdated
+
+	// scale versions ahead of traffic increase, and after traffic decrease
+	r.scaleUp(context, instance, previous)
+	markTrafficStart(instance)
 	if trafficUpdated {
 		if err := r.router.UpdateRouteWithTrafficUpdate(context, instance); err != nil {
 			r.markRoutingRulesError(context, instance, "%v", err)
@@ -264,11 +370,91 @@ func (r *ReconcileExperiment) processIteration(context context.Context, instance
 		}
 		r.markTrafficUpdate(context, instance, "Traffic: %s", instance.Status.TrafficToString())
 	}
+	r.scaleDown(context, instance)
 
-	r.markIterationUpdate(context, instance, "Iteration %d/%d completed", *instance.Status.CurrentIteration, instance.Spec.GetMaxIterations())
+	if warmup {
+		r.markIterationUpdate(context, instance, "Iteration %d/%d completed (warm-up)", *instance.Status.CurrentIteration, instance.Spec.GetMaxIterations())
+	} else if holdReason != "" {
+		r.markIterationUpdate(context, instance, "Iteration %d/%d completed (traffic held: %s)", *instance.Status.CurrentIteration, instance.Spec.GetMaxIterations(), holdReason)
+	} else {
+		r.markIterationUpdate(context, instance, "Iteration %d/%d completed", *instance.Status.CurrentIteration, instance.Spec.GetMaxIterations())
+	}
 	return nil
 }
 
+// fetchAssessment gets latest analysis of metrics collected since startTime and records assessment of each version in status
+func (r *ReconcileExperiment) fetchAssessment(context context.Context, instance *iter8v1alpha2.Experiment,
+	startTime time.Time) (*analyticsv1alpha2.Response, error) {
+	log := util.Logger(context)
+	payload, err := analytics.MakeRequest(instance, startTime)
+	if err != nil {
+		r.markAnalyticsServiceError(context, instance, "%s", err.Error())
+		return nil, err
+	}
+
+	response, err := analytics.Invoke(log, instance.Spec.GetAnalyticsEndpoint(), payload)
+	if err != nil {
+		r.markAnalyticsServiceError(context, instance, "%s", err.Error())
+		return nil, err
+	}
+
+	if response.LastState == nil {
+		instance.Status.AnalysisState.Raw = []byte("{}")
+	} else {
+		lastState, err := json.Marshal(response.LastState)
+		if err != nil {
+			r.markAnalyticsServiceError(context, instance, "%s", err.Error())
+			return nil, err
+		}
+		instance.Status.AnalysisState = &runtime.RawExtension{Raw: lastState}
+	}
+
+	instance.Status.Assessment.Baseline.VersionAssessment = *response.BaselineAssessment.DeepCopy()
+	for _, ca := range response.CandidateAssessments {
+		i := candidatePosition(instance.Status.Assessment, ca.ID)
+		if i < 0 {
+			err := fmt.Errorf("assessment of unknown candidate %s", ca.ID)
+			r.markAnalyticsServiceError(context, instance, "%v", err)
+			return nil, err
+		}
+		instance.Status.Assessment.Candidates[i].VersionAssessment = *ca.VersionAssessment.DeepCopy()
+		// candidates with unhealthy pods or being retired stay rolled back
+		instance.Status.Assessment.Candidates[i].Rollback = ca.Rollback || instance.Status.Assessment.Candidates[i].Unhealthy ||
+			instance.Status.Assessment.Candidates[i].Retired
+		if (isFixedSteps(instance) || isBlueGreen(instance)) && failsCriteria(&ca.VersionAssessment) {
+			// gate of fixed steps or blue/green validation fails
+			instance.Status.Assessment.Candidates[i].Rollback = true
+		}
+	}
+	return response, nil
+}
+
+// candidatePosition returns position in assessment of candidate with given analytics id, -1 if not found
+func candidatePosition(assessment *iter8v1alpha2.Assessment, id string) int {
+	for i, candidate := range assessment.Candidates {
+		if analytics.GetCandidateID(assessment.CandidateIndex(candidate.Name)) == id {
+			return i
+		}
+	}
+	return -1
+}
+
+// assessmentStartTime returns the start of the time range of metrics assessed in iterations,
+// which excludes the warm-up period
+func assessmentStartTime(instance *iter8v1alpha2.Experiment) time.Time {
+	warmup, _ := instance.Spec.GetWarmup()
+	return instance.Status.StartTimestamp.Add(warmup)
+}
+
+// inWarmup tells whether the experiment is still in its warm-up period
+func inWarmup(instance *iter8v1alpha2.Experiment) bool {
+	warmup, err := instance.Spec.GetWarmup()
+	if err != nil || warmup == 0 || instance.Status.StartTimestamp == nil {
+		return false
+	}
+	return time.Now().Before(instance.Status.StartTimestamp.Add(warmup))
+}
+
 func (r *ReconcileExperiment) updateIteration(instance *iter8v1alpha2.Experiment) {
 	*instance.Status.CurrentIteration++
 	r.markStatusUpdate()
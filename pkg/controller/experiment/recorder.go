@@ -36,6 +36,16 @@ func (r *ReconcileExperiment) markTargetsError(context context.Context, instance
 	}
 }
 
+func (r *ReconcileExperiment) markProgressDeadlineExceeded(context context.Context, instance *iter8v1alpha2.Experiment,
+	messageFormat string, messageA ...interface{}) {
+	if updated, reason := instance.Status.MarkProgressDeadlineExceeded(messageFormat, messageA...); updated {
+		util.Logger(context).Info(reason + ", " + fmt.Sprintf(messageFormat, messageA...))
+		r.eventRecorder.Eventf(instance, corev1.EventTypeWarning, reason, messageFormat, messageA...)
+		r.notificationCenter.Notify(instance, reason, messageFormat, messageA...)
+		r.markStatusUpdate()
+	}
+}
+
 func (r *ReconcileExperiment) markTargetsFound(context context.Context, instance *iter8v1alpha2.Experiment,
 	messageFormat string, messageA ...interface{}) {
 	if updated, reason := instance.Status.MarkTargetsFound(messageFormat, messageA...); updated {
@@ -97,6 +107,36 @@ func (r *ReconcileExperiment) markTrafficUpdate(context context.Context, instanc
 	}
 }
 
+func (r *ReconcileExperiment) markCandidateUnhealthy(context context.Context, instance *iter8v1alpha2.Experiment,
+	messageFormat string, messageA ...interface{}) {
+	if updated, reason := instance.Status.MarkCandidateUnhealthy(messageFormat, messageA...); updated {
+		util.Logger(context).Info(reason + ", " + fmt.Sprintf(messageFormat, messageA...))
+		r.eventRecorder.Eventf(instance, corev1.EventTypeWarning, reason, messageFormat, messageA...)
+		r.notificationCenter.Notify(instance, reason, messageFormat, messageA...)
+		r.markStatusUpdate()
+	}
+}
+
+func (r *ReconcileExperiment) markTrafficHeld(context context.Context, instance *iter8v1alpha2.Experiment,
+	messageFormat string, messageA ...interface{}) {
+	if updated, reason := instance.Status.MarkTrafficHeld(messageFormat, messageA...); updated {
+		util.Logger(context).Info(reason + ", " + fmt.Sprintf(messageFormat, messageA...))
+		r.eventRecorder.Eventf(instance, corev1.EventTypeNormal, reason, messageFormat, messageA...)
+		r.notificationCenter.Notify(instance, reason, messageFormat, messageA...)
+		r.markStatusUpdate()
+	}
+}
+
+func (r *ReconcileExperiment) markSampleSizeUnknown(context context.Context, instance *iter8v1alpha2.Experiment,
+	messageFormat string, messageA ...interface{}) {
+	if updated, reason := instance.Status.MarkSampleSizeUnknown(messageFormat, messageA...); updated {
+		util.Logger(context).Info(reason + ", " + fmt.Sprintf(messageFormat, messageA...))
+		r.eventRecorder.Eventf(instance, corev1.EventTypeWarning, reason, messageFormat, messageA...)
+		r.notificationCenter.Notify(instance, reason, messageFormat, messageA...)
+		r.markStatusUpdate()
+	}
+}
+
 func (r *ReconcileExperiment) markExperimentCompleted(context context.Context, instance *iter8v1alpha2.Experiment,
 	messageFormat string, messageA ...interface{}) {
 	if updated, reason := instance.Status.MarkExperimentCompleted(messageFormat, messageA...); updated {
@@ -172,3 +212,23 @@ func (r *ReconcileExperiment) markActionResume(context context.Context, instance
 		r.markRefresh()
 	}
 }
+
+func (r *ReconcileExperiment) markExperimentQueued(context context.Context, instance *iter8v1alpha2.Experiment,
+	position int32, messageFormat string, messageA ...interface{}) {
+	if updated, reason := instance.Status.MarkExperimentQueued(position, messageFormat, messageA...); updated {
+		util.Logger(context).Info(reason + ", " + fmt.Sprintf(messageFormat, messageA...))
+		r.eventRecorder.Eventf(instance, corev1.EventTypeNormal, reason, messageFormat, messageA...)
+		r.notificationCenter.Notify(instance, reason, messageFormat, messageA...)
+		r.markStatusUpdate()
+	}
+}
+
+func (r *ReconcileExperiment) markExperimentDequeued(context context.Context, instance *iter8v1alpha2.Experiment,
+	messageFormat string, messageA ...interface{}) {
+	if updated, reason := instance.Status.MarkExperimentDequeued(messageFormat, messageA...); updated {
+		util.Logger(context).Info(reason + ", " + fmt.Sprintf(messageFormat, messageA...))
+		r.eventRecorder.Eventf(instance, corev1.EventTypeNormal, reason, messageFormat, messageA...)
+		r.notificationCenter.Notify(instance, reason, messageFormat, messageA...)
+		r.markStatusUpdate()
+	}
+}
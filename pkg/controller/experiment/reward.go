@@ -0,0 +1,163 @@
+/*
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package experiment
+
+// This file contains functions used for combining multiple reward criteria into
+// a single objective when selecting the winner of an experiment.
+
+import (
+	"math"
+
+	iter8v1alpha2 "github.com/iter8-tools/iter8/pkg/apis/iter8/v1alpha2"
+)
+
+const preferredDirectionLower = "lower"
+
+// applyRewardPolicy computes the per-reward breakdown and combined objective of each version,
+// and selects the winner by the combined objective.
+// It is a no-op unless more than one reward criterion is specified.
+// The winner is only reselected once analytics has found one, so that point estimates of rewards
+// never declare a winner that analytics has not backed with its own assessment.
+func applyRewardPolicy(instance *iter8v1alpha2.Experiment) {
+	rewards := instance.Spec.GetRewardCriteria()
+	if len(rewards) < 2 {
+		return
+	}
+
+	assessment := instance.Status.Assessment
+	policy := instance.Spec.GetRewardPolicy()
+	baseline := rewardValues(&assessment.Baseline, rewards)
+
+	setRewardAssessments(instance, &assessment.Baseline, rewards, baseline)
+	for i := range assessment.Candidates {
+		setRewardAssessments(instance, &assessment.Candidates[i], rewards, baseline)
+	}
+
+	if !assessment.IsWinnerFound() {
+		return
+	}
+
+	best := &assessment.Baseline
+	for i := range assessment.Candidates {
+		candidate := &assessment.Candidates[i]
+		if candidate.Rollback {
+			continue
+		}
+		if betterRewards(candidate, best, policy) {
+			best = candidate
+		}
+	}
+
+	name := best.Name
+	assessment.Winner.Name = &name
+	assessment.Winner.Winner = best.ID
+	assessment.Winner.Probability = best.WinProbability
+}
+
+// rewardValues extracts values of reward metrics from the assessment of a version
+func rewardValues(va *iter8v1alpha2.VersionAssessment, rewards []iter8v1alpha2.Criterion) []*float32 {
+	out := make([]*float32, len(rewards))
+	for i, reward := range rewards {
+		for _, ca := range va.CriterionAssessments {
+			if ca.MetricID == reward.Metric && ca.Statistics != nil && ca.Statistics.Value != nil {
+				value := *ca.Statistics.Value
+				out[i] = &value
+				break
+			}
+		}
+	}
+	return out
+}
+
+// setRewardAssessments fills in reward breakdown and objective of a version against the baseline values
+func setRewardAssessments(instance *iter8v1alpha2.Experiment, va *iter8v1alpha2.VersionAssessment,
+	rewards []iter8v1alpha2.Criterion, baseline []*float32) {
+	values := rewardValues(va, rewards)
+	va.RewardAssessments = make([]iter8v1alpha2.RewardAssessment, len(rewards))
+	va.Objective = nil
+
+	complete := true
+	objective := float32(0)
+	for i, reward := range rewards {
+		ra := iter8v1alpha2.RewardAssessment{
+			Metric:   reward.Metric,
+			Value:    values[i],
+			Weight:   reward.GetWeight(),
+			Priority: reward.Priority,
+		}
+
+		if values[i] != nil && baseline[i] != nil && *baseline[i] != 0 {
+			improvement := (*values[i] - *baseline[i]) / float32(math.Abs(float64(*baseline[i])))
+			if preferredDirection(instance, reward.Metric) == preferredDirectionLower {
+				improvement = -improvement
+			}
+			ra.Improvement = &improvement
+			objective += ra.Weight * improvement
+		} else {
+			complete = false
+		}
+		va.RewardAssessments[i] = ra
+	}
+
+	if complete && instance.Spec.GetRewardPolicy() == iter8v1alpha2.RewardPolicyWeighted {
+		va.Objective = &objective
+	}
+}
+
+// betterRewards tells whether version a is strictly better than version b under the reward policy
+// Versions with incomplete reward assessments are never better than others
+func betterRewards(a, b *iter8v1alpha2.VersionAssessment, policy iter8v1alpha2.RewardPolicyType) bool {
+	switch policy {
+	case iter8v1alpha2.RewardPolicyLexicographic:
+		for i := range a.RewardAssessments {
+			ai, bi := a.RewardAssessments[i].Improvement, b.RewardAssessments[i].Improvement
+			if ai == nil {
+				return false
+			}
+			if bi == nil || *ai > *bi {
+				return true
+			}
+			if *ai < *bi {
+				return false
+			}
+		}
+		return false
+	default:
+		if a.Objective == nil {
+			return false
+		}
+		return b.Objective == nil || *a.Objective > *b.Objective
+	}
+}
+
+// preferredDirection returns the preferred direction of the metric, which is empty if not specified
+func preferredDirection(instance *iter8v1alpha2.Experiment, metric string) string {
+	metrics := instance.Spec.Metrics
+	if metrics == nil {
+		return ""
+	}
+	for _, m := range metrics.CounterMetrics {
+		if m.Name == metric && m.PreferredDirection != nil {
+			return *m.PreferredDirection
+		}
+	}
+	for _, m := range metrics.RatioMetrics {
+		if m.Name == metric && m.PreferredDirection != nil {
+			return *m.PreferredDirection
+		}
+	}
+	return ""
+}
@@ -0,0 +1,106 @@
+/*
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package experiment
+
+import (
+	"testing"
+
+	analyticsv1alpha2 "github.com/iter8-tools/iter8/pkg/analytics/api/v1alpha2"
+	iter8v1alpha2 "github.com/iter8-tools/iter8/pkg/apis/iter8/v1alpha2"
+)
+
+func float32Ptr(f float32) *float32 { return &f }
+
+func rewardVersion(objective *float32, improvements ...*float32) *iter8v1alpha2.VersionAssessment {
+	va := &iter8v1alpha2.VersionAssessment{Objective: objective}
+	for _, improvement := range improvements {
+		va.RewardAssessments = append(va.RewardAssessments, iter8v1alpha2.RewardAssessment{Improvement: improvement})
+	}
+	return va
+}
+
+func TestBetterRewards(t *testing.T) {
+	cases := []struct {
+		name   string
+		a, b   *iter8v1alpha2.VersionAssessment
+		policy iter8v1alpha2.RewardPolicyType
+		want   bool
+	}{
+		{"weighted higher objective", rewardVersion(float32Ptr(0.2)), rewardVersion(float32Ptr(0.1)),
+			iter8v1alpha2.RewardPolicyWeighted, true},
+		{"weighted equal objective", rewardVersion(float32Ptr(0.1)), rewardVersion(float32Ptr(0.1)),
+			iter8v1alpha2.RewardPolicyWeighted, false},
+		{"weighted incomplete a", rewardVersion(nil), rewardVersion(float32Ptr(0.1)),
+			iter8v1alpha2.RewardPolicyWeighted, false},
+		{"weighted incomplete b", rewardVersion(float32Ptr(-0.1)), rewardVersion(nil),
+			iter8v1alpha2.RewardPolicyWeighted, true},
+		{"lexicographic first reward decides", rewardVersion(nil, float32Ptr(0.2), float32Ptr(-1)),
+			rewardVersion(nil, float32Ptr(0.1), float32Ptr(1)), iter8v1alpha2.RewardPolicyLexicographic, true},
+		{"lexicographic tie broken by second reward", rewardVersion(nil, float32Ptr(0.1), float32Ptr(-1)),
+			rewardVersion(nil, float32Ptr(0.1), float32Ptr(1)), iter8v1alpha2.RewardPolicyLexicographic, false},
+		{"lexicographic all equal", rewardVersion(nil, float32Ptr(0.1), float32Ptr(0.1)),
+			rewardVersion(nil, float32Ptr(0.1), float32Ptr(0.1)), iter8v1alpha2.RewardPolicyLexicographic, false},
+		{"lexicographic incomplete a", rewardVersion(nil, nil, float32Ptr(1)),
+			rewardVersion(nil, float32Ptr(0.1), float32Ptr(0.1)), iter8v1alpha2.RewardPolicyLexicographic, false},
+	}
+	for _, c := range cases {
+		if got := betterRewards(c.a, c.b, c.policy); got != c.want {
+			t.Errorf("%s: got %t, want %t", c.name, got, c.want)
+		}
+	}
+}
+
+func TestApplyRewardPolicyKeepsAnalyticsWinner(t *testing.T) {
+	isReward := true
+	value := func(v float32) analyticsv1alpha2.VersionAssessment {
+		return analyticsv1alpha2.VersionAssessment{
+			CriterionAssessments: []analyticsv1alpha2.CriterionAssessment{
+				{MetricID: "r1", Statistics: &analyticsv1alpha2.Statistics{Value: &v}},
+				{MetricID: "r2", Statistics: &analyticsv1alpha2.Statistics{Value: &v}},
+			},
+		}
+	}
+	for _, winnerFound := range []bool{false, true} {
+		instance := &iter8v1alpha2.Experiment{
+			Spec: iter8v1alpha2.ExperimentSpec{
+				Criteria: []iter8v1alpha2.Criterion{
+					{Metric: "r1", IsReward: &isReward},
+					{Metric: "r2", IsReward: &isReward},
+				},
+			},
+			Status: iter8v1alpha2.ExperimentStatus{
+				Assessment: &iter8v1alpha2.Assessment{
+					Baseline: iter8v1alpha2.VersionAssessment{Name: "baseline", VersionAssessment: value(1)},
+					Candidates: []iter8v1alpha2.VersionAssessment{
+						{Name: "candidate", VersionAssessment: value(2)},
+					},
+					Winner: &iter8v1alpha2.WinnerAssessment{
+						WinnerAssessment: &analyticsv1alpha2.WinnerAssessment{WinnerFound: winnerFound},
+					},
+				},
+			},
+		}
+		applyRewardPolicy(instance)
+
+		got := instance.Status.Assessment.Winner.Name
+		if !winnerFound && got != nil {
+			t.Errorf("winner %s selected without a winner from analytics", *got)
+		}
+		if winnerFound && (got == nil || *got != "candidate") {
+			t.Errorf("got winner %v, want candidate", got)
+		}
+	}
+}
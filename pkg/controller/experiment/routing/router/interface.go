@@ -31,6 +31,8 @@ type Interface interface {
 	UpdateRouteWithBaseline(ctx context.Context, instance *iter8v1alpha2.Experiment, baseline runtime.Object) error
 	// UpdateRouteWithCandidates updates routing rules with runtime objects of candidates
 	UpdateRouteWithCandidates(ctx context.Context, instance *iter8v1alpha2.Experiment, candidates []runtime.Object) error
+	// RefreshSubsets updates routing rules with latest pod templates of baseline and candidates
+	RefreshSubsets(ctx context.Context, instance *iter8v1alpha2.Experiment, baseline runtime.Object, candidates []runtime.Object) error
 	// UpdateRouteWithTrafficUpdate updates routing rules with new traffic state from assessment
 	UpdateRouteWithTrafficUpdate(ctx context.Context, instance *iter8v1alpha2.Experiment) error
 	// UpdateRouteToStable updates routing rules to desired stable state
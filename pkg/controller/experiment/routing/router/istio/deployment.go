@@ -56,8 +56,8 @@ func (h deploymentHandler) validateAndInit(drl *v1alpha3.DestinationRuleList, vs
 				drLabel, drok := drl.Items[0].GetLabels()[experimentLabel]
 				vsLabel, vsok := vsl.Items[0].GetLabels()[experimentLabel]
 				if drok && vsok {
-					if drLabel == expFullName && vsLabel == expFullName {
-						// valid progressing rules found
+					if drLabel == expFullName && vsLabel == expFullName || sharable(&vsl.Items[0], instance) {
+						// valid progressing rules found, or rules shared by experiments in disjoint traffic segments
 						out.destinationRule = drl.Items[0].DeepCopy()
 						out.virtualService = vsl.Items[0].DeepCopy()
 					} else {
@@ -90,9 +90,15 @@ func (h deploymentHandler) validateAndInit(drl *v1alpha3.DestinationRuleList, vs
 }
 
 // build destination content based on runtime target object and experiment info
+// Versions in other namespaces are reached through the service of the same name in their namespaces,
+// by subsets in destination rules of their namespaces
 func (h deploymentHandler) buildDestination(instance *iter8v1alpha2.Experiment, opts destinationOptions) *networkingv1alpha3.HTTPRouteDestination {
+	namespace := instance.ServiceNamespace()
+	if opts.name != "" && instance.CrossNamespace(opts.name) {
+		namespace = instance.VersionNamespacedName(opts.name).Namespace
+	}
 	b := NewHTTPRouteDestination().
-		WithHost(util.ServiceToFullHostName(instance.Spec.Service.Name, instance.ServiceNamespace())).
+		WithHost(util.ServiceToFullHostName(instance.Spec.Service.Name, namespace)).
 		WithSubset(opts.subset).
 		WithWeight(opts.weight)
 
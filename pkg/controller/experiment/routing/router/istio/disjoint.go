@@ -0,0 +1,203 @@
+/*
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package istio
+
+// This file contains functions used for sharing routing rules among experiments in disjoint traffic segments
+
+import (
+	"context"
+	"strconv"
+	"strings"
+
+	"istio.io/client-go/pkg/apis/networking/v1alpha3"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	runtime "k8s.io/apimachinery/pkg/runtime"
+
+	iter8v1alpha2 "github.com/iter8-tools/iter8/pkg/apis/iter8/v1alpha2"
+	"github.com/iter8-tools/iter8/pkg/controller/experiment/util"
+)
+
+// annotation of vs listing experiments in disjoint traffic segments sharing the routing rules
+const disjointExperiments = "iter8-tools/disjoint-experiments"
+
+// routeName returns name of route of the experiment
+// Routes of experiments in disjoint traffic segments are qualified by names of experiments
+func routeName(instance *iter8v1alpha2.Experiment, name string) string {
+	if instance.Spec.GetDisjoint() {
+		return name + "-" + util.FullExperimentName(instance)
+	}
+	return name
+}
+
+// baselineSubset returns subset name of baseline
+// Subsets of experiments in disjoint traffic segments are qualified by names of experiments
+func baselineSubset(instance *iter8v1alpha2.Experiment) string {
+	if instance.Spec.GetDisjoint() {
+		return SubsetBaseline + "-" + instance.Name
+	}
+	return SubsetBaseline
+}
+
+// candidateSubset returns subset name of candidate with given name
+func candidateSubset(instance *iter8v1alpha2.Experiment, name string) string {
+	idx := instance.Status.Assessment.CandidateIndex(name)
+	if instance.Spec.GetDisjoint() {
+		return SubsetCandidate + "-" + instance.Name + "-" + strconv.Itoa(idx)
+	}
+	return CandidateSubsetName(idx)
+}
+
+// candidateSubsets returns names of subsets of all candidates that have been in the experiment
+func candidateSubsets(instance *iter8v1alpha2.Experiment) map[string]bool {
+	out := make(map[string]bool)
+	assessment := instance.Status.Assessment
+	next := int(assessment.NextCandidateIndex)
+	if next < len(assessment.Candidates) {
+		next = len(assessment.Candidates)
+	}
+	for i := 0; i < next; i++ {
+		if instance.Spec.GetDisjoint() {
+			out[SubsetCandidate+"-"+instance.Name+"-"+strconv.Itoa(i)] = true
+		} else {
+			out[CandidateSubsetName(i)] = true
+		}
+	}
+	return out
+}
+
+// getDisjointExperiments returns experiments in disjoint traffic segments sharing the vs
+func getDisjointExperiments(vs *v1alpha3.VirtualService) []string {
+	val, ok := vs.GetAnnotations()[disjointExperiments]
+	if !ok || val == "" {
+		return []string{}
+	}
+	return strings.Split(val, ",")
+}
+
+// sharable tells whether routing rules being used by other experiments can be shared with the instance
+func sharable(vs *v1alpha3.VirtualService, instance *iter8v1alpha2.Experiment) bool {
+	return instance.Spec.GetDisjoint() && len(getDisjointExperiments(vs)) > 0
+}
+
+// joinRules adds route and baseline subset of the experiment to routing rules shared with other experiments
+// Routes of segments precede the base route shared by all experiments
+func (r *Router) joinRules(ctx context.Context, instance *iter8v1alpha2.Experiment, baseline runtime.Object) (err error) {
+	vs := r.rules.virtualService
+	if getExperimentRoute(vs, instance) != nil {
+		return nil
+	}
+
+	baselineDestination := r.handler.buildDestination(instance, destinationOptions{
+		name:   instance.GetBaseline(),
+		weight: 100,
+		subset: baselineSubset(instance),
+		port:   httpPort(instance),
+	})
+	experimentRoute := NewEmptyHTTPRoute(routeName(instance, routeNameExperiment)).
+		WithDestination(baselineDestination).
+		WithHTTPMatch(instance.Spec.TrafficControl.Match.HTTP)
+
+	exps := append(getDisjointExperiments(vs), util.FullExperimentName(instance))
+	vs = NewVirtualServiceBuilder(vs).
+		WithFirstHTTPRoute(experimentRoute.Build()).
+		WithDisjointExperiments(exps).
+		Build()
+	vs, err = r.client.NetworkingV1alpha3().
+		VirtualServices(vs.GetNamespace()).
+		Update(ctx, vs, metav1.UpdateOptions{})
+	if err != nil {
+		return
+	}
+	r.rules.virtualService = vs.DeepCopy()
+
+	if r.handler.requireDestinationRule() {
+		drb := NewDestinationRuleBuilder(r.rules.destinationRule).
+			RemoveSubsets(map[string]bool{baselineSubset(instance): true})
+		if drb, err = withTargetSubset(drb, instance, baseline, true); err != nil {
+			return
+		}
+		dr := (*v1alpha3.DestinationRule)(nil)
+		dr, err = r.client.NetworkingV1alpha3().
+			DestinationRules(r.rules.destinationRule.GetNamespace()).
+			Update(ctx, drb.Build(), metav1.UpdateOptions{})
+		if err != nil {
+			return
+		}
+		r.rules.destinationRule = dr.DeepCopy()
+	}
+	if err = r.updateForeignRules(ctx, instance, baseline, nil, false); err != nil {
+		return
+	}
+
+	instance.Status.Assessment.Baseline.Weight = 100
+	return
+}
+
+// leaveRules removes the experiment from routing rules shared with other experiments
+// Route of the segment is retained with its final traffic split and match clauses;
+// rules become stable when no other experiment is using them
+func (r *Router) leaveRules(ctx context.Context, instance *iter8v1alpha2.Experiment) (err error) {
+	expName := util.FullExperimentName(instance)
+	others := make([]string, 0)
+	for _, exp := range getDisjointExperiments(r.rules.virtualService) {
+		if exp != expName {
+			others = append(others, exp)
+		}
+	}
+
+	if len(others) == 0 && instance.Spec.GetCleanup() && r.rules.isInit() {
+		if err = r.deleteRules(ctx); err != nil {
+			return
+		}
+		return r.stableForeignRules(ctx, instance, false)
+	}
+
+	vs := r.rules.virtualService
+	if route := getExperimentRoute(vs, instance); route != nil {
+		r.updateRouteFromExperiment(route, instance)
+		route.Name = routeName(instance, routeNameBase)
+		route.Mirror = nil
+	}
+	vsb := removeStickyRoutes(NewVirtualServiceBuilder(vs), instance).
+		RemoveHTTPRoute(routeName(instance, routeNameTest)).
+		WithDisjointExperiments(others)
+	if len(others) == 0 {
+		vsb = vsb.WithStableLabel().RemoveExperimentLabel()
+	} else if vs.GetLabels()[experimentLabel] == expName {
+		vsb = vsb.WithExperimentRegistered(others[0])
+	}
+	if _, err = r.client.NetworkingV1alpha3().
+		VirtualServices(vs.Namespace).
+		Update(ctx, vsb.Build(), metav1.UpdateOptions{}); err != nil {
+		return
+	}
+
+	if r.handler.requireDestinationRule() {
+		dr := r.rules.destinationRule
+		drb := NewDestinationRuleBuilder(dr)
+		if len(others) == 0 {
+			drb = drb.WithStableLabel().RemoveExperimentLabel()
+		} else if dr.GetLabels()[experimentLabel] == expName {
+			drb = drb.WithExperimentRegistered(others[0])
+		}
+		if _, err = r.client.NetworkingV1alpha3().
+			DestinationRules(dr.Namespace).
+			Update(ctx, drb.Build(), metav1.UpdateOptions{}); err != nil {
+			return
+		}
+	}
+	return r.stableForeignRules(ctx, instance, true)
+}
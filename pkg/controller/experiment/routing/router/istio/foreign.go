@@ -0,0 +1,215 @@
+/*
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package istio
+
+// This file contains functions used for routing versions in namespaces other than that of the service
+
+import (
+	"context"
+	"fmt"
+
+	"istio.io/client-go/pkg/apis/networking/v1alpha3"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	runtime "k8s.io/apimachinery/pkg/runtime"
+
+	iter8v1alpha2 "github.com/iter8-tools/iter8/pkg/apis/iter8/v1alpha2"
+	"github.com/iter8-tools/iter8/pkg/controller/experiment/util"
+)
+
+// foreignRules returns destination rules of the service in namespaces of the given versions in other namespaces,
+// keyed by namespace; each has subsets of the given versions in its namespace, named as in the rule of the service
+func foreignRules(instance *iter8v1alpha2.Experiment, baseline runtime.Object,
+	candidates []runtime.Object) (map[string]*v1alpha3.DestinationRule, error) {
+	out := make(map[string]*v1alpha3.DestinationRule)
+	objs := candidates
+	if baseline != nil {
+		objs = append([]runtime.Object{baseline}, candidates...)
+	}
+	for i, obj := range objs {
+		accessor, err := meta.Accessor(obj)
+		if err != nil {
+			return nil, err
+		}
+		namespace := accessor.GetNamespace()
+		if !instance.CrossNamespace(instance.VersionOf(accessor.GetName(), namespace)) {
+			continue
+		}
+
+		_, subset, err := targetSubset(instance, obj, baseline != nil && i == 0)
+		if err != nil {
+			return nil, err
+		}
+		drb, ok := out[namespace]
+		if !ok {
+			drb = NewDestinationRule(GetRoutingRuleName(getRouterID(instance)),
+				util.ServiceToFullHostName(instance.Spec.Service.Name, namespace),
+				util.FullExperimentName(instance), namespace).
+				WithRouterRegistered(getRouterID(instance)).
+				WithProgressingLabel().
+				Build()
+		}
+		out[namespace] = NewDestinationRuleBuilder(drb).WithSubset(subset.Labels, subset.Name).Build()
+	}
+	return out, nil
+}
+
+// updateForeignRules creates or updates destination rules of versions in other namespaces
+// Subsets of versions not given are kept unless all is set, in which case all versions of the experiment are given
+// and rules of namespaces without versions are deleted
+func (r *Router) updateForeignRules(ctx context.Context, instance *iter8v1alpha2.Experiment, baseline runtime.Object,
+	candidates []runtime.Object, all bool) error {
+	if !r.handler.requireDestinationRule() {
+		return nil
+	}
+
+	rules, err := foreignRules(instance, baseline, candidates)
+	if err != nil {
+		return err
+	}
+	for namespace, dr := range rules {
+		client := r.client.NetworkingV1alpha3().DestinationRules(namespace)
+		existing, err := client.Get(ctx, dr.Name, metav1.GetOptions{})
+		if errors.IsNotFound(err) {
+			if _, err = client.Create(ctx, dr, metav1.CreateOptions{}); err != nil {
+				return err
+			}
+			continue
+		} else if err != nil {
+			return err
+		}
+		if !foreignRuleOwned(existing, instance) || existing.Spec.Host != dr.Spec.Host {
+			return fmt.Errorf("Destination rule %s/%s is being involved in other experiments", namespace, dr.Name)
+		}
+
+		drb := NewDestinationRuleBuilder(existing.DeepCopy())
+		if all {
+			drb = drb.InitSubsets()
+		} else {
+			names := make(map[string]bool)
+			for _, subset := range dr.Spec.Subsets {
+				names[subset.Name] = true
+			}
+			drb = drb.RemoveSubsets(names)
+		}
+		for _, subset := range dr.Spec.Subsets {
+			drb = drb.WithSubset(subset.Labels, subset.Name)
+		}
+		drb = drb.
+			WithRouterRegistered(getRouterID(instance)).
+			WithExperimentRegistered(util.FullExperimentName(instance)).
+			WithProgressingLabel()
+		if _, err = client.Update(ctx, drb.Build(), metav1.UpdateOptions{}); err != nil {
+			return err
+		}
+	}
+
+	if !all {
+		return nil
+	}
+	existing, err := r.listForeignRules(ctx, instance)
+	if err != nil {
+		return err
+	}
+	for _, dr := range existing {
+		if _, ok := rules[dr.Namespace]; ok {
+			continue
+		}
+		if err = r.client.NetworkingV1alpha3().DestinationRules(dr.Namespace).
+			Delete(ctx, dr.Name, metav1.DeleteOptions{}); err != nil && !errors.IsNotFound(err) {
+			return err
+		}
+	}
+	return nil
+}
+
+// stableForeignRules turns destination rules of versions in other namespaces still receiving traffic into stable rules
+// if keep is set, and deletes the others
+func (r *Router) stableForeignRules(ctx context.Context, instance *iter8v1alpha2.Experiment, keep bool) error {
+	if !r.handler.requireDestinationRule() {
+		return nil
+	}
+
+	serving := make(map[string]bool)
+	if assessment := instance.Status.Assessment; assessment != nil {
+		versions := append([]iter8v1alpha2.VersionAssessment{assessment.Baseline}, assessment.Candidates...)
+		for _, version := range versions {
+			if version.Weight > 0 && instance.CrossNamespace(version.Name) {
+				serving[instance.VersionNamespacedName(version.Name).Namespace] = true
+			}
+		}
+	}
+
+	rules, err := r.listForeignRules(ctx, instance)
+	if err != nil {
+		return err
+	}
+	for i := range rules {
+		dr := &rules[i]
+		client := r.client.NetworkingV1alpha3().DestinationRules(dr.Namespace)
+		if keep && serving[dr.Namespace] {
+			dr = NewDestinationRuleBuilder(dr).
+				WithStableLabel().
+				RemoveExperimentLabel().
+				Build()
+			if _, err = client.Update(ctx, dr, metav1.UpdateOptions{}); err != nil {
+				return err
+			}
+			continue
+		}
+		if err = client.Delete(ctx, dr.Name, metav1.DeleteOptions{}); err != nil && !errors.IsNotFound(err) {
+			return err
+		}
+	}
+	return nil
+}
+
+// listForeignRules lists destination rules of the experiment in namespaces other than that of the service
+func (r *Router) listForeignRules(ctx context.Context, instance *iter8v1alpha2.Experiment) ([]v1alpha3.DestinationRule, error) {
+	selector := labels.SelectorFromSet(map[string]string{
+		experimentLabel: util.FullExperimentName(instance),
+		routerID:        getRouterID(instance),
+	})
+	drl, err := r.client.NetworkingV1alpha3().DestinationRules(metav1.NamespaceAll).
+		List(ctx, metav1.ListOptions{LabelSelector: selector.String()})
+	if err != nil {
+		return nil, err
+	}
+
+	out := make([]v1alpha3.DestinationRule, 0)
+	for _, dr := range drl.Items {
+		if dr.Namespace != instance.ServiceNamespace() {
+			out = append(out, dr)
+		}
+	}
+	return out, nil
+}
+
+// foreignRuleOwned tells whether the destination rule in another namespace can be used by the experiment,
+// i.e., it is used by the experiment, or left stable by an earlier experiment of the same router
+func foreignRuleOwned(dr *v1alpha3.DestinationRule, instance *iter8v1alpha2.Experiment) bool {
+	drLabels := dr.GetLabels()
+	if drLabels[routerID] != getRouterID(instance) {
+		return false
+	}
+	owner, ok := drLabels[experimentLabel]
+	if !ok {
+		return drLabels[experimentRole] == roleStable
+	}
+	return owner == util.FullExperimentName(instance)
+}
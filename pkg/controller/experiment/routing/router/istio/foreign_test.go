@@ -0,0 +1,168 @@
+/*
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package istio
+
+import (
+	"reflect"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	iter8v1alpha2 "github.com/iter8-tools/iter8/pkg/apis/iter8/v1alpha2"
+)
+
+// foreignExperiment returns an experiment on service reviews in namespace bookinfo with given versions
+func foreignExperiment(baseline string, candidates ...string) *iter8v1alpha2.Experiment {
+	instance := portsExperiment()
+	instance.Spec.Service.Baseline = baseline
+	instance.Spec.Service.Candidates = candidates
+	instance.Status.Assessment = &iter8v1alpha2.Assessment{
+		Baseline: iter8v1alpha2.VersionAssessment{Name: baseline, Weight: 100},
+	}
+	for _, candidate := range candidates {
+		instance.Status.Assessment.Candidates = append(instance.Status.Assessment.Candidates,
+			iter8v1alpha2.VersionAssessment{Name: candidate})
+	}
+	return instance
+}
+
+// reviewsDeployment returns deployment of reviews with pods labeled by app and version
+func reviewsDeployment(name, namespace, version string) *appsv1.Deployment {
+	d := &appsv1.Deployment{}
+	d.Name = name
+	d.Namespace = namespace
+	d.Spec.Template.Labels = map[string]string{"app": "reviews", "version": version}
+	return d
+}
+
+func TestForeignRules(t *testing.T) {
+	tests := []struct {
+		name       string
+		instance   *iter8v1alpha2.Experiment
+		baseline   runtime.Object
+		candidates []runtime.Object
+		// hosts and subsets of rules by namespace, subsets by name and version label of their pods
+		wantHosts   map[string]string
+		wantSubsets map[string]map[string]string
+	}{
+		{
+			name:       "versions in namespace of service",
+			instance:   foreignExperiment("reviews-v1", "reviews-v2"),
+			baseline:   reviewsDeployment("reviews-v1", "bookinfo", "v1"),
+			candidates: []runtime.Object{reviewsDeployment("reviews-v2", "bookinfo", "v2")},
+			wantHosts:  map[string]string{},
+		},
+		{
+			name:     "versions sharing a namespace",
+			instance: foreignExperiment("reviews-v1", "preview/reviews-v2", "preview/reviews-v3"),
+			baseline: reviewsDeployment("reviews-v1", "bookinfo", "v1"),
+			candidates: []runtime.Object{
+				reviewsDeployment("reviews-v2", "preview", "v2"),
+				reviewsDeployment("reviews-v3", "preview", "v3"),
+			},
+			wantHosts: map[string]string{"preview": "reviews.preview.svc.cluster.local"},
+			wantSubsets: map[string]map[string]string{
+				"preview": {"iter8-candidate-0": "v2", "iter8-candidate-1": "v3"},
+			},
+		},
+		{
+			name:       "baseline in another namespace",
+			instance:   foreignExperiment("stable/reviews-v1", "reviews-v2"),
+			baseline:   reviewsDeployment("reviews-v1", "stable", "v1"),
+			candidates: []runtime.Object{reviewsDeployment("reviews-v2", "bookinfo", "v2")},
+			wantHosts:  map[string]string{"stable": "reviews.stable.svc.cluster.local"},
+			wantSubsets: map[string]map[string]string{
+				"stable": {"iter8-baseline": "v1"},
+			},
+		},
+		{
+			name:     "versions in different namespaces",
+			instance: foreignExperiment("stable/reviews-v1", "preview/reviews-v2"),
+			candidates: []runtime.Object{
+				reviewsDeployment("reviews-v2", "preview", "v2"),
+			},
+			wantHosts: map[string]string{"preview": "reviews.preview.svc.cluster.local"},
+			wantSubsets: map[string]map[string]string{
+				"preview": {"iter8-candidate-0": "v2"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rules, err := foreignRules(tt.instance, tt.baseline, tt.candidates)
+			if err != nil {
+				t.Fatalf("foreignRules() error = %v", err)
+			}
+
+			hosts := make(map[string]string)
+			for namespace, dr := range rules {
+				hosts[namespace] = dr.Spec.Host
+				if dr.Namespace != namespace || dr.Name != GetRoutingRuleName(getRouterID(tt.instance)) {
+					t.Errorf("rule %s/%s, want %s/%s", dr.Namespace, dr.Name, namespace, GetRoutingRuleName(getRouterID(tt.instance)))
+				}
+				if !foreignRuleOwned(dr, tt.instance) {
+					t.Errorf("rule in %s should be owned by the experiment", namespace)
+				}
+
+				subsets := make(map[string]string)
+				for _, subset := range dr.Spec.Subsets {
+					subsets[subset.Name] = subset.Labels["version"]
+				}
+				if !reflect.DeepEqual(subsets, tt.wantSubsets[namespace]) {
+					t.Errorf("subsets in %s = %v, want %v", namespace, subsets, tt.wantSubsets[namespace])
+				}
+			}
+			if !reflect.DeepEqual(hosts, tt.wantHosts) {
+				t.Errorf("hosts = %v, want %v", hosts, tt.wantHosts)
+			}
+		})
+	}
+}
+
+func TestForeignDestination(t *testing.T) {
+	// service reviews in namespace preview selects pods of both versions by app label,
+	// so each version is reached by its own subset
+	instance := foreignExperiment("reviews-v1", "preview/reviews-v2", "preview/reviews-v3")
+	h := deploymentHandler{}
+
+	tests := []struct {
+		name       string
+		opts       destinationOptions
+		wantHost   string
+		wantSubset string
+	}{
+		{name: "version in namespace of service",
+			opts:     destinationOptions{name: "reviews-v1", subset: baselineSubset(instance)},
+			wantHost: "reviews.bookinfo.svc.cluster.local", wantSubset: "iter8-baseline"},
+		{name: "first version in another namespace",
+			opts:     destinationOptions{name: "preview/reviews-v2", subset: candidateSubset(instance, "preview/reviews-v2")},
+			wantHost: "reviews.preview.svc.cluster.local", wantSubset: "iter8-candidate-0"},
+		{name: "second version in the same namespace",
+			opts:     destinationOptions{name: "preview/reviews-v3", subset: candidateSubset(instance, "preview/reviews-v3")},
+			wantHost: "reviews.preview.svc.cluster.local", wantSubset: "iter8-candidate-1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			destination := h.buildDestination(instance, tt.opts).Destination
+			if destination.Host != tt.wantHost || destination.Subset != tt.wantSubset {
+				t.Errorf("destination = %s/%s, want %s/%s", destination.Host, destination.Subset, tt.wantHost, tt.wantSubset)
+			}
+		})
+	}
+}
@@ -17,9 +17,10 @@ package istio
 // This file contains helper functions for composing istio routing rules
 
 import (
+	"strings"
+
 	networkingv1alpha3 "istio.io/api/networking/v1alpha3"
 	"istio.io/client-go/pkg/apis/networking/v1alpha3"
-	appsv1 "k8s.io/api/apps/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	iter8v1alpha2 "github.com/iter8-tools/iter8/pkg/apis/iter8/v1alpha2"
@@ -127,11 +128,23 @@ func (b *DestinationRuleBuilder) InitSubsets() *DestinationRuleBuilder {
 	return b
 }
 
+// RemoveSubsets removes subsets with the names from dr
+func (b *DestinationRuleBuilder) RemoveSubsets(names map[string]bool) *DestinationRuleBuilder {
+	subsets := make([]*networkingv1alpha3.Subset, 0)
+	for _, subset := range b.Spec.Subsets {
+		if !names[subset.Name] {
+			subsets = append(subsets, subset)
+		}
+	}
+	b.Spec.Subsets = subsets
+	return b
+}
+
 // WithSubset converts stable dr to progressing dr
-func (b *DestinationRuleBuilder) WithSubset(d *appsv1.Deployment, subsetName string) *DestinationRuleBuilder {
+func (b *DestinationRuleBuilder) WithSubset(podLabels map[string]string, subsetName string) *DestinationRuleBuilder {
 	b.Spec.Subsets = append(b.Spec.Subsets, &networkingv1alpha3.Subset{
 		Name:   subsetName,
-		Labels: d.Spec.Template.ObjectMeta.Labels,
+		Labels: podLabels,
 	})
 
 	return b
@@ -215,12 +228,55 @@ func (b *VirtualServiceBuilder) RemoveExperimentLabel() *VirtualServiceBuilder {
 	return b
 }
 
+// WithDisjointExperiments records experiments in disjoint traffic segments sharing the vs
+func (b *VirtualServiceBuilder) WithDisjointExperiments(exps []string) *VirtualServiceBuilder {
+	if len(exps) == 0 {
+		delete(b.ObjectMeta.Annotations, disjointExperiments)
+		return b
+	}
+	if b.ObjectMeta.GetAnnotations() == nil {
+		b.ObjectMeta.SetAnnotations(map[string]string{})
+	}
+	b.ObjectMeta.Annotations[disjointExperiments] = strings.Join(exps, ",")
+	return b
+}
+
 // WithHTTPRoute adds route to http route list
 func (b *VirtualServiceBuilder) WithHTTPRoute(route *networkingv1alpha3.HTTPRoute) *VirtualServiceBuilder {
 	b.Spec.Http = append(b.Spec.Http, route)
 	return b
 }
 
+// WithFirstHTTPRoute adds route to the front of http route list
+func (b *VirtualServiceBuilder) WithFirstHTTPRoute(route *networkingv1alpha3.HTTPRoute) *VirtualServiceBuilder {
+	b.Spec.Http = append([]*networkingv1alpha3.HTTPRoute{route}, b.Spec.Http...)
+	return b
+}
+
+// WithHTTPRouteBefore adds route ahead of the route with the name, or to the end of http route list if it is absent
+func (b *VirtualServiceBuilder) WithHTTPRouteBefore(route *networkingv1alpha3.HTTPRoute, name string) *VirtualServiceBuilder {
+	for i := range b.Spec.Http {
+		if b.Spec.Http[i].Name == name {
+			routes := append([]*networkingv1alpha3.HTTPRoute{}, b.Spec.Http[:i]...)
+			b.Spec.Http = append(append(routes, route), b.Spec.Http[i:]...)
+			return b
+		}
+	}
+	return b.WithHTTPRoute(route)
+}
+
+// RemoveHTTPRoute removes route with the name from http route list
+func (b *VirtualServiceBuilder) RemoveHTTPRoute(name string) *VirtualServiceBuilder {
+	routes := make([]*networkingv1alpha3.HTTPRoute, 0)
+	for _, route := range b.Spec.Http {
+		if route.Name != name {
+			routes = append(routes, route)
+		}
+	}
+	b.Spec.Http = routes
+	return b
+}
+
 func (b *VirtualServiceBuilder) InitGateways() *VirtualServiceBuilder {
 	b.Spec.Gateways = []string{}
 	return b
@@ -333,6 +389,27 @@ func (b *HTTPRouteBuilder) WithHTTPMatch(httpMatch []*iter8v1alpha2.HTTPMatchReq
 	return b
 }
 
+// WithMirror mirrors traffic of the route to destination; nil removes mirroring
+func (b *HTTPRouteBuilder) WithMirror(d *networkingv1alpha3.Destination) *HTTPRouteBuilder {
+	b.Mirror = d
+	return b
+}
+
+// WithRequestHeader sets header of requests matching the route
+func (b *HTTPRouteBuilder) WithRequestHeader(key, value string) *HTTPRouteBuilder {
+	if b.Headers == nil {
+		b.Headers = &networkingv1alpha3.Headers{}
+	}
+	if b.Headers.Request == nil {
+		b.Headers.Request = &networkingv1alpha3.Headers_HeaderOperations{}
+	}
+	if b.Headers.Request.Set == nil {
+		b.Headers.Request.Set = make(map[string]string)
+	}
+	b.Headers.Request.Set[key] = value
+	return b
+}
+
 func (b *HTTPRouteBuilder) Build() *networkingv1alpha3.HTTPRoute {
 	return (*networkingv1alpha3.HTTPRoute)(b)
 }
@@ -0,0 +1,130 @@
+/*
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package istio
+
+import (
+	networkingv1alpha3 "istio.io/api/networking/v1alpha3"
+	"istio.io/client-go/pkg/apis/networking/v1alpha3"
+
+	iter8v1alpha2 "github.com/iter8-tools/iter8/pkg/apis/iter8/v1alpha2"
+)
+
+// httpPort returns port of destinations in HTTP routes
+// nil if internal services expose more than one HTTP port, so that requests keep their ports
+func httpPort(instance *iter8v1alpha2.Experiment) *int32 {
+	var out *int32
+	count := 0
+	for _, port := range instance.Spec.Service.GetPorts() {
+		if port.GetProtocol() == iter8v1alpha2.PortProtocolHTTP {
+			number := port.Number
+			out = &number
+			count++
+		}
+	}
+	if count != 1 {
+		return nil
+	}
+	return out
+}
+
+// hasHTTPPort tells whether traffic of internal services is routed by HTTP routes
+func hasHTTPPort(instance *iter8v1alpha2.Experiment) bool {
+	ports := instance.Spec.Service.GetPorts()
+	if len(ports) == 0 {
+		return true
+	}
+	for _, port := range ports {
+		if port.GetProtocol() == iter8v1alpha2.PortProtocolHTTP {
+			return true
+		}
+	}
+	return false
+}
+
+// versionDestinations returns destination options of baseline and candidates with their weights in assessment
+func versionDestinations(instance *iter8v1alpha2.Experiment) []destinationOptions {
+	assessment := instance.Status.Assessment
+	out := []destinationOptions{{
+		name:   assessment.Baseline.Name,
+		weight: assessment.Baseline.Weight,
+		subset: baselineSubset(instance),
+	}}
+	for _, candidate := range assessment.Candidates {
+		out = append(out, destinationOptions{
+			name:   candidate.Name,
+			weight: candidate.Weight,
+			subset: candidateSubset(instance, candidate.Name),
+		})
+	}
+	return out
+}
+
+// updateL4Routes replaces TCP and TLS routes of ports exposed by internal services
+// with routes sending traffic to versions by their weights; routes of other ports are kept
+func (r *Router) updateL4Routes(vs *v1alpha3.VirtualService, instance *iter8v1alpha2.Experiment, versions []destinationOptions) {
+	owned := make(map[uint32]bool)
+	for _, port := range instance.Spec.Service.GetPorts() {
+		if port.GetProtocol() != iter8v1alpha2.PortProtocolHTTP {
+			owned[uint32(port.Number)] = true
+		}
+	}
+
+	tcpRoutes := make([]*networkingv1alpha3.TCPRoute, 0)
+	for _, route := range vs.Spec.GetTcp() {
+		if len(route.Match) != 1 || !owned[route.Match[0].Port] {
+			tcpRoutes = append(tcpRoutes, route)
+		}
+	}
+	tlsRoutes := make([]*networkingv1alpha3.TLSRoute, 0)
+	for _, route := range vs.Spec.GetTls() {
+		if len(route.Match) != 1 || !owned[route.Match[0].Port] {
+			tlsRoutes = append(tlsRoutes, route)
+		}
+	}
+
+	for _, port := range instance.Spec.Service.GetPorts() {
+		number := port.Number
+		destinations := make([]*networkingv1alpha3.RouteDestination, 0, len(versions))
+		for _, opts := range versions {
+			opts.port = &number
+			d := r.handler.buildDestination(instance, opts)
+			destinations = append(destinations, &networkingv1alpha3.RouteDestination{
+				Destination: d.Destination,
+				Weight:      d.Weight,
+			})
+		}
+
+		switch port.GetProtocol() {
+		case iter8v1alpha2.PortProtocolTCP:
+			tcpRoutes = append(tcpRoutes, &networkingv1alpha3.TCPRoute{
+				Match: []*networkingv1alpha3.L4MatchAttributes{{Port: uint32(number)}},
+				Route: destinations,
+			})
+		case iter8v1alpha2.PortProtocolTLS:
+			sniHosts := port.SNIHosts
+			if len(sniHosts) == 0 {
+				sniHosts = vs.Spec.GetHosts()
+			}
+			tlsRoutes = append(tlsRoutes, &networkingv1alpha3.TLSRoute{
+				Match: []*networkingv1alpha3.TLSMatchAttributes{{Port: uint32(number), SniHosts: sniHosts}},
+				Route: destinations,
+			})
+		}
+	}
+
+	vs.Spec.Tcp = tcpRoutes
+	vs.Spec.Tls = tlsRoutes
+}
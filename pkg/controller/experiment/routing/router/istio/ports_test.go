@@ -0,0 +1,162 @@
+/*
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package istio
+
+import (
+	"testing"
+
+	networkingv1alpha3 "istio.io/api/networking/v1alpha3"
+	"istio.io/client-go/pkg/apis/networking/v1alpha3"
+	corev1 "k8s.io/api/core/v1"
+
+	iter8v1alpha2 "github.com/iter8-tools/iter8/pkg/apis/iter8/v1alpha2"
+)
+
+func portsExperiment(ports ...iter8v1alpha2.ServicePort) *iter8v1alpha2.Experiment {
+	instance := &iter8v1alpha2.Experiment{}
+	instance.Name = "reviews-experiment"
+	instance.Namespace = "bookinfo"
+	instance.Spec.Service.ObjectReference = &corev1.ObjectReference{Name: "reviews", Namespace: "bookinfo"}
+	instance.Spec.Service.Baseline = "reviews-v1"
+	instance.Spec.Service.Candidates = []string{"reviews-v2"}
+	instance.Spec.Service.Ports = ports
+	instance.Status.Assessment = &iter8v1alpha2.Assessment{
+		Baseline:   iter8v1alpha2.VersionAssessment{Name: "reviews-v1", Weight: 80},
+		Candidates: []iter8v1alpha2.VersionAssessment{{Name: "reviews-v2", Weight: 20}},
+	}
+	return instance
+}
+
+func servicePort(number int32, protocol iter8v1alpha2.PortProtocolType, sniHosts ...string) iter8v1alpha2.ServicePort {
+	return iter8v1alpha2.ServicePort{Number: number, Protocol: &protocol, SNIHosts: sniHosts}
+}
+
+func TestUpdateL4Routes(t *testing.T) {
+	foreignTCP := &networkingv1alpha3.TCPRoute{
+		Match: []*networkingv1alpha3.L4MatchAttributes{{Port: 5000}},
+	}
+	staleTCP := &networkingv1alpha3.TCPRoute{
+		Match: []*networkingv1alpha3.L4MatchAttributes{{Port: 3306}},
+	}
+	staleTLS := &networkingv1alpha3.TLSRoute{
+		Match: []*networkingv1alpha3.TLSMatchAttributes{{Port: 443, SniHosts: []string{"reviews"}}},
+	}
+
+	tests := []struct {
+		name     string
+		ports    []iter8v1alpha2.ServicePort
+		tcp      []*networkingv1alpha3.TCPRoute
+		tls      []*networkingv1alpha3.TLSRoute
+		wantTCP  []uint32
+		wantTLS  []uint32
+		wantSNI  []string
+		kept     *networkingv1alpha3.TCPRoute
+		replaced bool
+	}{
+		{
+			name:  "http only",
+			ports: []iter8v1alpha2.ServicePort{servicePort(9080, iter8v1alpha2.PortProtocolHTTP)},
+			tcp:   []*networkingv1alpha3.TCPRoute{foreignTCP},
+			// routes of ports not exposed by the service are kept
+			wantTCP: []uint32{5000},
+			kept:    foreignTCP,
+		},
+		{
+			name: "tcp replaces stale route",
+			ports: []iter8v1alpha2.ServicePort{
+				servicePort(9080, iter8v1alpha2.PortProtocolHTTP),
+				servicePort(3306, iter8v1alpha2.PortProtocolTCP),
+			},
+			tcp:      []*networkingv1alpha3.TCPRoute{foreignTCP, staleTCP},
+			wantTCP:  []uint32{5000, 3306},
+			kept:     foreignTCP,
+			replaced: true,
+		},
+		{
+			name:    "tls defaults to hosts of virtual service",
+			ports:   []iter8v1alpha2.ServicePort{servicePort(443, iter8v1alpha2.PortProtocolTLS)},
+			tls:     []*networkingv1alpha3.TLSRoute{staleTLS},
+			wantTLS: []uint32{443},
+			wantSNI: []string{"reviews.bookinfo.svc.cluster.local"},
+		},
+		{
+			name:    "tls with sni hosts",
+			ports:   []iter8v1alpha2.ServicePort{servicePort(443, iter8v1alpha2.PortProtocolTLS, "reviews.example.com")},
+			wantTLS: []uint32{443},
+			wantSNI: []string{"reviews.example.com"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			instance := portsExperiment(tt.ports...)
+			vs := &v1alpha3.VirtualService{}
+			vs.Spec.Hosts = []string{"reviews.bookinfo.svc.cluster.local"}
+			vs.Spec.Tcp = tt.tcp
+			vs.Spec.Tls = tt.tls
+
+			r := &Router{handler: deploymentHandler{}}
+			r.updateL4Routes(vs, instance, versionDestinations(instance))
+
+			if len(vs.Spec.Tcp) != len(tt.wantTCP) {
+				t.Fatalf("got %d TCP routes, want %d", len(vs.Spec.Tcp), len(tt.wantTCP))
+			}
+			for i, port := range tt.wantTCP {
+				if got := vs.Spec.Tcp[i].Match[0].Port; got != port {
+					t.Errorf("TCP route %d on port %d, want %d", i, got, port)
+				}
+			}
+			if tt.kept != nil && vs.Spec.Tcp[0] != tt.kept {
+				t.Errorf("route of port not exposed by the service should be kept")
+			}
+			if len(vs.Spec.Tls) != len(tt.wantTLS) {
+				t.Fatalf("got %d TLS routes, want %d", len(vs.Spec.Tls), len(tt.wantTLS))
+			}
+			for i, port := range tt.wantTLS {
+				match := vs.Spec.Tls[i].Match[0]
+				if match.Port != port {
+					t.Errorf("TLS route %d on port %d, want %d", i, match.Port, port)
+				}
+				if len(match.SniHosts) != len(tt.wantSNI) || match.SniHosts[0] != tt.wantSNI[0] {
+					t.Errorf("TLS route %d SNI hosts %v, want %v", i, match.SniHosts, tt.wantSNI)
+				}
+			}
+
+			// generated routes split traffic between subsets on their own ports
+			routes := [][]*networkingv1alpha3.RouteDestination{}
+			for _, route := range vs.Spec.Tcp {
+				if route != tt.kept {
+					routes = append(routes, route.Route)
+				}
+			}
+			for _, route := range vs.Spec.Tls {
+				routes = append(routes, route.Route)
+			}
+			for _, route := range routes {
+				if len(route) != 2 {
+					t.Fatalf("got %d destinations, want 2", len(route))
+				}
+				if route[0].Destination.Subset != SubsetBaseline || route[0].Weight != 80 ||
+					route[1].Destination.Subset != CandidateSubsetName(0) || route[1].Weight != 20 {
+					t.Errorf("unexpected destinations %v", route)
+				}
+			}
+			if tt.replaced && vs.Spec.Tcp[1] == staleTCP {
+				t.Errorf("stale route of exposed port should be replaced")
+			}
+		})
+	}
+}
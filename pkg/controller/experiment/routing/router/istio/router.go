@@ -24,13 +24,14 @@ import (
 	networkingv1alpha3 "istio.io/api/networking/v1alpha3"
 	"istio.io/client-go/pkg/apis/networking/v1alpha3"
 	istioclient "istio.io/client-go/pkg/clientset/versioned"
-	appsv1 "k8s.io/api/apps/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
 	runtime "k8s.io/apimachinery/pkg/runtime"
 
 	iter8v1alpha2 "github.com/iter8-tools/iter8/pkg/apis/iter8/v1alpha2"
 	"github.com/iter8-tools/iter8/pkg/controller/experiment/routing/router"
+	"github.com/iter8-tools/iter8/pkg/controller/experiment/targets"
 	"github.com/iter8-tools/iter8/pkg/controller/experiment/util"
 )
 
@@ -39,6 +40,10 @@ const (
 	routeNameExperiment = "iter8-experiment"
 	// name of route receving non-experimental traffic
 	routeNameBase = "iter8-base"
+	// name of route sending test traffic to candidate in blue/green validation
+	routeNameTest = "iter8-test"
+	// prefix of names of routes receiving traffic of segments
+	routeNameSegment = "iter8-segment"
 
 	// the key of label used to reference to the router id
 	routerID = "iter8-tools/router"
@@ -146,6 +151,8 @@ func GetRouter(ctx context.Context, instance *iter8v1alpha2.Experiment) router.I
 	switch instance.Spec.Service.Kind {
 	case "Service":
 		out.handler = serviceHandler{}
+	case "ServiceEntry":
+		out.handler = serviceEntryHandler{}
 	default:
 		out.handler = deploymentHandler{}
 	}
@@ -185,6 +192,9 @@ func (r *Router) Fetch(ctx context.Context, instance *iter8v1alpha2.Experiment)
 
 // UpdateRouteWithBaseline updates routing rules with runtime object of baseline
 func (r *Router) UpdateRouteWithBaseline(ctx context.Context, instance *iter8v1alpha2.Experiment, baseline runtime.Object) (err error) {
+	if sharable(r.rules.virtualService, instance) {
+		return r.joinRules(ctx, instance, baseline)
+	}
 	if r.rules.isProgressing() || r.rules.isInitializing() {
 		return nil
 	}
@@ -201,7 +211,7 @@ func (r *Router) UpdateRouteWithBaseline(ctx context.Context, instance *iter8v1a
 	// inject internal host
 	if service.Name != "" {
 		vsb = vsb.
-			WithHosts([]string{util.ServiceToFullHostName(service.Name, instance.ServiceNamespace())}).
+			WithHosts([]string{util.GetDefaultHost(instance)}).
 			WithMeshGateway()
 	}
 
@@ -223,30 +233,45 @@ func (r *Router) UpdateRouteWithBaseline(ctx context.Context, instance *iter8v1a
 		vsb = vsb.WithHosts(hosts).WithGateways(gateways)
 	}
 
-	experimentRoute := NewEmptyHTTPRoute(routeNameExperiment)
-
-	// inject baseline destination to route
-	baselineDestination := r.handler.buildDestination(instance, destinationOptions{
-		name:   service.Baseline,
+	baselineOptions := destinationOptions{
+		name:   instance.GetBaseline(),
 		weight: 100,
-		subset: SubsetBaseline,
-		port:   service.Port,
-	})
-	experimentRoute = experimentRoute.WithDestination(baselineDestination)
-
-	// inject match clauses to route
-	trafficControl := instance.Spec.TrafficControl
-	if trafficControl != nil && trafficControl.Match != nil && len(trafficControl.Match.HTTP) > 0 {
-		experimentRoute = experimentRoute.WithHTTPMatch(trafficControl.Match.HTTP)
+		subset: baselineSubset(instance),
 	}
+	if hasHTTPPort(instance) {
+		experimentRoute := NewEmptyHTTPRoute(routeName(instance, routeNameExperiment))
+
+		// inject baseline destination to route
+		httpOptions := baselineOptions
+		httpOptions.port = httpPort(instance)
+		baselineDestination := r.handler.buildDestination(instance, httpOptions)
+		experimentRoute = experimentRoute.WithDestination(baselineDestination)
+
+		// inject match clauses to route
+		trafficControl := instance.Spec.TrafficControl
+		if trafficControl != nil && trafficControl.Match != nil && len(trafficControl.Match.HTTP) > 0 {
+			experimentRoute = experimentRoute.WithHTTPMatch(trafficControl.Match.HTTP)
+		}
 
-	// update virtualservice with experiment route
-	vsb = vsb.WithHTTPRoute(experimentRoute.Build())
+		// inject routes of segments ahead of experiment route
+		for _, segmentRoute := range r.buildSegmentRoutes(instance, httpOptions) {
+			vsb = vsb.WithHTTPRoute(segmentRoute)
+		}
+
+		// update virtualservice with experiment route
+		vsb = vsb.WithHTTPRoute(experimentRoute.Build())
+
+		// inject base-route if matching clauses exist
+		if trafficControl != nil && trafficControl.Match != nil && len(trafficControl.Match.HTTP) > 0 {
+			baseRoute := NewEmptyHTTPRoute(routeNameBase).WithDestination(baselineDestination)
+			vsb = vsb.WithHTTPRoute(baseRoute.Build())
+		}
+	}
 
-	// inject base-route if matching clauses exist
-	if trafficControl != nil && trafficControl.Match != nil && len(trafficControl.Match.HTTP) > 0 {
-		baseRoute := NewEmptyHTTPRoute(routeNameBase).WithDestination(baselineDestination)
-		vsb = vsb.WithHTTPRoute(baseRoute.Build())
+	// inject tcp and tls routes
+	r.updateL4Routes(vsb.Build(), instance, []destinationOptions{baselineOptions})
+	if instance.Spec.GetDisjoint() {
+		vsb = vsb.WithDisjointExperiments([]string{util.FullExperimentName(instance)})
 	}
 	vs := (*v1alpha3.VirtualService)(nil)
 	if _, ok := vsb.GetLabels()[experimentInit]; ok {
@@ -265,10 +290,13 @@ func (r *Router) UpdateRouteWithBaseline(ctx context.Context, instance *iter8v1a
 
 	// Update destinationrule
 	if r.handler.requireDestinationRule() {
+		drb, err := withTargetSubset(NewDestinationRuleBuilder(r.rules.destinationRule).InitSubsets(),
+			instance, baseline, true)
+		if err != nil {
+			return err
+		}
 		dr := (*v1alpha3.DestinationRule)(nil)
-		drb := NewDestinationRuleBuilder(r.rules.destinationRule).
-			InitSubsets().
-			WithSubset(baseline.(*appsv1.Deployment), SubsetBaseline).
+		drb = drb.
 			WithInitializingLabel().
 			WithRouterRegistered(getRouterID(instance)).
 			WithExperimentRegistered(util.FullExperimentName(instance))
@@ -287,6 +315,9 @@ func (r *Router) UpdateRouteWithBaseline(ctx context.Context, instance *iter8v1a
 		}
 		r.rules.destinationRule = dr.DeepCopy()
 	}
+	if err := r.updateForeignRules(ctx, instance, baseline, nil, false); err != nil {
+		return err
+	}
 
 	instance.Status.Assessment.Baseline.Weight = 100
 	return nil
@@ -294,29 +325,45 @@ func (r *Router) UpdateRouteWithBaseline(ctx context.Context, instance *iter8v1a
 
 // UpdateRouteWithCandidates updates routing rules with runtime objects of candidates
 func (r *Router) UpdateRouteWithCandidates(ctx context.Context, instance *iter8v1alpha2.Experiment, candidates []runtime.Object) (err error) {
-	if r.rules.isProgressing() {
+	if r.rules.isProgressing() && !instance.Spec.GetDisjoint() {
 		return
 	}
 
 	vs := r.rules.virtualService
 
-	route := getExperimentRoute(vs)
-	if route == nil {
+	route := getExperimentRoute(vs, instance)
+	if route == nil && hasHTTPPort(instance) {
 		return fmt.Errorf("Fail to update route with candidates: experiment route missing in vs")
 	}
-	rb := NewHTTPRoute(route)
 
-	service := instance.Spec.Service
+	// rules shared by experiments in disjoint traffic segments are progressing once any of them is;
+	// candidates of this experiment are in its own route once they are added
+	if instance.Spec.GetDisjoint() && len(route.GetRoute()) > 1 {
+		return
+	}
+
 	// update candidates
-	for i, candidate := range instance.Spec.Candidates {
-		destination := r.handler.buildDestination(instance, destinationOptions{
-			name:   candidate,
-			weight: 0,
-			subset: CandidateSubsetName(i),
-			port:   service.Port,
-		})
+	if route != nil {
+		rb := NewHTTPRoute(route)
+		for _, candidate := range instance.Status.Assessment.Candidates {
+			destination := r.handler.buildDestination(instance, destinationOptions{
+				name:   candidate.Name,
+				weight: 0,
+				subset: candidateSubset(instance, candidate.Name),
+				port:   httpPort(instance),
+			})
+
+			rb = rb.WithDestination(destination)
+		}
+	}
+	r.addCandidatesToSegmentRoutes(vs, instance)
+	r.updateStickyRoutes(vs, instance)
+	r.updateBlueGreenRoutes(vs, instance)
+	r.updateL4Routes(vs, instance, versionDestinations(instance))
 
-		rb = rb.WithDestination(destination)
+	// bucket users ahead of routing by buckets
+	if err = r.createStickyFilter(ctx, instance); err != nil {
+		return
 	}
 
 	// update vs to progressing
@@ -335,8 +382,10 @@ func (r *Router) UpdateRouteWithCandidates(ctx context.Context, instance *iter8v
 	// Update destination rule to progressing
 	if r.handler.requireDestinationRule() {
 		drb := NewDestinationRuleBuilder(r.rules.destinationRule)
-		for i, candidate := range candidates {
-			drb = drb.WithSubset(candidate.(*appsv1.Deployment), CandidateSubsetName(i))
+		for _, candidate := range candidates {
+			if drb, err = withTargetSubset(drb, instance, candidate, false); err != nil {
+				return
+			}
 		}
 
 		dr := drb.WithProgressingLabel().Build()
@@ -349,15 +398,55 @@ func (r *Router) UpdateRouteWithCandidates(ctx context.Context, instance *iter8v
 		}
 		r.rules.destinationRule = dr.DeepCopy()
 	}
+	err = r.updateForeignRules(ctx, instance, nil, candidates, false)
+	return
+}
+
+// RefreshSubsets updates routing rules with latest pod templates of baseline and candidates
+func (r *Router) RefreshSubsets(ctx context.Context, instance *iter8v1alpha2.Experiment, baseline runtime.Object, candidates []runtime.Object) (err error) {
+	if !r.handler.requireDestinationRule() || !r.rules.isProgressing() {
+		return
+	}
+
+	drb := NewDestinationRuleBuilder(r.rules.destinationRule)
+	if instance.Spec.GetDisjoint() {
+		// subsets of other experiments are kept
+		subsets := candidateSubsets(instance)
+		subsets[baselineSubset(instance)] = true
+		drb = drb.RemoveSubsets(subsets)
+	} else {
+		drb = drb.InitSubsets()
+	}
+	if drb, err = withTargetSubset(drb, instance, baseline, true); err != nil {
+		return
+	}
+	for _, candidate := range candidates {
+		if drb, err = withTargetSubset(drb, instance, candidate, false); err != nil {
+			return
+		}
+	}
+
+	dr, err := r.client.NetworkingV1alpha3().
+		DestinationRules(r.rules.destinationRule.GetNamespace()).
+		Update(ctx, drb.Build(), metav1.UpdateOptions{})
+	if err != nil {
+		return
+	}
+	r.rules.destinationRule = dr.DeepCopy()
+	err = r.updateForeignRules(ctx, instance, baseline, candidates, true)
 	return
 }
 
 // UpdateRouteWithTrafficUpdate updates routing rules with new traffic state from assessment
 func (r *Router) UpdateRouteWithTrafficUpdate(ctx context.Context, instance *iter8v1alpha2.Experiment) (err error) {
 	vs := r.rules.virtualService
-	if route := getExperimentRoute(vs); route != nil {
+	if route := getExperimentRoute(vs, instance); route != nil {
 		r.updateRouteFromExperiment(route, instance)
 	}
+	r.updateSegmentRoutes(vs, instance)
+	r.updateStickyRoutes(vs, instance)
+	r.updateBlueGreenRoutes(vs, instance)
+	r.updateL4Routes(vs, instance, versionDestinations(instance))
 
 	vs, err = r.client.NetworkingV1alpha3().VirtualServices(vs.Namespace).Update(ctx, vs, metav1.UpdateOptions{})
 	if err != nil {
@@ -375,38 +464,41 @@ func (r *Router) UpdateRouteToStable(ctx context.Context, instance *iter8v1alpha
 		return nil
 	}
 
-	if instance.Spec.GetCleanup() && r.rules.isInit() {
-		// delete routing rules
-		if err = r.client.NetworkingV1alpha3().VirtualServices(r.rules.virtualService.Namespace).
-			Delete(ctx, r.rules.virtualService.Name, metav1.DeleteOptions{}); err != nil {
-			r.logger.Info("Err in deleting vs", "err", err)
-			return
-		}
+	// users are no longer assigned stickily once the experiment is over
+	if err = r.deleteStickyFilter(ctx, instance); err != nil {
+		return err
+	}
 
-		if r.handler.requireDestinationRule() {
-			if err = r.client.NetworkingV1alpha3().DestinationRules(r.rules.destinationRule.Namespace).
-				Delete(ctx, r.rules.destinationRule.Name, metav1.DeleteOptions{}); err != nil {
-				r.logger.Info("Err in deleting dr", "err", err)
-				return
-			}
+	if instance.Spec.GetDisjoint() {
+		return r.leaveRules(ctx, instance)
+	}
+
+	if instance.Spec.GetCleanup() && r.rules.isInit() {
+		if err = r.deleteRules(ctx); err != nil {
+			return err
 		}
+		err = r.stableForeignRules(ctx, instance, false)
 	} else {
 		// only applied to progressing(fully configured) routing rules
 		// otherwise, the routing rule will be remained as its last state
 		vs := r.rules.virtualService
 		if r.rules.isProgressing() {
-			// retain experiment route only, and rename it to base route
-			route := getExperimentRoute(vs)
+			// retain experiment route and routes of segments only, and rename experiment route to base route
+			route := getExperimentRoute(vs, instance)
 
 			if route != nil {
+				segmentRoutes := r.stableSegmentRoutes(vs, instance)
 				r.updateRouteFromExperiment(route, instance)
 				route.Name = ""
 				route.Match = nil
-				vs = NewVirtualServiceBuilder(vs).
-					InitHTTPRoutes().
-					WithHTTPRoute(route).
-					Build()
+				route.Mirror = nil
+				vsb := NewVirtualServiceBuilder(vs).InitHTTPRoutes()
+				for _, segmentRoute := range segmentRoutes {
+					vsb = vsb.WithHTTPRoute(segmentRoute)
+				}
+				vs = vsb.WithHTTPRoute(route).Build()
 			}
+			r.updateL4Routes(vs, instance, versionDestinations(instance))
 		}
 
 		// update vs
@@ -431,42 +523,99 @@ func (r *Router) UpdateRouteToStable(ctx context.Context, instance *iter8v1alpha
 				return err
 			}
 		}
+		err = r.stableForeignRules(ctx, instance, true)
 	}
-	return nil
+	return err
+}
+
+// deleteRules deletes routing rules from cluster
+func (r *Router) deleteRules(ctx context.Context) (err error) {
+	if err = r.client.NetworkingV1alpha3().VirtualServices(r.rules.virtualService.Namespace).
+		Delete(ctx, r.rules.virtualService.Name, metav1.DeleteOptions{}); err != nil {
+		r.logger.Info("Err in deleting vs", "err", err)
+		return
+	}
+
+	if r.handler.requireDestinationRule() {
+		if err = r.client.NetworkingV1alpha3().DestinationRules(r.rules.destinationRule.Namespace).
+			Delete(ctx, r.rules.destinationRule.Name, metav1.DeleteOptions{}); err != nil {
+			r.logger.Info("Err in deleting dr", "err", err)
+			return
+		}
+	}
+	return
 }
 
 func (r *Router) updateRouteFromExperiment(route *networkingv1alpha3.HTTPRoute, instance *iter8v1alpha2.Experiment) {
+	r.updateRouteFromAssessment(route, instance, instance.Status.Assessment)
+}
+
+// updateRouteFromAssessment sets destinations of the route by traffic split in the assessment
+func (r *Router) updateRouteFromAssessment(route *networkingv1alpha3.HTTPRoute, instance *iter8v1alpha2.Experiment,
+	assessment *iter8v1alpha2.Assessment) {
 	rb := NewHTTPRoute(route).ClearRoute()
-	assessment := instance.Status.Assessment
 
 	// update baseline
 	baselineDestination := r.handler.buildDestination(instance, destinationOptions{
 		name:   assessment.Baseline.Name,
 		weight: assessment.Baseline.Weight,
-		subset: SubsetBaseline,
-		port:   instance.Spec.Service.Port,
+		subset: baselineSubset(instance),
+		port:   httpPort(instance),
 	})
 
 	rb = rb.WithDestination(baselineDestination)
 
 	// update candidates
-	for i, candidate := range assessment.Candidates {
+	for _, candidate := range assessment.Candidates {
 		destination := r.handler.buildDestination(instance, destinationOptions{
 			name:   candidate.Name,
 			weight: candidate.Weight,
-			subset: CandidateSubsetName(i),
-			port:   instance.Spec.Service.Port,
+			subset: candidateSubset(instance, candidate.Name),
+			port:   httpPort(instance),
 		})
 
 		rb = rb.WithDestination(destination)
 	}
 }
 
-func getExperimentRoute(vs *v1alpha3.VirtualService) *networkingv1alpha3.HTTPRoute {
+// updateBlueGreenRoutes sends test traffic and mirrored traffic to candidate while it is validated in blue_green strategy,
+// and removes them otherwise
+func (r *Router) updateBlueGreenRoutes(vs *v1alpha3.VirtualService, instance *iter8v1alpha2.Experiment) {
+	vsb := NewVirtualServiceBuilder(vs).RemoveHTTPRoute(routeName(instance, routeNameTest))
+	route := getExperimentRoute(vs, instance)
+	if route != nil {
+		NewHTTPRoute(route).WithMirror(nil)
+	}
+
+	if !instance.InBlueGreenValidation() || len(instance.GetCandidates()) == 0 {
+		return
+	}
+
+	destination := r.handler.buildDestination(instance, destinationOptions{
+		name:   instance.GetCandidates()[0],
+		weight: 100,
+		subset: candidateSubset(instance, instance.GetCandidates()[0]),
+		port:   httpPort(instance),
+	})
+
+	if bg := instance.Spec.TrafficControl.BlueGreen; bg != nil && bg.TestMatch != nil && len(bg.TestMatch.HTTP) > 0 {
+		testRoute := NewEmptyHTTPRoute(routeName(instance, routeNameTest)).
+			WithHTTPMatch(bg.TestMatch.HTTP).
+			WithDestination(destination)
+		vsb.WithFirstHTTPRoute(testRoute.Build())
+	}
+
+	if route != nil && instance.Spec.GetMirror() {
+		NewHTTPRoute(route).WithMirror(destination.Destination)
+	}
+}
+
+func getExperimentRoute(vs *v1alpha3.VirtualService, instance *iter8v1alpha2.Experiment) *networkingv1alpha3.HTTPRoute {
 	httproutes := vs.Spec.GetHttp()
 	experimentRouteIndex := -1
+	name := routeName(instance, routeNameExperiment)
 	for i := range httproutes {
-		if httproutes[i].Name == routeNameExperiment {
+		if httproutes[i].Name == name {
 			experimentRouteIndex = i
 			break
 		}
@@ -480,11 +629,44 @@ func getExperimentRoute(vs *v1alpha3.VirtualService) *networkingv1alpha3.HTTPRou
 	return httproutes[experimentRouteIndex]
 }
 
-// CandidateSubsetName returns subset name of a candidate with respect to its index in service spec
+// CandidateSubsetName returns subset name of a candidate with respect to its stable index in assessment
 func CandidateSubsetName(idx int) string {
 	return SubsetCandidate + "-" + strconv.Itoa(idx)
 }
 
+// withTargetSubset adds subset of a target object to the destination rule
+// Targets in namespaces other than that of service have subsets in destination rules of their own namespaces
+func withTargetSubset(drb *DestinationRuleBuilder, instance *iter8v1alpha2.Experiment,
+	obj runtime.Object, baseline bool) (*DestinationRuleBuilder, error) {
+	version, subset, err := targetSubset(instance, obj, baseline)
+	if err != nil {
+		return nil, err
+	}
+	if instance.CrossNamespace(version) {
+		return drb, nil
+	}
+	return drb.WithSubset(subset.Labels, subset.Name), nil
+}
+
+// targetSubset returns version of a target object and its subset, selecting pods by labels of its pod template
+func targetSubset(instance *iter8v1alpha2.Experiment, obj runtime.Object,
+	baseline bool) (string, *networkingv1alpha3.Subset, error) {
+	accessor, err := meta.Accessor(obj)
+	if err != nil {
+		return "", nil, err
+	}
+	version := instance.VersionOf(accessor.GetName(), accessor.GetNamespace())
+	podLabels, err := targets.PodTemplateLabels(obj, instance.Spec.Service.GetPodTemplateLabelsPath())
+	if err != nil {
+		return "", nil, err
+	}
+	subset := baselineSubset(instance)
+	if !baseline {
+		subset = candidateSubset(instance, version)
+	}
+	return version, &networkingv1alpha3.Subset{Name: subset, Labels: podLabels}, nil
+}
+
 // returns the id of router used by this experiment
 func getRouterID(instance *iter8v1alpha2.Experiment) string {
 	nwk := instance.Spec.Networking
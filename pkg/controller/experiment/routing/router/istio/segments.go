@@ -0,0 +1,98 @@
+/*
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package istio
+
+// This file contains functions used for routing segments of requests with their own traffic split
+
+import (
+	networkingv1alpha3 "istio.io/api/networking/v1alpha3"
+	"istio.io/client-go/pkg/apis/networking/v1alpha3"
+
+	iter8v1alpha2 "github.com/iter8-tools/iter8/pkg/apis/iter8/v1alpha2"
+)
+
+// segmentRouteName returns name of route of the segment
+func segmentRouteName(name string) string {
+	return routeNameSegment + "-" + name
+}
+
+// getSegmentRoute returns route of the segment in vs; nil if not found
+func getSegmentRoute(vs *v1alpha3.VirtualService, name string) *networkingv1alpha3.HTTPRoute {
+	for _, route := range vs.Spec.GetHttp() {
+		if route.Name == segmentRouteName(name) {
+			return route
+		}
+	}
+	return nil
+}
+
+// buildSegmentRoutes returns routes of segments sending all traffic to baseline
+// Requests in a segment are labeled with name of the segment, so that they are assessed separately
+func (r *Router) buildSegmentRoutes(instance *iter8v1alpha2.Experiment, baselineOptions destinationOptions) []*networkingv1alpha3.HTTPRoute {
+	out := make([]*networkingv1alpha3.HTTPRoute, 0)
+	for _, segment := range instance.Spec.GetSegments() {
+		route := NewEmptyHTTPRoute(segmentRouteName(segment.Name)).
+			WithDestination(r.handler.buildDestination(instance, baselineOptions)).
+			WithHTTPMatch(segment.Match.HTTP).
+			WithRequestHeader(iter8v1alpha2.SegmentHeader, segment.Name)
+		out = append(out, route.Build())
+	}
+	return out
+}
+
+// addCandidatesToSegmentRoutes adds destinations of candidates with no traffic to routes of segments
+func (r *Router) addCandidatesToSegmentRoutes(vs *v1alpha3.VirtualService, instance *iter8v1alpha2.Experiment) {
+	for _, segment := range instance.Spec.GetSegments() {
+		route := getSegmentRoute(vs, segment.Name)
+		if route == nil {
+			continue
+		}
+		rb := NewHTTPRoute(route)
+		for _, candidate := range instance.Status.Assessment.Candidates {
+			rb = rb.WithDestination(r.handler.buildDestination(instance, destinationOptions{
+				name:   candidate.Name,
+				weight: 0,
+				subset: candidateSubset(instance, candidate.Name),
+				port:   httpPort(instance),
+			}))
+		}
+	}
+}
+
+// updateSegmentRoutes sets destinations of routes of segments by their traffic split
+func (r *Router) updateSegmentRoutes(vs *v1alpha3.VirtualService, instance *iter8v1alpha2.Experiment) {
+	for i := range instance.Status.Segments {
+		segment := &instance.Status.Segments[i]
+		if route := getSegmentRoute(vs, segment.Name); route != nil {
+			r.updateRouteFromAssessment(route, instance, &segment.Assessment)
+		}
+	}
+}
+
+// stableSegmentRoutes returns routes of segments with their final traffic split
+// Match clauses are retained, while requests are no longer labeled with names of segments
+func (r *Router) stableSegmentRoutes(vs *v1alpha3.VirtualService, instance *iter8v1alpha2.Experiment) []*networkingv1alpha3.HTTPRoute {
+	out := make([]*networkingv1alpha3.HTTPRoute, 0)
+	r.updateSegmentRoutes(vs, instance)
+	for _, segment := range instance.Status.Segments {
+		if route := getSegmentRoute(vs, segment.Name); route != nil {
+			route.Headers = nil
+			route.Mirror = nil
+			out = append(out, route)
+		}
+	}
+	return out
+}
@@ -49,8 +49,8 @@ func (h serviceHandler) validateAndInit(drl *v1alpha3.DestinationRuleList, vsl *
 				vsLabel, vsok := vsl.Items[0].GetLabels()[experimentLabel]
 				if vsok {
 					expName := util.FullExperimentName(instance)
-					if vsLabel == expName {
-						// valid progressing rules found
+					if vsLabel == expName || sharable(&vsl.Items[0], instance) {
+						// valid progressing rules found, or rules shared by experiments in disjoint traffic segments
 						out.virtualService = vsl.Items[0].DeepCopy()
 					} else {
 						return nil, fmt.Errorf("Progressing rules of other experiment are detected")
@@ -70,8 +70,9 @@ func (h serviceHandler) validateAndInit(drl *v1alpha3.DestinationRuleList, vsl *
 
 // build destination content based on runtime target object and experiment info
 func (h serviceHandler) buildDestination(instance *iter8v1alpha2.Experiment, opts destinationOptions) *networkingv1alpha3.HTTPRouteDestination {
+	version := instance.VersionNamespacedName(opts.name)
 	b := NewHTTPRouteDestination().
-		WithHost(util.ServiceToFullHostName(opts.name, instance.ServiceNamespace())).
+		WithHost(util.ServiceToFullHostName(version.Name, version.Namespace)).
 		WithWeight(opts.weight)
 
 	if opts.port != nil {
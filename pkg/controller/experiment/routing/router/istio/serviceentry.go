@@ -0,0 +1,42 @@
+/*
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package istio
+
+import (
+	networkingv1alpha3 "istio.io/api/networking/v1alpha3"
+
+	iter8v1alpha2 "github.com/iter8-tools/iter8/pkg/apis/iter8/v1alpha2"
+)
+
+var _ destinationHandler = serviceEntryHandler{}
+
+// serviceEntryHandler splits egress traffic for a logical host between external hosts defined by ServiceEntries
+// Like serviceHandler, only VirtualService is required
+type serviceEntryHandler struct {
+	serviceHandler
+}
+
+// build destination content with external host of the version
+func (h serviceEntryHandler) buildDestination(instance *iter8v1alpha2.Experiment, opts destinationOptions) *networkingv1alpha3.HTTPRouteDestination {
+	b := NewHTTPRouteDestination().
+		WithHost(instance.VersionNamespacedName(opts.name).Name).
+		WithWeight(opts.weight)
+
+	if opts.port != nil {
+		b = b.WithPort(uint32(*opts.port))
+	}
+	return b.Build()
+}
@@ -0,0 +1,420 @@
+/*
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package istio
+
+// This file contains functions used for assigning users to versions stickily by buckets
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/gogo/protobuf/types"
+	networkingv1alpha3 "istio.io/api/networking/v1alpha3"
+	"istio.io/client-go/pkg/apis/networking/v1alpha3"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	iter8v1alpha2 "github.com/iter8-tools/iter8/pkg/apis/iter8/v1alpha2"
+	"github.com/iter8-tools/iter8/pkg/controller/experiment/util"
+)
+
+const (
+	// number of buckets users are hashed into
+	stickyBuckets = 100
+	// prefix of names of routes sending buckets of users to versions
+	routeNameSticky = "iter8-sticky"
+	// prefix of header carrying bucket of the user of a request
+	bucketHeaderPrefix = "x-iter8-bucket-"
+
+	// environment variable of root namespace of istio, where filters bucketing users at gateways are created
+	istioRootNamespaceEnv     = "ISTIO_ROOT_NAMESPACE"
+	defaultIstioRootNamespace = "istio-system"
+)
+
+// istioRootNamespace returns the namespace whose envoy filters apply to all proxies in the mesh, including gateways
+func istioRootNamespace() string {
+	retVal := defaultIstioRootNamespace
+	if namespace := os.Getenv(istioRootNamespaceEnv); namespace != "" {
+		retVal = namespace
+	}
+	return retVal
+}
+
+// bucketHeader returns name of header carrying bucket of the user of a request in the experiment
+func bucketHeader(instance *iter8v1alpha2.Experiment) string {
+	return bucketHeaderPrefix + util.FullExperimentName(instance)
+}
+
+// stickyFilterName returns name of envoy filter bucketing users of the experiment
+func stickyFilterName(instance *iter8v1alpha2.Experiment) string {
+	return routeNameSticky + "-" + util.FullExperimentName(instance)
+}
+
+// stickyRouteName returns name of route sending buckets of users to the version with given subset
+func stickyRouteName(instance *iter8v1alpha2.Experiment, subset string) string {
+	return routeName(instance, routeNameSticky+"-"+subset)
+}
+
+// assignBuckets assigns buckets of users to versions by their weights
+// Versions over their weights release their highest buckets, and versions under their weights take
+// the lowest free ones, so the fewest users move when weights change; buckets left go to baseline
+func assignBuckets(previous []string, assessment *iter8v1alpha2.Assessment) []string {
+	versions := []iter8v1alpha2.VersionAssessment{assessment.Baseline}
+	versions = append(versions, assessment.Candidates...)
+	want := make(map[string]int32)
+	for _, version := range versions {
+		want[version.Name] = version.Weight
+	}
+
+	buckets := make([]string, stickyBuckets)
+	if len(previous) == stickyBuckets {
+		copy(buckets, previous)
+	}
+
+	owned := make(map[string]int32)
+	for i := range buckets {
+		if _, ok := want[buckets[i]]; !ok {
+			buckets[i] = ""
+			continue
+		}
+		owned[buckets[i]]++
+	}
+
+	for i := stickyBuckets - 1; i >= 0; i-- {
+		if name := buckets[i]; name != "" && owned[name] > want[name] {
+			buckets[i] = ""
+			owned[name]--
+		}
+	}
+
+	for _, version := range versions {
+		for i := 0; i < stickyBuckets && owned[version.Name] < want[version.Name]; i++ {
+			if buckets[i] == "" {
+				buckets[i] = version.Name
+				owned[version.Name]++
+			}
+		}
+	}
+
+	for i := range buckets {
+		if buckets[i] == "" {
+			buckets[i] = assessment.Baseline.Name
+		}
+	}
+	return buckets
+}
+
+// updateStickyRoutes assigns buckets of users to versions by current weights, and routes each bucket to its version
+// Sticky routes precede the experiment route, which takes requests without keys of users
+func (r *Router) updateStickyRoutes(vs *v1alpha3.VirtualService, instance *iter8v1alpha2.Experiment) {
+	if instance.Spec.GetSticky() == nil {
+		return
+	}
+
+	assessment := instance.Status.Assessment
+	instance.Status.Buckets = assignBuckets(instance.Status.Buckets, assessment)
+	owned := make(map[string][]string)
+	for i, name := range instance.Status.Buckets {
+		owned[name] = append(owned[name], strconv.Itoa(i))
+	}
+
+	vsb := removeStickyRoutes(NewVirtualServiceBuilder(vs), instance)
+	subsets := map[string]string{assessment.Baseline.Name: baselineSubset(instance)}
+	names := []string{assessment.Baseline.Name}
+	for _, candidate := range assessment.Candidates {
+		subsets[candidate.Name] = candidateSubset(instance, candidate.Name)
+		names = append(names, candidate.Name)
+	}
+	for _, name := range names {
+		if len(owned[name]) == 0 {
+			continue
+		}
+		route := NewEmptyHTTPRoute(stickyRouteName(instance, subsets[name])).
+			WithDestination(r.handler.buildDestination(instance, destinationOptions{
+				name:   name,
+				weight: 100,
+				subset: subsets[name],
+				port:   httpPort(instance),
+			}))
+		route.Match = stickyMatches(instance, owned[name])
+		vsb = vsb.WithHTTPRouteBefore(route.Build(), routeName(instance, routeNameExperiment))
+	}
+}
+
+// removeStickyRoutes removes routes sending buckets of users of the experiment to versions
+func removeStickyRoutes(vsb *VirtualServiceBuilder, instance *iter8v1alpha2.Experiment) *VirtualServiceBuilder {
+	subsets := candidateSubsets(instance)
+	subsets[baselineSubset(instance)] = true
+	for subset := range subsets {
+		vsb = vsb.RemoveHTTPRoute(stickyRouteName(instance, subset))
+	}
+	return vsb
+}
+
+// stickyMatches returns match clauses of the experiment narrowed to requests in the buckets
+func stickyMatches(instance *iter8v1alpha2.Experiment, buckets []string) []*networkingv1alpha3.HTTPMatchRequest {
+	bucket := &networkingv1alpha3.StringMatch{
+		MatchType: &networkingv1alpha3.StringMatch_Regex{Regex: strings.Join(buckets, "|")},
+	}
+
+	out := make([]*networkingv1alpha3.HTTPMatchRequest, 0)
+	if tc := instance.Spec.TrafficControl; tc != nil && tc.Match != nil && len(tc.Match.HTTP) > 0 {
+		for _, m := range tc.Match.HTTP {
+			match := convertMatchToIstio(m)
+			if match.Headers == nil {
+				match.Headers = make(map[string]*networkingv1alpha3.StringMatch)
+			}
+			match.Headers[bucketHeader(instance)] = bucket
+			out = append(out, match)
+		}
+	} else {
+		out = append(out, &networkingv1alpha3.HTTPMatchRequest{
+			Headers: map[string]*networkingv1alpha3.StringMatch{bucketHeader(instance): bucket},
+		})
+	}
+	return out
+}
+
+// createStickyFilter creates envoy filters labeling requests of the experiment with buckets of their users
+func (r *Router) createStickyFilter(ctx context.Context, instance *iter8v1alpha2.Experiment) error {
+	if instance.Spec.GetSticky() == nil {
+		return nil
+	}
+
+	for _, filter := range stickyFilters(instance) {
+		client := r.client.NetworkingV1alpha3().EnvoyFilters(filter.Namespace)
+		if _, err := client.Get(ctx, filter.Name, metav1.GetOptions{}); err == nil {
+			continue
+		} else if !errors.IsNotFound(err) {
+			return err
+		}
+		if _, err := client.Create(ctx, filter, metav1.CreateOptions{}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// deleteStickyFilter deletes envoy filters bucketing users of the experiment if they exist
+func (r *Router) deleteStickyFilter(ctx context.Context, instance *iter8v1alpha2.Experiment) error {
+	if instance.Spec.GetSticky() == nil {
+		return nil
+	}
+	for _, namespace := range []string{instance.ServiceNamespace(), istioRootNamespace()} {
+		err := r.client.NetworkingV1alpha3().EnvoyFilters(namespace).
+			Delete(ctx, stickyFilterName(instance), metav1.DeleteOptions{})
+		if err != nil && !errors.IsNotFound(err) {
+			return err
+		}
+	}
+	return nil
+}
+
+// stickyFilters returns envoy filters bucketing users of the experiment
+// The lua filter does nothing by default, and runs the bucketing code only on virtual hosts of the experiment:
+// clients in the namespace of the service bucket requests to its internal host, which is matched by name and port;
+// gateways bucket requests they route by the experiment gateways, from root namespace of istio where they live
+// Clients in other namespaces do not bucket requests, which are then assigned by weights
+func stickyFilters(instance *iter8v1alpha2.Experiment) []*v1alpha3.EnvoyFilter {
+	out := make([]*v1alpha3.EnvoyFilter, 0)
+	if instance.Spec.Service.Name != "" {
+		host := util.GetDefaultHost(instance)
+		patches := make([]*networkingv1alpha3.EnvoyFilter_EnvoyConfigObjectPatch, 0)
+		for _, port := range instance.Spec.Service.GetPorts() {
+			if port.GetProtocol() != iter8v1alpha2.PortProtocolHTTP {
+				continue
+			}
+			patches = append(patches,
+				stickyFilterPatch(instance, networkingv1alpha3.EnvoyFilter_SIDECAR_OUTBOUND, uint32(port.Number)),
+				stickyRoutePatch(instance, networkingv1alpha3.EnvoyFilter_SIDECAR_OUTBOUND,
+					&networkingv1alpha3.EnvoyFilter_RouteConfigurationMatch{
+						PortNumber: uint32(port.Number),
+						Vhost: &networkingv1alpha3.EnvoyFilter_RouteConfigurationMatch_VirtualHostMatch{
+							Name: fmt.Sprintf("%s:%d", host, port.Number),
+						},
+					}))
+		}
+		if len(patches) > 0 {
+			out = append(out, stickyFilter(instance, instance.ServiceNamespace(), patches))
+		}
+	}
+
+	if nwk := instance.Spec.Networking; nwk != nil {
+		patches := make([]*networkingv1alpha3.EnvoyFilter_EnvoyConfigObjectPatch, 0)
+		gateways := make(map[string]bool)
+		for _, host := range nwk.Hosts {
+			gateway := host.Gateway
+			if gateway == "" || gateway == "mesh" {
+				continue
+			}
+			if !strings.Contains(gateway, "/") {
+				gateway = instance.ServiceNamespace() + "/" + gateway
+			}
+			if gateways[gateway] {
+				continue
+			}
+			gateways[gateway] = true
+			patches = append(patches, stickyRoutePatch(instance, networkingv1alpha3.EnvoyFilter_GATEWAY,
+				&networkingv1alpha3.EnvoyFilter_RouteConfigurationMatch{Gateway: gateway}))
+		}
+		if len(patches) > 0 {
+			patches = append([]*networkingv1alpha3.EnvoyFilter_EnvoyConfigObjectPatch{
+				stickyFilterPatch(instance, networkingv1alpha3.EnvoyFilter_GATEWAY, 0),
+			}, patches...)
+			out = append(out, stickyFilter(instance, istioRootNamespace(), patches))
+		}
+	}
+	return out
+}
+
+// stickyFilter returns envoy filter of the experiment in the namespace with given patches
+func stickyFilter(instance *iter8v1alpha2.Experiment, namespace string,
+	patches []*networkingv1alpha3.EnvoyFilter_EnvoyConfigObjectPatch) *v1alpha3.EnvoyFilter {
+	return &v1alpha3.EnvoyFilter{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      stickyFilterName(instance),
+			Namespace: namespace,
+			Labels: map[string]string{
+				experimentLabel: util.FullExperimentName(instance),
+			},
+		},
+		Spec: networkingv1alpha3.EnvoyFilter{
+			ConfigPatches: patches,
+		},
+	}
+}
+
+// stickyFilterPatch inserts lua filter of the experiment ahead of the router of listeners in the context
+// Listeners on any port are patched if port is 0
+func stickyFilterPatch(instance *iter8v1alpha2.Experiment, context networkingv1alpha3.EnvoyFilter_PatchContext,
+	port uint32) *networkingv1alpha3.EnvoyFilter_EnvoyConfigObjectPatch {
+	return &networkingv1alpha3.EnvoyFilter_EnvoyConfigObjectPatch{
+		ApplyTo: networkingv1alpha3.EnvoyFilter_HTTP_FILTER,
+		Match: &networkingv1alpha3.EnvoyFilter_EnvoyConfigObjectMatch{
+			Context: context,
+			ObjectTypes: &networkingv1alpha3.EnvoyFilter_EnvoyConfigObjectMatch_Listener{
+				Listener: &networkingv1alpha3.EnvoyFilter_ListenerMatch{
+					PortNumber: port,
+					FilterChain: &networkingv1alpha3.EnvoyFilter_ListenerMatch_FilterChainMatch{
+						Filter: &networkingv1alpha3.EnvoyFilter_ListenerMatch_FilterMatch{
+							Name: "envoy.filters.network.http_connection_manager",
+							SubFilter: &networkingv1alpha3.EnvoyFilter_ListenerMatch_SubFilterMatch{
+								Name: "envoy.filters.http.router",
+							},
+						},
+					},
+				},
+			},
+		},
+		Patch: &networkingv1alpha3.EnvoyFilter_Patch{
+			Operation: networkingv1alpha3.EnvoyFilter_Patch_INSERT_BEFORE,
+			Value: structValue(map[string]*types.Value{
+				"name": stringValue(stickyFilterName(instance)),
+				"typed_config": structField(map[string]*types.Value{
+					"@type":      stringValue("type.googleapis.com/envoy.extensions.filters.http.lua.v3.Lua"),
+					"inlineCode": stringValue("function envoy_on_request(request_handle)\nend\n"),
+					"sourceCodes": structField(map[string]*types.Value{
+						stickyFilterName(instance): structField(map[string]*types.Value{
+							"inlineString": stringValue(stickyLuaCode(instance)),
+						}),
+					}),
+				}),
+			}),
+		},
+	}
+}
+
+// stickyRoutePatch runs bucketing code of the lua filter of the experiment on virtual hosts matched in the context
+func stickyRoutePatch(instance *iter8v1alpha2.Experiment, context networkingv1alpha3.EnvoyFilter_PatchContext,
+	match *networkingv1alpha3.EnvoyFilter_RouteConfigurationMatch) *networkingv1alpha3.EnvoyFilter_EnvoyConfigObjectPatch {
+	return &networkingv1alpha3.EnvoyFilter_EnvoyConfigObjectPatch{
+		ApplyTo: networkingv1alpha3.EnvoyFilter_VIRTUAL_HOST,
+		Match: &networkingv1alpha3.EnvoyFilter_EnvoyConfigObjectMatch{
+			Context: context,
+			ObjectTypes: &networkingv1alpha3.EnvoyFilter_EnvoyConfigObjectMatch_RouteConfiguration{
+				RouteConfiguration: match,
+			},
+		},
+		Patch: &networkingv1alpha3.EnvoyFilter_Patch{
+			Operation: networkingv1alpha3.EnvoyFilter_Patch_MERGE,
+			Value: structValue(map[string]*types.Value{
+				"typedPerFilterConfig": structField(map[string]*types.Value{
+					stickyFilterName(instance): structField(map[string]*types.Value{
+						"@type": stringValue("type.googleapis.com/envoy.extensions.filters.http.lua.v3.LuaPerRoute"),
+						"name":  stringValue(stickyFilterName(instance)),
+					}),
+				}),
+			}),
+		},
+	}
+}
+
+// stickyLuaCode returns lua script hashing key of the user of a request into a bucket
+// Requests without the key are stripped of the bucket header, and fall through to the experiment route
+func stickyLuaCode(instance *iter8v1alpha2.Experiment) string {
+	sticky := instance.Spec.GetSticky()
+	key := ""
+	if sticky.Header != nil {
+		key = fmt.Sprintf("local key = request_handle:headers():get(%q)", strings.ToLower(*sticky.Header))
+	} else {
+		key = fmt.Sprintf(`local key = nil
+  local cookie = request_handle:headers():get("cookie")
+  if cookie ~= nil then
+    key = string.match("; " .. cookie, ";%%s*%s=([^;]*)")
+  end`, luaPatternEscape(*sticky.Cookie))
+	}
+
+	return fmt.Sprintf(`function envoy_on_request(request_handle)
+  %s
+  if key == nil or key == "" then
+    request_handle:headers():remove(%q)
+    return
+  end
+  local hash = 0
+  for i = 1, #key do
+    hash = (hash * 31 + string.byte(key, i)) %% 4294967296
+  end
+  request_handle:headers():replace(%q, tostring(hash %% %d))
+end
+`, key, bucketHeader(instance), bucketHeader(instance), stickyBuckets)
+}
+
+// luaPatternEscape escapes characters of s that are special in lua patterns
+func luaPatternEscape(s string) string {
+	var b strings.Builder
+	for _, c := range s {
+		if !(c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9') {
+			b.WriteRune('%')
+		}
+		b.WriteRune(c)
+	}
+	return b.String()
+}
+
+func structValue(fields map[string]*types.Value) *types.Struct {
+	return &types.Struct{Fields: fields}
+}
+
+func structField(fields map[string]*types.Value) *types.Value {
+	return &types.Value{Kind: &types.Value_StructValue{StructValue: structValue(fields)}}
+}
+
+func stringValue(s string) *types.Value {
+	return &types.Value{Kind: &types.Value_StringValue{StringValue: s}}
+}
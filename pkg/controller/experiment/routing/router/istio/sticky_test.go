@@ -0,0 +1,207 @@
+/*
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package istio
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	networkingv1alpha3 "istio.io/api/networking/v1alpha3"
+
+	iter8v1alpha2 "github.com/iter8-tools/iter8/pkg/apis/iter8/v1alpha2"
+)
+
+// stickyAssessment returns assessment of reviews-v1 as baseline and following versions as candidates with given weights
+func stickyAssessment(weights ...int32) *iter8v1alpha2.Assessment {
+	a := &iter8v1alpha2.Assessment{
+		Baseline: iter8v1alpha2.VersionAssessment{Name: "reviews-v1", Weight: weights[0]},
+	}
+	for i, weight := range weights[1:] {
+		a.Candidates = append(a.Candidates, iter8v1alpha2.VersionAssessment{
+			Name:   fmt.Sprintf("reviews-v%d", i+2),
+			Weight: weight,
+		})
+	}
+	return a
+}
+
+// contiguousBuckets returns buckets owned by versions in order, each taking given number of buckets
+func contiguousBuckets(owners ...interface{}) []string {
+	out := make([]string, 0, stickyBuckets)
+	for i := 0; i < len(owners); i += 2 {
+		for j := 0; j < owners[i+1].(int); j++ {
+			out = append(out, owners[i].(string))
+		}
+	}
+	return out
+}
+
+func TestAssignBuckets(t *testing.T) {
+	tests := []struct {
+		name      string
+		previous  []string
+		weights   []int32
+		want      []string
+		wantMoved int
+	}{
+		{
+			name:    "first assignment",
+			weights: []int32{70, 30},
+			want:    contiguousBuckets("reviews-v1", 70, "reviews-v2", 30),
+		},
+		{
+			name:      "weights unchanged",
+			previous:  contiguousBuckets("reviews-v1", 70, "reviews-v2", 30),
+			weights:   []int32{70, 30},
+			want:      contiguousBuckets("reviews-v1", 70, "reviews-v2", 30),
+			wantMoved: 0,
+		},
+		{
+			name:      "only excess buckets move",
+			previous:  contiguousBuckets("reviews-v1", 70, "reviews-v2", 30),
+			weights:   []int32{50, 50},
+			want:      contiguousBuckets("reviews-v1", 50, "reviews-v2", 50),
+			wantMoved: 20,
+		},
+		{
+			name:      "new candidate takes highest buckets released",
+			previous:  contiguousBuckets("reviews-v1", 50, "reviews-v2", 50),
+			weights:   []int32{40, 40, 20},
+			want:      contiguousBuckets("reviews-v1", 40, "reviews-v3", 10, "reviews-v2", 40, "reviews-v3", 10),
+			wantMoved: 20,
+		},
+		{
+			name:      "removed candidate releases its buckets",
+			previous:  contiguousBuckets("reviews-v1", 50, "reviews-v2", 50),
+			weights:   []int32{100},
+			want:      contiguousBuckets("reviews-v1", 100),
+			wantMoved: 50,
+		},
+		{
+			name:      "buckets left go to baseline",
+			previous:  contiguousBuckets("reviews-v1", 50, "reviews-v2", 50),
+			weights:   []int32{0, 30},
+			want:      contiguousBuckets("reviews-v1", 50, "reviews-v2", 30, "reviews-v1", 20),
+			wantMoved: 20,
+		},
+		{
+			name:     "previous assignment of other size is ignored",
+			previous: []string{"reviews-v2"},
+			weights:  []int32{80, 20},
+			want:     contiguousBuckets("reviews-v1", 80, "reviews-v2", 20),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := assignBuckets(append([]string{}, tt.previous...), stickyAssessment(tt.weights...))
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("assignBuckets() = %v, want %v", got, tt.want)
+			}
+			if len(tt.previous) == stickyBuckets {
+				moved := 0
+				for i := range got {
+					if got[i] != tt.previous[i] {
+						moved++
+					}
+				}
+				if moved != tt.wantMoved {
+					t.Errorf("assignBuckets() moved %d buckets, want %d", moved, tt.wantMoved)
+				}
+			}
+		})
+	}
+}
+
+func TestStickyFilters(t *testing.T) {
+	header := "x-user-id"
+	tests := []struct {
+		name     string
+		ports    []iter8v1alpha2.ServicePort
+		gateways []string
+		// patch contexts and matches of each filter, keyed by its namespace
+		want map[string][]string
+	}{
+		{
+			name:  "internal service",
+			ports: []iter8v1alpha2.ServicePort{servicePort(9080, iter8v1alpha2.PortProtocolHTTP)},
+			want: map[string][]string{
+				"bookinfo": {"SIDECAR_OUTBOUND listener 9080", "SIDECAR_OUTBOUND vhost reviews.bookinfo.svc.cluster.local:9080"},
+			},
+		},
+		{
+			name: "tcp ports are not bucketed",
+			ports: []iter8v1alpha2.ServicePort{
+				servicePort(9080, iter8v1alpha2.PortProtocolHTTP),
+				servicePort(3306, iter8v1alpha2.PortProtocolTCP),
+			},
+			want: map[string][]string{
+				"bookinfo": {"SIDECAR_OUTBOUND listener 9080", "SIDECAR_OUTBOUND vhost reviews.bookinfo.svc.cluster.local:9080"},
+			},
+		},
+		{
+			name:     "gateways",
+			ports:    []iter8v1alpha2.ServicePort{servicePort(9080, iter8v1alpha2.PortProtocolHTTP)},
+			gateways: []string{"bookinfo-gateway", "bookinfo-gateway", "istio-system/shared-gateway"},
+			want: map[string][]string{
+				"bookinfo": {"SIDECAR_OUTBOUND listener 9080", "SIDECAR_OUTBOUND vhost reviews.bookinfo.svc.cluster.local:9080"},
+				"istio-system": {"GATEWAY listener 0", "GATEWAY gateway bookinfo/bookinfo-gateway",
+					"GATEWAY gateway istio-system/shared-gateway"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			instance := portsExperiment(tt.ports...)
+			instance.Spec.TrafficControl = &iter8v1alpha2.TrafficControl{
+				Sticky: &iter8v1alpha2.Sticky{Header: &header},
+			}
+			if len(tt.gateways) > 0 {
+				instance.Spec.Networking = &iter8v1alpha2.Networking{}
+				for _, gateway := range tt.gateways {
+					instance.Spec.Networking.Hosts = append(instance.Spec.Networking.Hosts,
+						iter8v1alpha2.Host{Name: "bookinfo.example.com", Gateway: gateway})
+				}
+			}
+
+			got := make(map[string][]string)
+			for _, filter := range stickyFilters(instance) {
+				if filter.Name != stickyFilterName(instance) {
+					t.Errorf("filter name = %s, want %s", filter.Name, stickyFilterName(instance))
+				}
+				for _, patch := range filter.Spec.ConfigPatches {
+					got[filter.Namespace] = append(got[filter.Namespace], describePatch(patch))
+				}
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("stickyFilters() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// describePatch returns context and match of the patch
+func describePatch(patch *networkingv1alpha3.EnvoyFilter_EnvoyConfigObjectPatch) string {
+	context := patch.Match.Context.String()
+	if listener := patch.Match.GetListener(); listener != nil {
+		return fmt.Sprintf("%s listener %d", context, listener.PortNumber)
+	}
+	rc := patch.Match.GetRouteConfiguration()
+	if rc.Vhost != nil {
+		return fmt.Sprintf("%s vhost %s", context, rc.Vhost.Name)
+	}
+	return fmt.Sprintf("%s gateway %s", context, rc.Gateway)
+}
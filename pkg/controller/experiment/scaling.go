@@ -0,0 +1,287 @@
+/*
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package experiment
+
+// This file contains functions used for scaling replicas of versions in proportion to their traffic.
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv1 "k8s.io/api/autoscaling/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	iter8v1alpha2 "github.com/iter8-tools/iter8/pkg/apis/iter8/v1alpha2"
+	"github.com/iter8-tools/iter8/pkg/controller/experiment/util"
+)
+
+// scaleUp scales versions ahead of increase of their traffic
+// Traffic increase of a candidate is limited to the share its available replicas can serve
+func (r *ReconcileExperiment) scaleUp(context context.Context, instance *iter8v1alpha2.Experiment, previous map[string]int32) {
+	if instance.Spec.Scaling == nil {
+		return
+	}
+	if err := r.initScaling(context, instance); err != nil {
+		util.Logger(context).Error(err, "Error when initializing scaling")
+		return
+	}
+
+	assessment := instance.Status.Assessment
+	held := []string{}
+	for i := range assessment.Candidates {
+		candidate := &assessment.Candidates[i]
+		if candidate.Weight <= previous[candidate.Name] {
+			continue
+		}
+		desired := desiredReplicas(instance, candidate.Weight)
+		available, err := r.scaleVersion(context, instance, candidate.Name, desired, true)
+		if err != nil {
+			util.Logger(context).Error(err, "Error when scaling up", "version", candidate.Name)
+		}
+		if available >= desired {
+			continue
+		}
+		allowed := availableWeight(instance, available)
+		if allowed < previous[candidate.Name] {
+			allowed = previous[candidate.Name]
+		}
+		if allowed < candidate.Weight {
+			assessment.Baseline.Weight += candidate.Weight - allowed
+			held = append(held, fmt.Sprintf("%s held at %d%% (%d/%d replicas available)", candidate.Name, allowed, available, desired))
+			candidate.Weight = allowed
+		}
+	}
+	if len(held) > 0 {
+		r.markTrafficHeld(context, instance, "Waiting for replicas: %s", strings.Join(held, "; "))
+	}
+
+	// baseline is never held back since it takes traffic cut off from candidates
+	if _, err := r.scaleVersion(context, instance, assessment.Baseline.Name, desiredReplicas(instance, assessment.Baseline.Weight), true); err != nil {
+		util.Logger(context).Error(err, "Error when scaling up", "version", assessment.Baseline.Name)
+	}
+}
+
+// scaleForSplit scales up versions gaining traffic in the split ahead of shifting traffic to the split
+// returns versions whose desired replicas are not available yet
+func (r *ReconcileExperiment) scaleForSplit(context context.Context, instance *iter8v1alpha2.Experiment, split map[string]int32) []string {
+	if instance.Status.Scaling == nil {
+		return nil
+	}
+	current := currentSplit(instance)
+	waiting := []string{}
+	for _, va := range allVersions(instance) {
+		weight, ok := split[va.Name]
+		if !ok || weight <= current[va.Name] {
+			continue
+		}
+		desired := desiredReplicas(instance, weight)
+		available, err := r.scaleVersion(context, instance, va.Name, desired, true)
+		if err != nil {
+			util.Logger(context).Error(err, "Error when scaling up", "version", va.Name)
+		}
+		if available < desired {
+			waiting = append(waiting, fmt.Sprintf("%s (%d/%d replicas available)", va.Name, available, desired))
+		}
+	}
+	return waiting
+}
+
+// toScaleFinal tells whether completion should wait for versions gaining traffic in the final split to scale up
+// Completion waits no longer than the progress deadline
+func (r *ReconcileExperiment) toScaleFinal(context context.Context, instance *iter8v1alpha2.Experiment) bool {
+	if instance.Status.Scaling == nil {
+		return false
+	}
+	final := instance.DeepCopy()
+	overrideAssessment(final)
+	waiting := r.scaleForSplit(context, instance, currentSplit(final))
+	if len(waiting) == 0 {
+		return false
+	}
+
+	now := metav1.Now()
+	if instance.Status.UnavailableSince == nil {
+		instance.Status.UnavailableSince = &now
+		r.markStatusUpdate()
+	}
+	deadline, _ := instance.Spec.GetProgressDeadline()
+	if now.Time.After(instance.Status.UnavailableSince.Add(deadline)) {
+		util.Logger(context).Info("FinalScalingTimeout", "waiting", waiting, "progressDeadline", deadline)
+		return false
+	}
+	r.markTrafficHeld(context, instance, "Completion waiting for replicas: %s", strings.Join(waiting, "; "))
+	r.markRequeue()
+	return true
+}
+
+// scaleDown scales versions down to their current traffic
+// It should be called after traffic is moved away from versions
+func (r *ReconcileExperiment) scaleDown(context context.Context, instance *iter8v1alpha2.Experiment) {
+	if instance.Status.Scaling == nil {
+		return
+	}
+	for _, va := range allVersions(instance) {
+		if _, err := r.scaleVersion(context, instance, va.Name, desiredReplicas(instance, va.Weight), false); err != nil {
+			util.Logger(context).Error(err, "Error when scaling down", "version", va.Name)
+		}
+	}
+}
+
+// scaleToTraffic scales versions up to their current traffic without holding back traffic
+func (r *ReconcileExperiment) scaleToTraffic(context context.Context, instance *iter8v1alpha2.Experiment) {
+	if instance.Status.Scaling == nil {
+		return
+	}
+	for _, va := range allVersions(instance) {
+		if _, err := r.scaleVersion(context, instance, va.Name, desiredReplicas(instance, va.Weight), true); err != nil {
+			util.Logger(context).Error(err, "Error when scaling up", "version", va.Name)
+		}
+	}
+}
+
+// restoreScaling scales versions receiving final traffic, and restores minReplicas of HPAs of all versions
+func (r *ReconcileExperiment) restoreScaling(context context.Context, instance *iter8v1alpha2.Experiment) {
+	if instance.Status.Scaling == nil {
+		return
+	}
+	r.scaleToTraffic(context, instance)
+	for _, va := range allVersions(instance) {
+		original, ok := instance.Status.Scaling.OriginalMinReplicas[va.Name]
+		if !ok {
+			continue
+		}
+		nn := instance.VersionNamespacedName(va.Name)
+		hpa, err := r.findHPA(context, nn.Namespace, nn.Name)
+		if err != nil || hpa == nil {
+			continue
+		}
+		hpa.Spec.MinReplicas = &original
+		if err := r.Update(context, hpa); err != nil {
+			util.Logger(context).Error(err, "Error when restoring minReplicas", "version", va.Name)
+		}
+	}
+}
+
+// initScaling records number of replicas serving all traffic from baseline
+func (r *ReconcileExperiment) initScaling(context context.Context, instance *iter8v1alpha2.Experiment) error {
+	if instance.Status.Scaling != nil {
+		return nil
+	}
+	baseline := &appsv1.Deployment{}
+	if err := r.Get(context, instance.VersionNamespacedName(instance.GetBaseline()), baseline); err != nil {
+		return err
+	}
+
+	total := int32(1)
+	if baseline.Spec.Replicas != nil {
+		total = *baseline.Spec.Replicas
+	}
+	instance.Status.Scaling = &iter8v1alpha2.ScalingStatus{
+		TotalReplicas:       total,
+		OriginalMinReplicas: make(map[string]int32),
+	}
+	r.markStatusUpdate()
+	return nil
+}
+
+// scaleVersion sets replicas of a version, through its HPA if one exists
+// Replicas are only increased if up is true, and only decreased otherwise
+// returns number of available replicas of the version
+func (r *ReconcileExperiment) scaleVersion(context context.Context, instance *iter8v1alpha2.Experiment,
+	name string, replicas int32, up bool) (int32, error) {
+	nn := instance.VersionNamespacedName(name)
+	deployment := &appsv1.Deployment{}
+	if err := r.Get(context, nn, deployment); err != nil {
+		return 0, client.IgnoreNotFound(err)
+	}
+	available := deployment.Status.AvailableReplicas
+
+	hpa, err := r.findHPA(context, nn.Namespace, nn.Name)
+	if err != nil {
+		return available, err
+	}
+	if hpa != nil {
+		min := int32(1)
+		if hpa.Spec.MinReplicas != nil {
+			min = *hpa.Spec.MinReplicas
+		}
+		if _, ok := instance.Status.Scaling.OriginalMinReplicas[name]; !ok {
+			instance.Status.Scaling.OriginalMinReplicas[name] = min
+			r.markStatusUpdate()
+		}
+		if up && min < replicas || !up && min > replicas {
+			hpa.Spec.MinReplicas = &replicas
+			if hpa.Spec.MaxReplicas < replicas {
+				hpa.Spec.MaxReplicas = replicas
+			}
+			if err := r.Update(context, hpa); err != nil {
+				return available, err
+			}
+		}
+	} else {
+		current := int32(1)
+		if deployment.Spec.Replicas != nil {
+			current = *deployment.Spec.Replicas
+		}
+		if up && current < replicas || !up && current > replicas {
+			deployment.Spec.Replicas = &replicas
+			if err := r.Update(context, deployment); err != nil {
+				return available, err
+			}
+		}
+	}
+
+	return available, nil
+}
+
+// findHPA returns the HPA targeting the deployment; nil if none
+func (r *ReconcileExperiment) findHPA(context context.Context, namespace, name string) (*autoscalingv1.HorizontalPodAutoscaler, error) {
+	hpas := &autoscalingv1.HorizontalPodAutoscalerList{}
+	if err := r.List(context, hpas, client.InNamespace(namespace)); err != nil {
+		return nil, err
+	}
+	for i := range hpas.Items {
+		ref := hpas.Items[i].Spec.ScaleTargetRef
+		if ref.Kind == "Deployment" && ref.Name == name {
+			return &hpas.Items[i], nil
+		}
+	}
+	return nil, nil
+}
+
+// desiredReplicas returns number of replicas of a version in proportion to its traffic
+func desiredReplicas(instance *iter8v1alpha2.Experiment, weight int32) int32 {
+	replicas := (instance.Status.Scaling.TotalReplicas*weight + 99) / 100
+	if min := instance.Spec.GetMinReplicas(); replicas < min {
+		return min
+	}
+	return replicas
+}
+
+// availableWeight returns the largest traffic weight whose desired replicas do not exceed the available replicas
+func availableWeight(instance *iter8v1alpha2.Experiment, available int32) int32 {
+	if available < instance.Spec.GetMinReplicas() {
+		return 0
+	}
+	total := instance.Status.Scaling.TotalReplicas
+	if total <= 0 || available*100/total > 100 {
+		return 100
+	}
+	return available * 100 / total
+}
@@ -0,0 +1,85 @@
+/*
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package experiment
+
+import (
+	"testing"
+
+	iter8v1alpha2 "github.com/iter8-tools/iter8/pkg/apis/iter8/v1alpha2"
+)
+
+func scalingExperiment(total, min int32) *iter8v1alpha2.Experiment {
+	instance := &iter8v1alpha2.Experiment{}
+	instance.Spec.Scaling = &iter8v1alpha2.Scaling{MinReplicas: &min}
+	instance.Status.Scaling = &iter8v1alpha2.ScalingStatus{TotalReplicas: total}
+	return instance
+}
+
+func TestDesiredReplicas(t *testing.T) {
+	tests := []struct {
+		name   string
+		total  int32
+		min    int32
+		weight int32
+		want   int32
+	}{
+		{name: "all traffic", total: 4, min: 1, weight: 100, want: 4},
+		{name: "proportional", total: 4, min: 1, weight: 50, want: 2},
+		{name: "rounded up", total: 4, min: 1, weight: 30, want: 2},
+		{name: "no traffic", total: 4, min: 1, weight: 0, want: 1},
+		{name: "below minimum", total: 10, min: 3, weight: 10, want: 3},
+		{name: "no minimum", total: 10, min: 0, weight: 0, want: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := desiredReplicas(scalingExperiment(tt.total, tt.min), tt.weight); got != tt.want {
+				t.Errorf("desiredReplicas() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAvailableWeight(t *testing.T) {
+	tests := []struct {
+		name      string
+		total     int32
+		min       int32
+		available int32
+		want      int32
+	}{
+		{name: "all available", total: 4, min: 1, available: 4, want: 100},
+		{name: "more than total", total: 4, min: 1, available: 6, want: 100},
+		{name: "partially available", total: 4, min: 1, available: 1, want: 25},
+		{name: "rounded down", total: 3, min: 1, available: 2, want: 66},
+		{name: "below minimum", total: 10, min: 3, available: 2, want: 0},
+		{name: "none available", total: 4, min: 1, available: 0, want: 0},
+		{name: "no baseline replicas", total: 0, min: 0, available: 0, want: 100},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			instance := scalingExperiment(tt.total, tt.min)
+			got := availableWeight(instance, tt.available)
+			if got != tt.want {
+				t.Errorf("availableWeight() = %d, want %d", got, tt.want)
+			}
+			if got > 0 && desiredReplicas(instance, got) > tt.available && tt.total > 0 {
+				t.Errorf("desiredReplicas(%d) = %d exceeds %d available", got, desiredReplicas(instance, got), tt.available)
+			}
+		})
+	}
+}
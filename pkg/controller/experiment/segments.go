@@ -0,0 +1,254 @@
+/*
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package experiment
+
+// This file contains functions used for assessing segments of requests separately.
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/runtime"
+
+	"github.com/iter8-tools/iter8/pkg/analytics"
+	iter8v1alpha2 "github.com/iter8-tools/iter8/pkg/apis/iter8/v1alpha2"
+	"github.com/iter8-tools/iter8/pkg/controller/experiment/util"
+)
+
+// processSegments updates traffic split of each segment of the experiment
+// Segments follow traffic split of the experiment until versions are assessed by analytics;
+// returns whether traffic of any segment is updated
+func (r *ReconcileExperiment) processSegments(context context.Context, instance *iter8v1alpha2.Experiment, assessed, hold bool) (bool, error) {
+	if len(instance.Spec.GetSegments()) == 0 {
+		return false, nil
+	}
+
+	updated := syncSegments(instance)
+	for i := range instance.Status.Segments {
+		segment := &instance.Status.Segments[i]
+		if !assessed {
+			updated = followSplit(&segment.Assessment, instance.Status.Assessment) || updated
+			continue
+		}
+		segmentUpdated, err := r.assessSegment(context, instance, segment, hold)
+		if err != nil {
+			return false, err
+		}
+		updated = segmentUpdated || updated
+	}
+	return updated, nil
+}
+
+// syncSegments aligns status of segments with the segments and versions of the experiment
+// A new segment starts with traffic split of the experiment; returns whether status is changed
+func syncSegments(instance *iter8v1alpha2.Experiment) bool {
+	overall := instance.Status.Assessment
+	changed := false
+	segments := make([]iter8v1alpha2.SegmentStatus, 0, len(instance.Spec.GetSegments()))
+	for _, spec := range instance.Spec.GetSegments() {
+		var segment *iter8v1alpha2.SegmentStatus
+		for i := range instance.Status.Segments {
+			if instance.Status.Segments[i].Name == spec.Name {
+				segment = instance.Status.Segments[i].DeepCopy()
+				break
+			}
+		}
+
+		if segment == nil {
+			assessment := overall.DeepCopy()
+			assessment.Winner = nil
+			segment = &iter8v1alpha2.SegmentStatus{
+				Name:          spec.Name,
+				Assessment:    *assessment,
+				AnalysisState: &runtime.RawExtension{Raw: []byte("{}")},
+			}
+			changed = true
+		} else {
+			changed = alignCandidates(&segment.Assessment, overall) || changed
+		}
+		segments = append(segments, *segment)
+	}
+
+	if len(segments) != len(instance.Status.Segments) {
+		changed = true
+	}
+	instance.Status.Segments = segments
+	return changed
+}
+
+// alignCandidates retires candidates of the segment not in the experiment, drains those being retired,
+// and adds new ones with no traffic
+func alignCandidates(a, overall *iter8v1alpha2.Assessment) bool {
+	changed := false
+	for _, candidate := range append([]iter8v1alpha2.VersionAssessment{}, a.Candidates...) {
+		if overall.CandidateIndex(candidate.Name) < 0 {
+			a.RetireCandidate(candidate.Name)
+			changed = true
+		}
+	}
+	for _, candidate := range overall.Candidates {
+		if candidate.Retired && a.CandidateIndex(candidate.Name) >= 0 && !retired(a, candidate.Name) {
+			a.DrainCandidate(candidate.Name)
+			changed = true
+		}
+	}
+	for _, candidate := range overall.Candidates {
+		if a.CandidateIndex(candidate.Name) < 0 {
+			a.Candidates = append(a.Candidates, iter8v1alpha2.VersionAssessment{
+				Name:  candidate.Name,
+				Index: candidate.Index,
+			})
+			changed = true
+		}
+	}
+	a.Baseline.Name = overall.Baseline.Name
+	a.NextCandidateIndex = overall.NextCandidateIndex
+	return changed
+}
+
+// retired tells whether candidate with given name is retired in the assessment
+func retired(a *iter8v1alpha2.Assessment, name string) bool {
+	for _, candidate := range a.Candidates {
+		if candidate.Name == name {
+			return candidate.Retired
+		}
+	}
+	return false
+}
+
+// followSplit sets traffic split of the segment to that of the experiment; returns whether it is changed
+func followSplit(a, overall *iter8v1alpha2.Assessment) bool {
+	changed := a.Baseline.Weight != overall.Baseline.Weight
+	a.Baseline.Weight = overall.Baseline.Weight
+	for i := range a.Candidates {
+		weight := int32(0)
+		for _, candidate := range overall.Candidates {
+			if candidate.Name == a.Candidates[i].Name {
+				weight = candidate.Weight
+				break
+			}
+		}
+		changed = changed || a.Candidates[i].Weight != weight
+		a.Candidates[i].Weight = weight
+	}
+	return changed
+}
+
+// assessSegment gets assessment of versions by requests in the segment, and updates its traffic split
+// Traffic is kept steady except for candidates to be rolled back if hold is true;
+// returns whether traffic of the segment is updated
+func (r *ReconcileExperiment) assessSegment(context context.Context, instance *iter8v1alpha2.Experiment,
+	segment *iter8v1alpha2.SegmentStatus, hold bool) (bool, error) {
+	log := util.Logger(context)
+	payload, err := analytics.MakeSegmentRequest(instance, segment, assessmentStartTime(instance))
+	if err != nil {
+		r.markAnalyticsServiceError(context, instance, "%s", err.Error())
+		return false, err
+	}
+
+	response, err := analytics.Invoke(log, instance.Spec.GetAnalyticsEndpoint(), payload)
+	if err != nil {
+		r.markAnalyticsServiceError(context, instance, "Segment %s: %s", segment.Name, err.Error())
+		return false, err
+	}
+
+	if response.LastState == nil {
+		segment.AnalysisState = &runtime.RawExtension{Raw: []byte("{}")}
+	} else {
+		lastState, err := json.Marshal(response.LastState)
+		if err != nil {
+			r.markAnalyticsServiceError(context, instance, "%s", err.Error())
+			return false, err
+		}
+		segment.AnalysisState = &runtime.RawExtension{Raw: lastState}
+	}
+
+	assessment := &segment.Assessment
+	assessment.Baseline.VersionAssessment = *response.BaselineAssessment.DeepCopy()
+	for _, ca := range response.CandidateAssessments {
+		i := candidatePosition(assessment, ca.ID)
+		if i < 0 {
+			err := fmt.Errorf("assessment of unknown candidate %s in segment %s", ca.ID, segment.Name)
+			r.markAnalyticsServiceError(context, instance, "%v", err)
+			return false, err
+		}
+		assessment.Candidates[i].VersionAssessment = *ca.VersionAssessment.DeepCopy()
+		// candidates rolled back in the experiment are rolled back in every segment
+		assessment.Candidates[i].Rollback = ca.Rollback
+		if j := candidatePosition(instance.Status.Assessment, ca.ID); j >= 0 {
+			overall := instance.Status.Assessment.Candidates[j]
+			assessment.Candidates[i].Rollback = assessment.Candidates[i].Rollback || overall.Rollback || overall.Unhealthy || overall.Retired
+		}
+	}
+
+	assessment.Winner = &iter8v1alpha2.WinnerAssessment{
+		WinnerAssessment: response.WinnerAssessment.DeepCopy(),
+	}
+	if assessment.Baseline.ID == response.WinnerAssessment.Winner {
+		assessment.Winner.Name = &assessment.Baseline.Name
+	} else {
+		for i := range assessment.Candidates {
+			if assessment.Candidates[i].ID == response.WinnerAssessment.Winner {
+				assessment.Winner.Name = &assessment.Candidates[i].Name
+				break
+			}
+		}
+	}
+	if hold {
+		// no winner is declared until every version has enough samples
+		assessment.Winner.WinnerFound = false
+	}
+
+	updated := false
+	trafficSplit := response.TrafficSplitRecommendation[instance.Spec.GetStrategy()]
+	if !hold {
+		if trafficSplit == nil {
+			err := fmt.Errorf("Missing traffic split recommendation for strategy %s in segment %s", instance.Spec.GetStrategy(), segment.Name)
+			r.markAnalyticsServiceError(context, instance, "%v", err)
+			return false, err
+		}
+		if baselineWeight, ok := trafficSplit[analytics.GetBaselineID()]; ok {
+			updated = assessment.Baseline.Weight != baselineWeight
+			assessment.Baseline.Weight = baselineWeight
+		} else {
+			err := fmt.Errorf("traffic split recommendation for baseline not found in segment %s", segment.Name)
+			r.markAnalyticsServiceError(context, instance, "%v", err)
+			return false, err
+		}
+	}
+
+	for i, candidate := range assessment.Candidates {
+		if candidate.Rollback {
+			if hold {
+				// traffic of candidates rolled back goes to baseline
+				assessment.Baseline.Weight += candidate.Weight
+			}
+			updated = updated || candidate.Weight > 0
+			assessment.Candidates[i].Weight = int32(0)
+		} else if hold {
+			continue
+		} else if weight, ok := trafficSplit[analytics.GetCandidateID(assessment.CandidateIndex(candidate.Name))]; ok {
+			updated = updated || candidate.Weight != weight
+			assessment.Candidates[i].Weight = weight
+		} else {
+			err := fmt.Errorf("traffic split recommendation for candidate %s not found in segment %s", candidate.Name, segment.Name)
+			r.markAnalyticsServiceError(context, instance, "%v", err)
+			return false, err
+		}
+	}
+	return updated, nil
+}
@@ -0,0 +1,149 @@
+/*
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package experiment
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	iter8v1alpha2 "github.com/iter8-tools/iter8/pkg/apis/iter8/v1alpha2"
+)
+
+func segmentVersion(name string, weight int32, retired bool) iter8v1alpha2.VersionAssessment {
+	return iter8v1alpha2.VersionAssessment{Name: name, Weight: weight, Retired: retired}
+}
+
+func segmentAssessment(baseline int32, candidates ...iter8v1alpha2.VersionAssessment) iter8v1alpha2.Assessment {
+	return iter8v1alpha2.Assessment{
+		Baseline:   segmentVersion("reviews-v1", baseline, false),
+		Candidates: candidates,
+	}
+}
+
+// segmentSplit describes traffic split of the assessment, marking retired candidates with *
+func segmentSplit(a iter8v1alpha2.Assessment) string {
+	split := []string{fmt.Sprintf("%s:%d", a.Baseline.Name, a.Baseline.Weight)}
+	for _, candidate := range a.Candidates {
+		version := fmt.Sprintf("%s:%d", candidate.Name, candidate.Weight)
+		if candidate.Retired {
+			version += "*"
+		}
+		split = append(split, version)
+	}
+	return strings.Join(split, " ")
+}
+
+func TestSyncSegments(t *testing.T) {
+	winner := "reviews-v2"
+	cases := []struct {
+		name        string
+		overall     iter8v1alpha2.Assessment
+		specified   []string
+		segments    map[string]iter8v1alpha2.Assessment
+		wantChanged bool
+		wantSplits  map[string]string
+	}{
+		{
+			name:        "new segment follows experiment",
+			overall:     segmentAssessment(60, segmentVersion("reviews-v2", 40, false)),
+			specified:   []string{"beta"},
+			wantChanged: true,
+			wantSplits:  map[string]string{"beta": "reviews-v1:60 reviews-v2:40"},
+		},
+		{
+			name:        "segment keeps its own split",
+			overall:     segmentAssessment(100, segmentVersion("reviews-v2", 0, false)),
+			specified:   []string{"beta"},
+			segments:    map[string]iter8v1alpha2.Assessment{"beta": segmentAssessment(0, segmentVersion("reviews-v2", 100, false))},
+			wantChanged: false,
+			wantSplits:  map[string]string{"beta": "reviews-v1:0 reviews-v2:100"},
+		},
+		{
+			name:      "segment removed from spec",
+			overall:   segmentAssessment(100, segmentVersion("reviews-v2", 0, false)),
+			specified: []string{"beta"},
+			segments: map[string]iter8v1alpha2.Assessment{
+				"alpha": segmentAssessment(100, segmentVersion("reviews-v2", 0, false)),
+				"beta":  segmentAssessment(100, segmentVersion("reviews-v2", 0, false)),
+			},
+			wantChanged: true,
+			wantSplits:  map[string]string{"beta": "reviews-v1:100 reviews-v2:0"},
+		},
+		{
+			name:        "candidate removed from experiment",
+			overall:     segmentAssessment(100),
+			specified:   []string{"beta"},
+			segments:    map[string]iter8v1alpha2.Assessment{"beta": segmentAssessment(20, segmentVersion("reviews-v2", 80, false))},
+			wantChanged: true,
+			wantSplits:  map[string]string{"beta": "reviews-v1:100"},
+		},
+		{
+			name:        "candidate added to experiment",
+			overall:     segmentAssessment(100, segmentVersion("reviews-v2", 0, false), segmentVersion("reviews-v3", 0, false)),
+			specified:   []string{"beta"},
+			segments:    map[string]iter8v1alpha2.Assessment{"beta": segmentAssessment(20, segmentVersion("reviews-v2", 80, false))},
+			wantChanged: true,
+			wantSplits:  map[string]string{"beta": "reviews-v1:20 reviews-v2:80 reviews-v3:0"},
+		},
+		{
+			name:        "candidate retired in experiment",
+			overall:     segmentAssessment(100, segmentVersion("reviews-v2", 0, true)),
+			specified:   []string{"beta"},
+			segments:    map[string]iter8v1alpha2.Assessment{"beta": segmentAssessment(0, segmentVersion("reviews-v2", 100, false))},
+			wantChanged: true,
+			wantSplits:  map[string]string{"beta": "reviews-v1:100 reviews-v2:0*"},
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			overall := c.overall.DeepCopy()
+			overall.Winner = &iter8v1alpha2.WinnerAssessment{Name: &winner}
+			instance := &iter8v1alpha2.Experiment{
+				Spec: iter8v1alpha2.ExperimentSpec{
+					TrafficControl: &iter8v1alpha2.TrafficControl{},
+				},
+				Status: iter8v1alpha2.ExperimentStatus{Assessment: overall},
+			}
+			for _, name := range c.specified {
+				instance.Spec.TrafficControl.Segments = append(instance.Spec.TrafficControl.Segments, iter8v1alpha2.Segment{Name: name})
+			}
+			for _, name := range []string{"alpha", "beta"} {
+				if a, ok := c.segments[name]; ok {
+					instance.Status.Segments = append(instance.Status.Segments, iter8v1alpha2.SegmentStatus{
+						Name:       name,
+						Assessment: *a.DeepCopy(),
+					})
+				}
+			}
+
+			if changed := syncSegments(instance); changed != c.wantChanged {
+				t.Errorf("syncSegments() = %v, want %v", changed, c.wantChanged)
+			}
+			if len(instance.Status.Segments) != len(c.wantSplits) {
+				t.Fatalf("got %d segments, want %d", len(instance.Status.Segments), len(c.wantSplits))
+			}
+			for _, segment := range instance.Status.Segments {
+				if split := segmentSplit(segment.Assessment); split != c.wantSplits[segment.Name] {
+					t.Errorf("split of segment %s = %s, want %s", segment.Name, split, c.wantSplits[segment.Name])
+				}
+				if segment.Assessment.Winner != nil && c.segments[segment.Name].Winner == nil {
+					t.Errorf("segment %s should not take winner of the experiment", segment.Name)
+				}
+			}
+		})
+	}
+}
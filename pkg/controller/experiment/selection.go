@@ -0,0 +1,103 @@
+/*
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package experiment
+
+// This file contains functions used for selecting baseline and candidates by labels.
+
+import (
+	"context"
+	"sort"
+
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	iter8v1alpha2 "github.com/iter8-tools/iter8/pkg/apis/iter8/v1alpha2"
+	"github.com/iter8-tools/iter8/pkg/controller/experiment/util"
+)
+
+// selectTargets resolves baseline and candidates from label selectors
+// Baseline is selected once; matching candidates are added until maxCandidates is reached
+// returns non-nil error if reconcile process should be terminated right after this function
+func (r *ReconcileExperiment) selectTargets(context context.Context, instance *iter8v1alpha2.Experiment) error {
+	service := instance.Spec.Service
+	if instance.GetBaseline() == "" && service.BaselineSelector != nil {
+		deployments, err := r.listSelected(context, instance, service.BaselineSelector)
+		if err != nil {
+			r.markTargetsError(context, instance, "Fail to select baseline: %v", err)
+			return err
+		}
+		if len(deployments) > 0 {
+			instance.Status.SelectBaseline(deployments[0].Name)
+			util.Logger(context).Info("BaselineSelected", "name", deployments[0].Name)
+			r.markStatusUpdate()
+		}
+	}
+
+	// no candidates are added once the experiment moves on to its final traffic
+	if service.CandidateSelector == nil || instance.Spec.Terminate() || instance.Status.Ramp != nil ||
+		instance.Status.Bake != nil || instance.Status.Promotion != nil {
+		return nil
+	}
+	if int32(len(instance.Status.SelectedCandidates)) >= service.GetMaxCandidates() {
+		return nil
+	}
+
+	deployments, err := r.listSelected(context, instance, service.CandidateSelector)
+	if err != nil {
+		r.markTargetsError(context, instance, "Fail to select candidates: %v", err)
+		return err
+	}
+	known := map[string]bool{instance.GetBaseline(): true}
+	for _, name := range instance.GetCandidates() {
+		known[name] = true
+	}
+	for _, deployment := range deployments {
+		if int32(len(instance.Status.SelectedCandidates)) >= service.GetMaxCandidates() {
+			break
+		}
+		if known[deployment.Name] {
+			continue
+		}
+		instance.Status.SelectCandidate(deployment.Name)
+		util.Logger(context).Info("CandidateSelected", "name", deployment.Name)
+		r.markStatusUpdate()
+	}
+	return nil
+}
+
+// listSelected returns deployments matching the selector in the namespace of service, oldest first
+func (r *ReconcileExperiment) listSelected(context context.Context, instance *iter8v1alpha2.Experiment,
+	labelSelector *metav1.LabelSelector) ([]appsv1.Deployment, error) {
+	selector, err := metav1.LabelSelectorAsSelector(labelSelector)
+	if err != nil {
+		return nil, err
+	}
+	deployments := &appsv1.DeploymentList{}
+	if err := r.List(context, deployments, client.InNamespace(instance.ServiceNamespace()),
+		client.MatchingLabelsSelector{Selector: selector}); err != nil {
+		return nil, err
+	}
+	out := deployments.Items
+	sort.SliceStable(out, func(i, j int) bool {
+		ti, tj := out[i].CreationTimestamp, out[j].CreationTimestamp
+		if ti.Equal(&tj) {
+			return out[i].Name < out[j].Name
+		}
+		return ti.Before(&tj)
+	})
+	return out, nil
+}
@@ -0,0 +1,100 @@
+/*
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package experiment
+
+// This file contains functions used for shifting traffic by fixed_steps strategy.
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	analyticsv1alpha2 "github.com/iter8-tools/iter8/pkg/analytics/api/v1alpha2"
+	iter8v1alpha2 "github.com/iter8-tools/iter8/pkg/apis/iter8/v1alpha2"
+)
+
+func isFixedSteps(instance *iter8v1alpha2.Experiment) bool {
+	return instance.Spec.GetStrategy() == string(iter8v1alpha2.StrategyFixedSteps)
+}
+
+// applyFixedSteps moves to the next step once the hold duration of current step elapses,
+// and splits the weight of current step among candidates not rolled back.
+// Candidates failing criteria are expected to be rolled back before this is called.
+// Without criteria, steps advance on hold durations alone; candidates with unhealthy pods are still rolled back.
+// It is not called during warm-up, so the hold duration of the first step starts after warm-up.
+// returns true if traffic split is changed
+func applyFixedSteps(instance *iter8v1alpha2.Experiment) bool {
+	steps := instance.Spec.TrafficControl.Steps
+	status := &instance.Status
+	now := metav1.Now()
+
+	if status.CurrentStep == nil {
+		step := int32(0)
+		status.CurrentStep = &step
+		status.StepTimestamp = &now
+	} else if int(*status.CurrentStep) < len(steps)-1 {
+		hold, _ := steps[*status.CurrentStep].GetHold(&instance.Spec)
+		if !now.Time.Before(status.StepTimestamp.Add(hold)) {
+			*status.CurrentStep++
+			status.StepTimestamp = &now
+		}
+	}
+
+	return splitStepWeight(instance, steps[*status.CurrentStep].Weight)
+}
+
+// splitStepWeight splits weight evenly among candidates not rolled back, and the rest goes to baseline
+// returns true if traffic split is changed
+func splitStepWeight(instance *iter8v1alpha2.Experiment, weight int32) bool {
+	assessment := instance.Status.Assessment
+	active := int32(0)
+	for _, candidate := range assessment.Candidates {
+		if !candidate.Rollback {
+			active++
+		}
+	}
+
+	updated := false
+	total, idx := int32(0), int32(0)
+	for i, candidate := range assessment.Candidates {
+		w := int32(0)
+		if !candidate.Rollback {
+			w = weight / active
+			if idx < weight%active {
+				w++
+			}
+			idx++
+		}
+		if candidate.Weight != w {
+			assessment.Candidates[i].Weight = w
+			updated = true
+		}
+		total += w
+	}
+	if assessment.Baseline.Weight != 100-total {
+		assessment.Baseline.Weight = 100 - total
+		updated = true
+	}
+	return updated
+}
+
+// failsCriteria tells whether any threshold of criteria is breached in the assessment of a version
+func failsCriteria(va *analyticsv1alpha2.VersionAssessment) bool {
+	for _, ca := range va.CriterionAssessments {
+		if ca.ThresholdAssessment != nil && ca.ThresholdAssessment.ThresholdBreached {
+			return true
+		}
+	}
+	return false
+}
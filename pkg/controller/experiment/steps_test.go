@@ -0,0 +1,60 @@
+/*
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package experiment
+
+import (
+	"reflect"
+	"testing"
+
+	iter8v1alpha2 "github.com/iter8-tools/iter8/pkg/apis/iter8/v1alpha2"
+)
+
+func TestSplitStepWeight(t *testing.T) {
+	cases := []struct {
+		name     string
+		weight   int32
+		rollback []bool
+		before   []int32
+		want     []int32
+		updated  bool
+	}{
+		{"single candidate", 30, []bool{false}, []int32{100, 0}, []int32{70, 30}, true},
+		{"unchanged", 30, []bool{false}, []int32{70, 30}, []int32{70, 30}, false},
+		{"remainder to first candidates", 50, []bool{false, false, false}, []int32{100, 0, 0, 0},
+			[]int32{50, 17, 17, 16}, true},
+		{"rolled back candidate gets nothing", 40, []bool{false, true}, []int32{60, 20, 20},
+			[]int32{60, 40, 0}, true},
+	}
+	for _, c := range cases {
+		assessment := &iter8v1alpha2.Assessment{
+			Baseline: iter8v1alpha2.VersionAssessment{Weight: c.before[0]},
+		}
+		for i, rollback := range c.rollback {
+			assessment.Candidates = append(assessment.Candidates,
+				iter8v1alpha2.VersionAssessment{Weight: c.before[i+1], Rollback: rollback})
+		}
+		instance := &iter8v1alpha2.Experiment{Status: iter8v1alpha2.ExperimentStatus{Assessment: assessment}}
+
+		updated := splitStepWeight(instance, c.weight)
+		got := []int32{assessment.Baseline.Weight}
+		for _, candidate := range assessment.Candidates {
+			got = append(got, candidate.Weight)
+		}
+		if !reflect.DeepEqual(got, c.want) || updated != c.updated {
+			t.Errorf("%s: got %v (updated %t), want %v (updated %t)", c.name, got, updated, c.want, c.updated)
+		}
+	}
+}
@@ -20,12 +20,17 @@ package targets
 
 import (
 	"context"
+	"fmt"
+	"strings"
 
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
@@ -33,6 +38,9 @@ import (
 	"github.com/iter8-tools/iter8/pkg/controller/experiment/util"
 )
 
+// serviceEntryGroupVersion is the group version of Istio ServiceEntry
+var serviceEntryGroupVersion = schema.GroupVersion{Group: "networking.istio.io", Version: "v1alpha3"}
+
 // Role of target
 type Role string
 
@@ -49,24 +57,44 @@ type Targets struct {
 	Baseline   runtime.Object
 	Candidates []runtime.Object
 
-	service   iter8v1alpha2.Service
-	namespace string
-	client    client.Client
+	service    iter8v1alpha2.Service
+	baseline   string
+	candidates []string
+	namespace  string
+	client     client.Client
 }
 
 // Init initialize a Targets object with k8s client and namespace of the target service
+// Candidates are those in assessment if available, which exclude candidates pending to join the experiment
 func Init(instance *iter8v1alpha2.Experiment, client client.Client) *Targets {
+	candidates := instance.GetCandidates()
+	if instance.Status.Assessment != nil {
+		candidates = make([]string, len(instance.Status.Assessment.Candidates))
+		for i, candidate := range instance.Status.Assessment.Candidates {
+			candidates[i] = candidate.Name
+		}
+	}
 	return &Targets{
-		client:    client,
-		namespace: instance.ServiceNamespace(),
-		service:   instance.Spec.Service,
+		client:     client,
+		namespace:  instance.ServiceNamespace(),
+		service:    instance.Spec.Service,
+		baseline:   instance.GetBaseline(),
+		candidates: candidates,
 	}
 }
 
+// InitWithCandidates initialize a Targets object with given names of candidates instead of those of the experiment
+func InitWithCandidates(instance *iter8v1alpha2.Experiment, client client.Client, candidates []string) *Targets {
+	t := Init(instance, client)
+	t.candidates = candidates
+	return t
+}
+
 // GetService substantializes internal service in targets
 // returns non-nil error if there is problem in getting the runtime object from cluster
 func (t *Targets) GetService(context context.Context) error {
-	if t.service.Name == "" {
+	// logical host of external services is not backed by an internal service
+	if t.service.Name == "" || t.service.Kind == "ServiceEntry" {
 		return nil
 	}
 
@@ -82,27 +110,18 @@ func (t *Targets) GetService(context context.Context) error {
 
 // GetBaseline substantializes baseline in the targets
 // returns non-nil error if there is problem in getting the runtime object from cluster
-func (t *Targets) GetBaseline(context context.Context) error {
-	t.Baseline = getRuntimeObject(metav1.ObjectMeta{
-		Name:      t.service.Baseline,
-		Namespace: t.namespace,
-	}, t.service.Kind)
-
-	return getObject(context, t.client, t.Baseline)
+func (t *Targets) GetBaseline(context context.Context) (err error) {
+	t.Baseline, err = t.getTarget(context, t.baseline)
+	return
 }
 
 // GetCandidates substantializes all candidates in the targets
 // returns non-nil error if there is problem in getting the runtime objects from cluster
 func (t *Targets) GetCandidates(context context.Context) (err error) {
-	t.Candidates = make([]runtime.Object, len(t.service.Candidates))
+	t.Candidates = make([]runtime.Object, len(t.candidates))
 
 	for i := range t.Candidates {
-		t.Candidates[i] = getRuntimeObject(metav1.ObjectMeta{
-			Name:      t.service.Candidates[i],
-			Namespace: t.namespace,
-		}, t.service.Kind)
-
-		err = getObject(context, t.client, t.Candidates[i])
+		t.Candidates[i], err = t.getTarget(context, t.candidates[i])
 		if err != nil {
 			return
 		}
@@ -110,55 +129,105 @@ func (t *Targets) GetCandidates(context context.Context) (err error) {
 	return
 }
 
-// Cleanup deletes cluster runtime objects of targets at the end of experiment
-func Cleanup(context context.Context, instance *iter8v1alpha2.Experiment, client client.Client) {
-	if instance.Spec.GetCleanup() {
-		assessment := instance.Status.Assessment
-		toKeep := make(map[string]bool)
-
-		switch instance.Spec.GetOnTermination() {
-		case iter8v1alpha2.OnTerminationToWinner:
-			if instance.Status.IsWinnerFound() {
-				toKeep[*assessment.Winner.Name] = true
-				break
-			}
-			fallthrough
-		case iter8v1alpha2.OnTerminationToBaseline:
-			toKeep[instance.Spec.Baseline] = true
-		case iter8v1alpha2.OnTerminationKeepLast:
-			if assessment != nil {
-				if assessment.Baseline.Weight > 0 {
-					toKeep[assessment.Baseline.Name] = true
-				}
-				for _, candidate := range assessment.Candidates {
-					if candidate.Weight > 0 {
-						toKeep[candidate.Name] = true
-					}
-				}
+// getTarget returns runtime object of a version from cluster
+func (t *Targets) getTarget(context context.Context, version string) (runtime.Object, error) {
+	if t.service.Kind == "ServiceEntry" {
+		return t.getServiceEntry(context, version)
+	}
+
+	obj := getRuntimeObject(t.objectMeta(version), t.service)
+	if err := getObject(context, t.client, obj); err != nil {
+		return nil, err
+	}
+	return obj, nil
+}
+
+// getServiceEntry returns the ServiceEntry defining the external host of a version
+func (t *Targets) getServiceEntry(context context.Context, version string) (runtime.Object, error) {
+	host, namespace := iter8v1alpha2.SplitVersion(version, t.namespace)
+	list := &unstructured.UnstructuredList{}
+	list.SetAPIVersion(serviceEntryGroupVersion.String())
+	list.SetKind("ServiceEntryList")
+	if err := t.client.List(context, list, client.InNamespace(namespace)); err != nil {
+		return nil, err
+	}
+
+	for i := range list.Items {
+		hosts, _, _ := unstructured.NestedStringSlice(list.Items[i].Object, "spec", "hosts")
+		for _, h := range hosts {
+			if h == host {
+				return &list.Items[i], nil
 			}
 		}
+	}
+	return nil, errors.NewNotFound(serviceEntryGroupVersion.WithResource("serviceentries").GroupResource(), host)
+}
+
+// objectMeta returns meta info of the target object of a version
+func (t *Targets) objectMeta(version string) metav1.ObjectMeta {
+	name, namespace := iter8v1alpha2.SplitVersion(version, t.namespace)
+	return metav1.ObjectMeta{
+		Name:      name,
+		Namespace: namespace,
+	}
+}
+
+// Available tells whether all desired replicas of a target are available to receive traffic
+// If not, reason explains why; failed is true if the object is not expected to become available
+func Available(obj runtime.Object) (available bool, failed bool, reason string) {
+	if ss, ok := obj.(*appsv1.StatefulSet); ok {
+		desired := int32(1)
+		if ss.Spec.Replicas != nil {
+			desired = *ss.Spec.Replicas
+		}
+		if ss.Status.ReadyReplicas == 0 || ss.Status.ReadyReplicas < desired {
+			return false, false, fmt.Sprintf("%d of %d replicas ready", ss.Status.ReadyReplicas, desired)
+		}
+		return true, false, ""
+	}
+
+	d, ok := obj.(*appsv1.Deployment)
+	if !ok {
+		return true, false, ""
+	}
+
+	for _, c := range d.Status.Conditions {
+		if c.Type == appsv1.DeploymentProgressing && c.Status == corev1.ConditionFalse &&
+			c.Reason == "ProgressDeadlineExceeded" {
+			return false, true, c.Message
+		}
+	}
 
-		kind := instance.Spec.Service.Kind
-		svcNamespace := instance.ServiceNamespace()
+	desired := int32(1)
+	if d.Spec.Replicas != nil {
+		desired = *d.Spec.Replicas
+	}
+	if d.Status.AvailableReplicas == 0 || d.Status.AvailableReplicas < desired {
+		return false, false, fmt.Sprintf("%d of %d replicas available", d.Status.AvailableReplicas, desired)
+	}
+	return true, false, ""
+}
+
+// Cleanup deletes cluster runtime objects of targets at the end of experiment
+// External hosts defined by ServiceEntries are not deleted
+func Cleanup(context context.Context, instance *iter8v1alpha2.Experiment, client client.Client) {
+	if instance.Spec.GetCleanup() && instance.Spec.Service.Kind != "ServiceEntry" {
+		toKeep := versionsToKeep(instance)
+
+		t := Init(instance, client)
 
 		// delete baseline if not receiving traffic
-		if ok := toKeep[instance.Spec.Baseline]; !ok {
-			err := client.Delete(context, getRuntimeObject(metav1.ObjectMeta{
-				Namespace: svcNamespace,
-				Name:      instance.Spec.Baseline,
-			}, kind))
+		if ok := toKeep[instance.GetBaseline()]; !ok {
+			err := client.Delete(context, getRuntimeObject(t.objectMeta(instance.GetBaseline()), instance.Spec.Service))
 			if err != nil {
 				util.Logger(context).Error(err, "Error when deleting baseline")
 			}
 		}
 
 		// delete candidates that are not receiving traffic
-		for _, candidate := range instance.Spec.Candidates {
+		for _, candidate := range instance.GetCandidates() {
 			if ok := toKeep[candidate]; !ok {
-				err := client.Delete(context, getRuntimeObject(metav1.ObjectMeta{
-					Namespace: svcNamespace,
-					Name:      candidate,
-				}, kind))
+				err := client.Delete(context, getRuntimeObject(t.objectMeta(candidate), instance.Spec.Service))
 				if err != nil {
 					util.Logger(context).Error(err, "Error when deleting candidate", "name", candidate)
 				}
@@ -167,6 +236,51 @@ func Cleanup(context context.Context, instance *iter8v1alpha2.Experiment, client
 	}
 }
 
+// versionsToKeep returns the versions left running when the experiment terminates
+func versionsToKeep(instance *iter8v1alpha2.Experiment) map[string]bool {
+	assessment := instance.Status.Assessment
+	toKeep := make(map[string]bool)
+
+	switch instance.Spec.GetOnTermination() {
+	case iter8v1alpha2.OnTerminationToWinner:
+		// winner runs as baseline once promoted
+		if instance.Status.IsWinnerFound() && !instance.Status.Promoted() {
+			toKeep[*assessment.Winner.Name] = true
+			break
+		}
+		fallthrough
+	case iter8v1alpha2.OnTerminationToBaseline:
+		toKeep[instance.GetBaseline()] = true
+	case iter8v1alpha2.OnTerminationKeepLast:
+		if assessment != nil {
+			keepWeighted(toKeep, assessment)
+		}
+	}
+
+	// baseline of experiments in disjoint traffic segments also serves traffic out of their segments
+	if instance.Spec.GetDisjoint() {
+		toKeep[instance.GetBaseline()] = true
+	}
+
+	// segments keep the traffic split they end with
+	for i := range instance.Status.Segments {
+		keepWeighted(toKeep, &instance.Status.Segments[i].Assessment)
+	}
+	return toKeep
+}
+
+// keepWeighted adds versions receiving traffic in the assessment to toKeep
+func keepWeighted(toKeep map[string]bool, assessment *iter8v1alpha2.Assessment) {
+	if assessment.Baseline.Weight > 0 {
+		toKeep[assessment.Baseline.Name] = true
+	}
+	for _, candidate := range assessment.Candidates {
+		if candidate.Weight > 0 {
+			toKeep[candidate.Name] = true
+		}
+	}
+}
+
 // Instantiate runtime object content from k8s cluster
 func getObject(ctx context.Context, c client.Client, obj runtime.Object) error {
 	accessor, err := meta.Accessor(obj)
@@ -181,16 +295,72 @@ func getObject(ctx context.Context, c client.Client, obj runtime.Object) error {
 }
 
 // Form runtime object with meta info and kind specified
-func getRuntimeObject(om metav1.ObjectMeta, kind string) runtime.Object {
-	switch kind {
+func getRuntimeObject(om metav1.ObjectMeta, service iter8v1alpha2.Service) runtime.Object {
+	switch service.Kind {
 	case "Service":
 		return &corev1.Service{
 			ObjectMeta: om,
 		}
-	default:
-		// Deployment
+	case "Deployment", "":
 		return &appsv1.Deployment{
 			ObjectMeta: om,
 		}
+	case "StatefulSet":
+		return &appsv1.StatefulSet{
+			ObjectMeta: om,
+		}
+	default:
+		// generic workload
+		u := &unstructured.Unstructured{}
+		u.SetAPIVersion(service.APIVersion)
+		u.SetKind(service.Kind)
+		u.SetName(om.Name)
+		u.SetNamespace(om.Namespace)
+		return u
+	}
+}
+
+// PodTemplateLabels returns labels in pod template of a workload target
+// path is the dot-separated path to pod template labels, only used for targets of generic kind
+func PodTemplateLabels(obj runtime.Object, path string) (map[string]string, error) {
+	switch o := obj.(type) {
+	case *appsv1.Deployment:
+		return o.Spec.Template.Labels, nil
+	case *appsv1.StatefulSet:
+		return o.Spec.Template.Labels, nil
+	case *unstructured.Unstructured:
+		labels, found, err := unstructured.NestedStringMap(o.Object, strings.Split(path, ".")...)
+		if err != nil {
+			return nil, err
+		}
+		if !found || len(labels) == 0 {
+			return nil, fmt.Errorf("pod template labels not found at %s in %s %s", path, o.GetKind(), o.GetName())
+		}
+		return labels, nil
+	default:
+		return nil, fmt.Errorf("pod template not available in %T", obj)
+	}
+}
+
+// PodTemplate returns pod template of a deployment or statefulset, nil for other objects
+func PodTemplate(obj runtime.Object) *corev1.PodTemplateSpec {
+	switch o := obj.(type) {
+	case *appsv1.Deployment:
+		return &o.Spec.Template
+	case *appsv1.StatefulSet:
+		return &o.Spec.Template
+	default:
+		return nil
+	}
+}
+
+// Watched tells whether targets of the kind are watched by the controller
+// Targets of generic kind are not watched, and should be polled
+func Watched(kind string) bool {
+	switch kind {
+	case "Deployment", "StatefulSet", "Service", "":
+		return true
+	default:
+		return false
 	}
 }
@@ -0,0 +1,131 @@
+/*
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package targets
+
+import (
+	"fmt"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+
+	iter8v1alpha2 "github.com/iter8-tools/iter8/pkg/apis/iter8/v1alpha2"
+)
+
+func int32Ptr(i int32) *int32 {
+	return &i
+}
+
+func TestAvailable(t *testing.T) {
+	tests := []struct {
+		name      string
+		replicas  *int32
+		available int32
+		progress  corev1.ConditionStatus
+		want      bool
+		failed    bool
+	}{
+		{name: "all available", replicas: int32Ptr(3), available: 3, want: true},
+		{name: "partially available", replicas: int32Ptr(3), available: 1, want: false},
+		{name: "none available", replicas: int32Ptr(1), available: 0, want: false},
+		{name: "default replicas", available: 1, want: true},
+		{name: "scaled to zero", replicas: int32Ptr(0), available: 0, want: false},
+		{name: "progress deadline exceeded", replicas: int32Ptr(1), available: 0,
+			progress: corev1.ConditionFalse, want: false, failed: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := &appsv1.Deployment{}
+			d.Spec.Replicas = tt.replicas
+			d.Status.AvailableReplicas = tt.available
+			if tt.progress != "" {
+				d.Status.Conditions = []appsv1.DeploymentCondition{{
+					Type:   appsv1.DeploymentProgressing,
+					Status: tt.progress,
+					Reason: "ProgressDeadlineExceeded",
+				}}
+			}
+			available, failed, _ := Available(d)
+			if available != tt.want || failed != tt.failed {
+				t.Errorf("Available() = (%v, %v), want (%v, %v)", available, failed, tt.want, tt.failed)
+			}
+		})
+	}
+}
+
+func weighted(baseline, candidate int32) iter8v1alpha2.Assessment {
+	return iter8v1alpha2.Assessment{
+		Baseline: iter8v1alpha2.VersionAssessment{Name: "reviews-v1", Weight: baseline},
+		Candidates: []iter8v1alpha2.VersionAssessment{
+			{Name: "reviews-v2", Weight: candidate},
+		},
+	}
+}
+
+func TestVersionsToKeep(t *testing.T) {
+	tests := []struct {
+		name          string
+		onTermination iter8v1alpha2.OnTerminationType
+		assessment    iter8v1alpha2.Assessment
+		segments      []iter8v1alpha2.Assessment
+		want          []string
+	}{
+		{name: "to baseline", onTermination: iter8v1alpha2.OnTerminationToBaseline,
+			assessment: weighted(100, 0), want: []string{"reviews-v1"}},
+		{name: "keep last split", onTermination: iter8v1alpha2.OnTerminationKeepLast,
+			assessment: weighted(60, 40), want: []string{"reviews-v1", "reviews-v2"}},
+		{name: "keep last without baseline traffic", onTermination: iter8v1alpha2.OnTerminationKeepLast,
+			assessment: weighted(0, 100), want: []string{"reviews-v2"}},
+		{name: "segment winner", onTermination: iter8v1alpha2.OnTerminationToBaseline,
+			assessment: weighted(100, 0), segments: []iter8v1alpha2.Assessment{weighted(0, 100)},
+			want: []string{"reviews-v1", "reviews-v2"}},
+		{name: "segment on baseline", onTermination: iter8v1alpha2.OnTerminationKeepLast,
+			assessment: weighted(0, 100), segments: []iter8v1alpha2.Assessment{weighted(100, 0)},
+			want: []string{"reviews-v1", "reviews-v2"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			onTermination := tt.onTermination
+			assessment := tt.assessment
+			instance := &iter8v1alpha2.Experiment{
+				Spec: iter8v1alpha2.ExperimentSpec{
+					Service: iter8v1alpha2.Service{
+						Baseline:   "reviews-v1",
+						Candidates: []string{"reviews-v2"},
+					},
+					TrafficControl: &iter8v1alpha2.TrafficControl{OnTermination: &onTermination},
+				},
+				Status: iter8v1alpha2.ExperimentStatus{Assessment: &assessment},
+			}
+			for i, segment := range tt.segments {
+				instance.Status.Segments = append(instance.Status.Segments, iter8v1alpha2.SegmentStatus{
+					Name:       fmt.Sprintf("segment-%d", i),
+					Assessment: segment,
+				})
+			}
+			toKeep := versionsToKeep(instance)
+			if len(toKeep) != len(tt.want) {
+				t.Errorf("versionsToKeep() = %v, want %v", toKeep, tt.want)
+			}
+			for _, name := range tt.want {
+				if !toKeep[name] {
+					t.Errorf("versionsToKeep() = %v, want %v", toKeep, tt.want)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,63 @@
+/*
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package experiment
+
+// This file contains functions used for reacting to pod template changes of targets during the experiment.
+
+import (
+	"context"
+	"fmt"
+
+	iter8v1alpha2 "github.com/iter8-tools/iter8/pkg/apis/iter8/v1alpha2"
+	"github.com/iter8-tools/iter8/pkg/controller/experiment/targets"
+	"github.com/iter8-tools/iter8/pkg/controller/experiment/util"
+)
+
+// handleTemplateChange refreshes routing subsets with latest pod templates of targets,
+// and applies the policy specified for template changes
+// returns non-nil error if reconcile process should be terminated right after this function
+func (r *ReconcileExperiment) handleTemplateChange(context context.Context, instance *iter8v1alpha2.Experiment) error {
+	targetsHandler := targets.Init(instance, r.Client)
+	if err := targetsHandler.GetBaseline(context); err != nil {
+		r.markTargetsError(context, instance, "Missing Baseline")
+		return err
+	}
+	if err := targetsHandler.GetCandidates(context); err != nil {
+		r.markTargetsError(context, instance, "Missing Candidate")
+		return err
+	}
+	if err := r.router.RefreshSubsets(context, instance, targetsHandler.Baseline, targetsHandler.Candidates); err != nil {
+		r.markRoutingRulesError(context, instance, "Fail in refreshing subsets: %v", err)
+		return err
+	}
+
+	// changes made by iter8 after iterations, e.g. promotion of winner, are not assessed
+	if instance.Status.Ramp != nil || instance.Status.Bake != nil || instance.Status.Promotion != nil {
+		return nil
+	}
+
+	switch instance.Spec.GetOnTemplateChange() {
+	case iter8v1alpha2.TemplateChangePolicyRestart:
+		instance.Status.RestartAssessment()
+		r.markAssessmentUpdate(context, instance, "Pod template changed, assessment restarted")
+	case iter8v1alpha2.TemplateChangePolicyPause:
+		r.markActionPause(context, instance, "Pod template changed")
+		return fmt.Errorf("experiment paused on pod template change")
+	default:
+		util.Logger(context).Info("TemplateChangeIgnored")
+	}
+	return nil
+}
@@ -48,6 +48,10 @@ func FullExperimentName(instance *iter8v1alpha2.Experiment) string {
 
 // GetDefaultHost returns the default host for experiment
 func GetDefaultHost(instance *iter8v1alpha2.Experiment) string {
+	// logical host of external services is used as is
+	if instance.Spec.Service.Kind == "ServiceEntry" && instance.Spec.Service.Name != "" {
+		return instance.Spec.Service.Name
+	}
 	if instance.Spec.Service.Name != "" {
 		return ServiceToFullHostName(instance.Spec.Service.Name, instance.ServiceNamespace())
 	}
@@ -513,7 +513,7 @@ func getDestinationRule(serviceName, name string, subsets []string, objs []runti
 	ruleName := istio.GetRoutingRuleName(routerID)
 	drb := istio.NewDestinationRule(ruleName, util.ServiceToFullHostName(serviceName, Flags.Namespace), name, Flags.Namespace)
 	for i, subset := range subsets {
-		drb.WithSubset(objs[i].(*appsv1.Deployment), subset)
+		drb.WithSubset(objs[i].(*appsv1.Deployment).Spec.Template.Labels, subset)
 	}
 	return drb.Build()
 }
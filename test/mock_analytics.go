@@ -101,7 +101,7 @@ func (s *AnalyticsService) AddMock(name string, response analyticsv1alpha2.Respo
 }
 
 func GetRollToWinnerMockResponse(instance *iter8v1alpha2.Experiment, winIdx int) analyticsv1alpha2.Response {
-	candidates := instance.Spec.Candidates
+	candidates := instance.GetCandidates()
 	cas := make([]analyticsv1alpha2.CandidateAssessment, len(candidates))
 
 	for i := range cas {
@@ -151,7 +151,7 @@ func GetRollToWinnerMockResponse(instance *iter8v1alpha2.Experiment, winIdx int)
 }
 
 func GetAbortExperimentResponse(instance *iter8v1alpha2.Experiment) analyticsv1alpha2.Response {
-	candidates := instance.Spec.Candidates
+	candidates := instance.GetCandidates()
 	cas := make([]analyticsv1alpha2.CandidateAssessment, len(candidates))
 
 	for i := range cas {
@@ -190,7 +190,7 @@ func GetAbortExperimentResponse(instance *iter8v1alpha2.Experiment) analyticsv1a
 }
 
 func GetRollbackMockResponse(instance *iter8v1alpha2.Experiment) analyticsv1alpha2.Response {
-	candidates := instance.Spec.Candidates
+	candidates := instance.GetCandidates()
 	cas := make([]analyticsv1alpha2.CandidateAssessment, len(candidates))
 
 	for i := range cas {